@@ -0,0 +1,57 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/rode/es-index-manager/indexmanager"
+)
+
+// generate writes Go structs for the mappings. When run from go:generate, the package defaults to the package of the
+// file containing the directive.
+func generate(args []string) error {
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	mappingsPath := flags.String("mappings", "mappings", "the directory containing the mapping files")
+	packageName := flags.String("package", os.Getenv("GOPACKAGE"), "the package of the generated file; defaults to $GOPACKAGE")
+	output := flags.String("out", "-", "the file to write, or - for stdout")
+	_ = flags.Parse(args)
+
+	if *packageName == "" {
+		flags.Usage()
+		return errors.New("-package is required outside of go generate")
+	}
+
+	// the registry only reads the mapping files, so the prefix doesn't matter
+	registry := indexmanager.NewMappingsRegistry(&indexmanager.Config{MappingsPath: "."}, os.DirFS(*mappingsPath))
+	if err := registry.LoadMappings(); err != nil {
+		return fmt.Errorf("error loading mappings: %s", err)
+	}
+
+	source, err := indexmanager.GenerateStructs(registry, *packageName)
+	if err != nil {
+		return err
+	}
+
+	if *output == "-" {
+		_, err = os.Stdout.Write(source)
+		return err
+	}
+
+	return os.WriteFile(*output, source, 0644)
+}
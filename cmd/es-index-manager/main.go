@@ -0,0 +1,165 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command es-index-manager runs IndexManager operations against a cluster from the command line.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/rode/es-index-manager/indexmanager"
+	"go.uber.org/zap"
+)
+
+const usage = `Usage: es-index-manager <command> [flags]
+
+Commands:
+  export    write the documents for a document kind to a file as NDJSON
+  generate  write Go structs for the document kinds in a mappings directory
+  import    load documents from an NDJSON file into the index for a document kind
+
+Run es-index-manager <command> -h for the flags of each command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "export":
+		err = transfer(os.Args[1], os.Args[2:], exportDocuments)
+	case "generate":
+		err = generate(os.Args[2:])
+	case "import":
+		err = transfer(os.Args[1], os.Args[2:], importDocuments)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+type transferOptions struct {
+	documentKind string
+	inner        string
+	file         string
+}
+
+type transferFunc func(ctx context.Context, manager indexmanager.IndexManager, options *transferOptions) (*indexmanager.TransferReport, error)
+
+func transfer(command string, args []string, run transferFunc) error {
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	address := flags.String("url", "", "Elasticsearch address; defaults to $ELASTICSEARCH_URL, or http://localhost:9200")
+	username := flags.String("username", "", "Elasticsearch username")
+	password := flags.String("password", "", "Elasticsearch password")
+	prefix := flags.String("prefix", "", "the application's index prefix (required)")
+	mappingsPath := flags.String("mappings", "mappings", "the directory containing the mapping files")
+	batchSize := flags.Int("batch-size", 0, "the number of documents in each request; defaults to 1000")
+	options := &transferOptions{}
+	flags.StringVar(&options.documentKind, "kind", "", "the document kind (required)")
+	flags.StringVar(&options.inner, "inner", "", "the inner name of the index")
+	flags.StringVar(&options.file, "file", "-", "the NDJSON file, or - for stdin/stdout")
+	_ = flags.Parse(args)
+
+	if *prefix == "" || options.documentKind == "" {
+		flags.Usage()
+		return errors.New("-prefix and -kind are required")
+	}
+
+	esConfig := elasticsearch.Config{
+		Username: *username,
+		Password: *password,
+	}
+	if *address != "" {
+		esConfig.Addresses = strings.Split(*address, ",")
+	}
+
+	client, err := elasticsearch.NewClient(esConfig)
+	if err != nil {
+		return fmt.Errorf("error creating Elasticsearch client: %s", err)
+	}
+
+	config := &indexmanager.Config{
+		IndexPrefix:  *prefix,
+		MappingsPath: *mappingsPath,
+		Transfer: &indexmanager.TransferConfig{
+			BatchSize: *batchSize,
+			Progress: func(documentKind string, documents int) {
+				fmt.Fprintf(os.Stderr, "%s: %d documents\n", documentKind, documents)
+			},
+		},
+	}
+	manager := indexmanager.NewIndexManager(zap.NewNop(), client, config)
+	if err := manager.LoadMappings(); err != nil {
+		return fmt.Errorf("error loading mappings: %s", err)
+	}
+
+	report, err := run(context.Background(), manager, options)
+	if err != nil {
+		return err
+	}
+
+	for _, failure := range report.Failed {
+		fmt.Fprintf(os.Stderr, "failed to %s document %q: %s\n", command, failure.ID, failure.Reason)
+	}
+	fmt.Fprintf(os.Stderr, "%s complete: %d documents, %d failed\n", command, report.Documents, len(report.Failed))
+
+	if len(report.Failed) != 0 {
+		return fmt.Errorf("%d documents failed", len(report.Failed))
+	}
+
+	return nil
+}
+
+func exportDocuments(ctx context.Context, manager indexmanager.IndexManager, options *transferOptions) (*indexmanager.TransferReport, error) {
+	var w io.Writer = os.Stdout
+	if options.file != "-" {
+		file, err := os.Create(options.file)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	return manager.Export(ctx, options.documentKind, options.inner, w)
+}
+
+func importDocuments(ctx context.Context, manager indexmanager.IndexManager, options *transferOptions) (*indexmanager.TransferReport, error) {
+	var r io.Reader = os.Stdin
+	if options.file != "-" {
+		file, err := os.Open(options.file)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	return manager.Import(ctx, options.documentKind, options.inner, r)
+}
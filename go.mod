@@ -5,13 +5,13 @@ go 1.17
 require (
 	github.com/brianvoe/gofakeit/v6 v6.4.1
 	github.com/elastic/go-elasticsearch/v7 v7.12.0
+	github.com/fsnotify/fsnotify v1.4.9
 	github.com/onsi/ginkgo v1.16.2
 	github.com/onsi/gomega v1.12.0
 	go.uber.org/zap v1.16.0
 )
 
 require (
-	github.com/fsnotify/fsnotify v1.4.9 // indirect
 	github.com/nxadm/tail v1.4.8 // indirect
 	go.uber.org/atomic v1.6.0 // indirect
 	go.uber.org/multierr v1.5.0 // indirect
@@ -0,0 +1,163 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	. "github.com/rode/es-index-manager/indexmanager/internal"
+	"go.uber.org/zap"
+)
+
+// Adopter is the part of the Migrator that's exposed by the IndexManager.
+//
+//counterfeiter:generate -o ../mocks . Adopter
+type Adopter interface {
+	// Adopt brings an index that wasn't created by the IndexManager under its management. The index is reindexed into
+	// a new index named for the document kind and inner name, using the current mapping, and any aliases are moved over
+	// with their filters, routing, and write index flags. The original index is deleted; if keepIndexName is true, its
+	// name is added as an alias to the new index so that existing clients keep working.
+	Adopt(ctx context.Context, indexName, documentKind, inner string, keepIndexName bool) error
+}
+
+func (m *migrator) Adopt(ctx context.Context, indexName, documentKind, inner string, keepIndexName bool) error {
+	mapping, _, targetIndex := m.currentMapping(documentKind, inner)
+	alias := m.registry.AliasName(documentKind, inner)
+	log := m.logger.Named("Adopt").
+		With(zap.String("source", indexName)).
+		With(zap.String("target", targetIndex))
+
+	if mapping == nil {
+		return fmt.Errorf("unable to find a mapping for document kind %s", documentKind)
+	}
+
+	if m.reindexConfig(documentKind).MaxDocs != 0 {
+		return fmt.Errorf("MaxDocs can't be used to adopt an index, since the original index is removed after reindexing")
+	}
+
+	if indexName == targetIndex {
+		return fmt.Errorf("index %s already has the managed name", indexName)
+	}
+
+	existingAliases, err := m.getAliases(ctx, indexName)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Adopting index")
+	if err := m.blockWritesOnIndex(ctx, log, indexName); err != nil {
+		return err
+	}
+
+	migration := &Migration{
+		Alias:        alias,
+		SourceIndex:  indexName,
+		TargetIndex:  targetIndex,
+		DocumentKind: documentKind,
+		Mapping:      mapping,
+	}
+	if err := m.createTarget(ctx, migration, targetIndex, ""); err != nil {
+		return fmt.Errorf("error creating target index: %s", err)
+	}
+
+	if err := m.reindex(ctx, log, migration, nil); err != nil {
+		return err
+	}
+
+	aliases := sortedAliasNames(existingAliases)
+	if _, ok := existingAliases[alias]; !ok {
+		aliases = append(aliases, alias)
+	}
+	if keepIndexName {
+		aliases = append(aliases, indexName)
+	}
+
+	// removing the index in the same request means the aliases move atomically, and allows the old index name to be
+	// reused as an alias
+	actions := []EsActions{
+		{
+			RemoveIndex: &EsIndexRef{Index: indexName},
+		},
+	}
+	for _, a := range aliases {
+		// existing aliases keep their filter, routing, and write index flag
+		existing := existingAliases[a]
+		actions = append(actions, EsActions{
+			Add: &EsIndexAlias{
+				Index:         targetIndex,
+				Alias:         a,
+				Filter:        existing.Filter,
+				IndexRouting:  existing.IndexRouting,
+				SearchRouting: existing.SearchRouting,
+				IsWriteIndex:  existing.IsWriteIndex,
+				IsHidden:      existing.IsHidden,
+			},
+		})
+	}
+
+	aliasReqBody, _ := encodeRequest(&EsIndexAliasRequest{Actions: actions})
+	log.Info("Moving aliases to the adopted index and removing the original", zap.Strings("aliases", aliases))
+	res, err := m.client.Indices.UpdateAliases(
+		aliasReqBody,
+		m.client.Indices.UpdateAliases.WithContext(ctx),
+	)
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return fmt.Errorf("error moving aliases to the adopted index: %s", err)
+	}
+
+	log.Info("Adoption complete")
+
+	return nil
+}
+
+func (m *migrator) getAliases(ctx context.Context, indexName string) (map[string]EsAlias, error) {
+	res, err := m.client.Indices.GetAlias(
+		m.client.Indices.GetAlias.WithContext(ctx),
+		m.client.Indices.GetAlias.WithIndex(indexName),
+	)
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return nil, fmt.Errorf("error getting aliases for index %s: %s", indexName, err)
+	}
+
+	aliasResponse := map[string]EsIndex{}
+	if err := decodeResponse(res.Body, &aliasResponse); err != nil {
+		return nil, fmt.Errorf("error decoding alias response: %s", err)
+	}
+
+	// the response is keyed by concrete index, so an alias name resolves to the indices behind it instead
+	index, ok := aliasResponse[indexName]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a concrete index", indexName)
+	}
+
+	aliases := index.Aliases
+	if aliases == nil {
+		aliases = map[string]EsAlias{}
+	}
+
+	return aliases, nil
+}
+
+func sortedAliasNames(aliases map[string]EsAlias) []string {
+	var names []string
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,333 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/rode/es-index-manager/indexmanager"
+	. "github.com/rode/es-index-manager/indexmanager/internal"
+	"github.com/rode/es-index-manager/mocks"
+)
+
+var _ = Describe("Adopt", func() {
+	var (
+		ctx           = context.Background()
+		config        *Config
+		mockTransport *mockEsTransport
+		mockRegistry  *mocks.FakeMappingsRegistry
+		mockRepo      *mocks.FakeIndexRepository
+		migrator      Migrator
+
+		legacyIndex    string
+		legacyAlias    string
+		documentKind   string
+		innerName      string
+		expectedIndex  string
+		expectedAlias  string
+		keepIndexName  bool
+		actualError    error
+		expectedTaskId string
+	)
+
+	BeforeEach(func() {
+		config = &Config{
+			IndexPrefix: fake.Word(),
+			Migration: &MigrationConfig{
+				PollAttempts: 10,
+				PollInterval: time.Second,
+			},
+		}
+		mockTransport = &mockEsTransport{}
+		mockEsClient := &elasticsearch.Client{Transport: mockTransport, API: esapi.New(mockTransport)}
+		mockRegistry = &mocks.FakeMappingsRegistry{}
+		mockRepo = &mocks.FakeIndexRepository{}
+
+		legacyIndex = fake.Word() + "-000001"
+		legacyAlias = fake.Word()
+		documentKind = fake.Word()
+		innerName = fake.Word()
+		keepIndexName = false
+		expectedTaskId = fake.Word()
+		expectedIndex = createIndexOrAliasName(config.IndexPrefix, "v1", innerName, documentKind)
+		expectedAlias = createIndexOrAliasName(config.IndexPrefix, innerName, documentKind)
+
+		mockRegistry.IndexNameReturns(expectedIndex)
+		mockRegistry.AliasNameReturns(expectedAlias)
+		mockRegistry.MappingReturns(createRandomMapping())
+
+		mockTransport.preparedHttpResponses = []*http.Response{
+			// get aliases
+			{
+				StatusCode: http.StatusOK,
+				Body: createESBody(map[string]interface{}{
+					legacyIndex: map[string]interface{}{
+						"aliases": map[string]interface{}{
+							legacyAlias: map[string]interface{}{
+								"filter":         map[string]interface{}{"term": map[string]interface{}{"user": "foo"}},
+								"index_routing":  "1",
+								"search_routing": "1,2",
+								"is_write_index": true,
+							},
+						},
+					},
+				}),
+			},
+			// get index settings
+			{
+				StatusCode: http.StatusOK,
+				Body: createESBody(map[string]interface{}{
+					legacyIndex: EsSettingsResponse{
+						Settings: &EsSettingsIndex{
+							Index: &EsSettingsBlocks{},
+						},
+					},
+				}),
+			},
+			// add write block
+			{
+				StatusCode: http.StatusOK,
+				Body: createESBody(&EsBlockResponse{
+					Acknowledged:       true,
+					ShardsAcknowledged: true,
+				}),
+			},
+			// reindex
+			{
+				StatusCode: http.StatusOK,
+				Body: createESBody(&EsTaskCreationResponse{
+					Task: expectedTaskId,
+				}),
+			},
+			// poll task
+			{
+				StatusCode: http.StatusOK,
+				Body: createESBody(&EsTask{
+					Completed: true,
+				}),
+			},
+			// delete task document
+			{
+				StatusCode: http.StatusOK,
+			},
+			// update aliases
+			{
+				StatusCode: http.StatusOK,
+			},
+		}
+
+		migrator = NewMigrator(logger, mockEsClient, mockRegistry, mockRepo, func(time.Duration) {}, config)
+	})
+
+	JustBeforeEach(func() {
+		actualError = migrator.Adopt(ctx, legacyIndex, documentKind, innerName, keepIndexName)
+	})
+
+	When("the index is adopted", func() {
+		It("should not return an error", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+			Expect(mockTransport.receivedHttpRequests).To(HaveLen(7))
+		})
+
+		It("should use the registry to name the new index and alias", func() {
+			actualDocumentKind, actualInner := mockRegistry.IndexNameArgsForCall(0)
+			Expect(actualDocumentKind).To(Equal(documentKind))
+			Expect(actualInner).To(Equal(innerName))
+
+			actualDocumentKind, actualInner = mockRegistry.AliasNameArgsForCall(0)
+			Expect(actualDocumentKind).To(Equal(documentKind))
+			Expect(actualInner).To(Equal(innerName))
+		})
+
+		It("should look up the existing aliases", func() {
+			Expect(mockTransport.receivedHttpRequests[0].Method).To(Equal(http.MethodGet))
+			Expect(mockTransport.receivedHttpRequests[0].URL.Path).To(Equal(fmt.Sprintf("/%s/_alias", legacyIndex)))
+		})
+
+		It("should place a write block on the original index", func() {
+			Expect(mockTransport.receivedHttpRequests[2].Method).To(Equal(http.MethodPut))
+			Expect(mockTransport.receivedHttpRequests[2].URL.Path).To(Equal(fmt.Sprintf("/%s/_block/write", legacyIndex)))
+		})
+
+		It("should create the new index with the registry mapping", func() {
+			Expect(mockRepo.CreateIndexCallCount()).To(Equal(1))
+			_, actualIndex, actualAlias, actualDocumentKind := mockRepo.CreateIndexArgsForCall(0)
+
+			Expect(actualIndex).To(Equal(expectedIndex))
+			Expect(actualAlias).To(BeEmpty())
+			Expect(actualDocumentKind).To(Equal(documentKind))
+		})
+
+		It("should reindex into the new index", func() {
+			actualBody := &EsReindex{}
+			readRequestBody(mockTransport.receivedHttpRequests[3], actualBody)
+
+			Expect(actualBody.Source.Index).To(Equal(legacyIndex))
+			Expect(actualBody.Destination.Index).To(Equal(expectedIndex))
+		})
+
+		It("should move the aliases and remove the original index in one request", func() {
+			isWriteIndex := true
+			expectedBody := &EsIndexAliasRequest{
+				Actions: []EsActions{
+					{
+						RemoveIndex: &EsIndexRef{Index: legacyIndex},
+					},
+					{
+						Add: &EsIndexAlias{
+							Index:         expectedIndex,
+							Alias:         legacyAlias,
+							Filter:        map[string]interface{}{"term": map[string]interface{}{"user": "foo"}},
+							IndexRouting:  "1",
+							SearchRouting: "1,2",
+							IsWriteIndex:  &isWriteIndex,
+						},
+					},
+					{
+						Add: &EsIndexAlias{Index: expectedIndex, Alias: expectedAlias},
+					},
+				},
+			}
+			actualBody := &EsIndexAliasRequest{}
+			readRequestBody(mockTransport.receivedHttpRequests[6], actualBody)
+
+			Expect(mockTransport.receivedHttpRequests[6].URL.Path).To(Equal("/_aliases"))
+			Expect(actualBody).To(Equal(expectedBody))
+		})
+	})
+
+	When("the original index name should be kept", func() {
+		BeforeEach(func() {
+			keepIndexName = true
+		})
+
+		It("should add the original name as an alias", func() {
+			actualBody := &EsIndexAliasRequest{}
+			readRequestBody(mockTransport.receivedHttpRequests[6], actualBody)
+
+			Expect(actualBody.Actions).To(HaveLen(4))
+			Expect(actualBody.Actions[3].Add).To(Equal(&EsIndexAlias{Index: expectedIndex, Alias: legacyIndex}))
+		})
+	})
+
+	When("the document kind isn't in the registry", func() {
+		BeforeEach(func() {
+			mockRegistry.MappingReturns(nil)
+		})
+
+		It("should return an error without making any requests", func() {
+			Expect(actualError).To(HaveOccurred())
+			Expect(actualError.Error()).To(ContainSubstring("unable to find a mapping"))
+			Expect(mockTransport.receivedHttpRequests).To(BeEmpty())
+		})
+	})
+
+	When("MaxDocs is set", func() {
+		BeforeEach(func() {
+			config.Migration.Reindex = &ReindexConfig{MaxDocs: 100}
+		})
+
+		It("should return an error without making any requests", func() {
+			Expect(actualError).To(HaveOccurred())
+			Expect(actualError.Error()).To(ContainSubstring("MaxDocs"))
+			Expect(mockTransport.receivedHttpRequests).To(BeEmpty())
+		})
+	})
+
+	When("the index already has the managed name", func() {
+		BeforeEach(func() {
+			legacyIndex = expectedIndex
+		})
+
+		It("should return an error", func() {
+			Expect(actualError).To(HaveOccurred())
+			Expect(mockTransport.receivedHttpRequests).To(BeEmpty())
+		})
+	})
+
+	When("the original index doesn't exist", func() {
+		BeforeEach(func() {
+			mockTransport.preparedHttpResponses[0] = &http.Response{StatusCode: http.StatusNotFound}
+		})
+
+		It("should return an error", func() {
+			Expect(actualError).To(HaveOccurred())
+			Expect(actualError.Error()).To(ContainSubstring("error getting aliases"))
+			Expect(mockTransport.receivedHttpRequests).To(HaveLen(1))
+		})
+	})
+
+	When("an alias name is given instead of an index", func() {
+		BeforeEach(func() {
+			mockTransport.preparedHttpResponses[0].Body = createESBody(map[string]interface{}{
+				fake.Word(): map[string]interface{}{
+					"aliases": map[string]interface{}{
+						legacyIndex: map[string]interface{}{},
+					},
+				},
+			})
+		})
+
+		It("should return an error without blocking writes", func() {
+			Expect(actualError).To(HaveOccurred())
+			Expect(actualError.Error()).To(ContainSubstring("is not a concrete index"))
+			Expect(mockTransport.receivedHttpRequests).To(HaveLen(1))
+		})
+	})
+
+	When("the settings response doesn't include index settings", func() {
+		BeforeEach(func() {
+			mockTransport.preparedHttpResponses[1].Body = createESBody(map[string]interface{}{
+				legacyIndex: map[string]interface{}{},
+			})
+		})
+
+		It("should place a write block on the original index", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+			Expect(mockTransport.receivedHttpRequests[2].URL.Path).To(Equal(fmt.Sprintf("/%s/_block/write", legacyIndex)))
+		})
+	})
+
+	When("creating the new index fails", func() {
+		BeforeEach(func() {
+			mockRepo.CreateIndexReturns(errors.New(fake.Word()))
+		})
+
+		It("should return an error", func() {
+			Expect(actualError).To(HaveOccurred())
+			Expect(actualError.Error()).To(ContainSubstring("error creating target index"))
+			Expect(mockTransport.receivedHttpRequests).To(HaveLen(3))
+		})
+	})
+
+	When("moving the aliases fails", func() {
+		BeforeEach(func() {
+			mockTransport.preparedHttpResponses[6].StatusCode = http.StatusBadRequest
+		})
+
+		It("should return an error", func() {
+			Expect(actualError).To(HaveOccurred())
+			Expect(actualError.Error()).To(ContainSubstring("error moving aliases"))
+		})
+	})
+})
@@ -0,0 +1,311 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	. "github.com/rode/es-index-manager/indexmanager/internal"
+	"go.uber.org/zap"
+)
+
+const taskDocumentsPageSize = 1000
+
+var taskDescriptionIndexPattern = regexp.MustCompile(`\[([^\]]+)\]`)
+
+//counterfeiter:generate -o ../mocks . IndexCleaner
+type IndexCleaner interface {
+	// Cleanup finds indices and reindex task documents that the application no longer needs. Nothing is deleted
+	// unless CleanupOptions.Delete is set. Cleanup should not run at the same time as migrations.
+	Cleanup(ctx context.Context, options *CleanupOptions) (*CleanupReport, error)
+}
+
+type CleanupOptions struct {
+	// Delete confirms that the garbage should be deleted. Otherwise, it's only reported.
+	Delete bool
+	// Allowlist contains index names that are never deleted.
+	Allowlist []string
+}
+
+type CleanupReport struct {
+	// RetiredIndices belong to the application, but their document kind is no longer in the registry.
+	RetiredIndices []string
+	// OrphanedIndices are older versions of an index that the alias no longer points to, e.g. because a migration
+	// failed before the source or target was deleted.
+	OrphanedIndices []string
+	// TaskDocuments are the ids of completed reindex tasks in the .tasks index that involved the application's indices.
+	TaskDocuments []string
+	// Protected lists indices that would have been reported, but are in the allowlist.
+	Protected []string
+	// Deleted is true if the indices and task documents were removed.
+	Deleted bool
+}
+
+type indexCleaner struct {
+	client   *elasticsearch.Client
+	config   *Config
+	logger   *zap.Logger
+	registry MappingsRegistry
+	repo     IndexRepository
+}
+
+func NewIndexCleaner(
+	logger *zap.Logger,
+	client *elasticsearch.Client,
+	registry MappingsRegistry,
+	repo IndexRepository,
+	config *Config,
+) IndexCleaner {
+	return &indexCleaner{
+		client,
+		config,
+		logger,
+		registry,
+		repo,
+	}
+}
+
+func (c *indexCleaner) Cleanup(ctx context.Context, options *CleanupOptions) (*CleanupReport, error) {
+	log := c.logger.Named("Cleanup")
+	if options == nil {
+		options = &CleanupOptions{}
+	}
+
+	applicationIndices, err := getApplicationIndices(ctx, c.client, c.config.IndexPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("error finding indices: %s", err)
+	}
+
+	report := &CleanupReport{}
+	var indices []*managedIndex
+	for indexName, indexValue := range applicationIndices {
+		indexParts := c.registry.ParseIndexName(indexName)
+		if indexParts == nil {
+			report.RetiredIndices = append(report.RetiredIndices, indexName)
+			continue
+		}
+
+		indices = append(indices, newManagedIndex(indexName, indexParts, indexValue))
+	}
+
+	for _, group := range groupIndices(indices) {
+		report.OrphanedIndices = append(report.OrphanedIndices, c.orphanedIndices(group)...)
+	}
+
+	report.RetiredIndices = c.protect(report, report.RetiredIndices, options.Allowlist)
+	report.OrphanedIndices = c.protect(report, report.OrphanedIndices, options.Allowlist)
+	sort.Strings(report.RetiredIndices)
+	sort.Strings(report.OrphanedIndices)
+	sort.Strings(report.Protected)
+
+	report.TaskDocuments, err = c.findTaskDocuments(ctx, applicationIndices)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("Found garbage",
+		zap.Strings("retiredIndices", report.RetiredIndices),
+		zap.Strings("orphanedIndices", report.OrphanedIndices),
+		zap.Int("taskDocuments", len(report.TaskDocuments)),
+		zap.Strings("protected", report.Protected))
+
+	if !options.Delete {
+		return report, nil
+	}
+
+	for _, indexName := range append(report.RetiredIndices, report.OrphanedIndices...) {
+		if err := c.repo.DeleteIndex(ctx, indexName); err != nil {
+			return report, err
+		}
+	}
+
+	for _, taskId := range report.TaskDocuments {
+		res, err := c.client.Delete(ElasticsearchTaskIndex, taskId, c.client.Delete.WithContext(ctx))
+		if err := getErrorFromESResponse(res, err); err != nil {
+			return report, fmt.Errorf("error deleting task document %s: %s", taskId, err)
+		}
+	}
+
+	report.Deleted = true
+	log.Info("Garbage deleted")
+
+	return report, nil
+}
+
+// orphanedIndices returns the older indices in the group that the alias doesn't point to. If the alias doesn't point to
+// any index in the group, nothing is returned, since there's no way to tell which index is in use. Newer indices are
+// left alone, since they may be the target of a migration that hasn't moved the alias yet, as are all of the indices
+// while the aliased index has a write block.
+func (c *indexCleaner) orphanedIndices(group []*managedIndex) []string {
+	alias := c.registry.AliasName(group[0].parts.DocumentKind, group[0].parts.Inner)
+
+	var aliased []*managedIndex
+	for _, index := range group {
+		if index.hasAlias(alias) {
+			aliased = append(aliased, index)
+		}
+	}
+
+	if len(aliased) == 0 {
+		return nil
+	}
+
+	for _, index := range aliased {
+		if index.writeBlocked {
+			return nil
+		}
+	}
+
+	var orphaned []string
+	for _, index := range group {
+		if !index.hasAlias(alias) && c.isOlderThanAll(index, aliased) {
+			orphaned = append(orphaned, index.name)
+		}
+	}
+
+	return orphaned
+}
+
+// isOlderThanAll checks if the index has an older version than every one of the other indices. Versions that can't
+// be compared are treated as newer, so the index is kept.
+func (c *indexCleaner) isOlderThanAll(index *managedIndex, others []*managedIndex) bool {
+	for _, other := range others {
+		comparison, err := CompareVersions(index.parts.Version, other.parts.Version)
+		if err != nil || comparison >= 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// protect removes any index in the allowlist, recording it in the report.
+func (c *indexCleaner) protect(report *CleanupReport, indices, allowlist []string) []string {
+	var unprotected []string
+	for _, indexName := range indices {
+		if containsString(allowlist, indexName) {
+			report.Protected = append(report.Protected, indexName)
+			continue
+		}
+
+		unprotected = append(unprotected, indexName)
+	}
+
+	return unprotected
+}
+
+func (c *indexCleaner) findTaskDocuments(ctx context.Context, applicationIndices map[string]EsIndex) ([]string, error) {
+	var taskIds []string
+	var searchAfter []interface{}
+	for {
+		hits, err := c.searchTaskDocuments(ctx, searchAfter)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, hit := range hits {
+			task := &EsTaskDocument{}
+			if err := json.Unmarshal(hit.Source, task); err != nil || task.Task == nil {
+				continue
+			}
+
+			// e.g. "reindex from [myapp-v1-foo] to [myapp-v2-foo][_doc]"
+			for _, match := range taskDescriptionIndexPattern.FindAllStringSubmatch(task.Task.Description, -1) {
+				if c.isApplicationIndex(match[1], applicationIndices) {
+					taskIds = append(taskIds, hit.ID)
+					break
+				}
+			}
+		}
+
+		if len(hits) < taskDocumentsPageSize {
+			break
+		}
+		searchAfter = hits[len(hits)-1].Sort
+	}
+	sort.Strings(taskIds)
+
+	return taskIds, nil
+}
+
+// searchTaskDocuments returns a page of completed reindex task documents, starting after the sort values of the last
+// document in the previous page.
+func (c *indexCleaner) searchTaskDocuments(ctx context.Context, searchAfter []interface{}) ([]*EsSearchHit, error) {
+	query := map[string]interface{}{
+		"size": taskDocumentsPageSize,
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []interface{}{
+					map[string]interface{}{"term": map[string]interface{}{"completed": true}},
+					map[string]interface{}{"term": map[string]interface{}{"task.action": ElasticsearchReindexAction}},
+				},
+			},
+		},
+		// the .tasks index has a single shard, so index order is stable between pages
+		"sort": []interface{}{
+			map[string]interface{}{"_doc": "asc"},
+		},
+	}
+	if searchAfter != nil {
+		query["search_after"] = searchAfter
+	}
+
+	body, _ := encodeRequest(query)
+	res, err := c.client.Search(
+		c.client.Search.WithContext(ctx),
+		c.client.Search.WithIndex(ElasticsearchTaskIndex),
+		c.client.Search.WithBody(body),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error searching for task documents: %s", err)
+	}
+
+	// the .tasks index is only created once a task stores its result
+	if res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if res.IsError() {
+		return nil, fmt.Errorf("error searching for task documents, status: %d", res.StatusCode)
+	}
+
+	searchResponse := &EsSearchResponse{}
+	if err := decodeResponse(res.Body, searchResponse); err != nil {
+		return nil, fmt.Errorf("error decoding task search response: %s", err)
+	}
+
+	if searchResponse.Hits == nil {
+		return nil, nil
+	}
+
+	return searchResponse.Hits.Hits, nil
+}
+
+// isApplicationIndex checks if an index named in a task belongs to the application. The index may have been deleted
+// since the task ran, e.g. the source of a migration.
+func (c *indexCleaner) isApplicationIndex(indexName string, applicationIndices map[string]EsIndex) bool {
+	if _, ok := applicationIndices[indexName]; ok {
+		return true
+	}
+
+	return strings.HasPrefix(indexName, c.config.IndexPrefix) && c.registry.ParseIndexName(indexName) != nil
+}
@@ -0,0 +1,352 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/rode/es-index-manager/indexmanager"
+	"github.com/rode/es-index-manager/mocks"
+)
+
+var _ = Describe("IndexCleaner", func() {
+	var (
+		ctx           = context.Background()
+		config        *Config
+		mockTransport *mockEsTransport
+		mockRegistry  *mocks.FakeMappingsRegistry
+		mockRepo      *mocks.FakeIndexRepository
+		cleaner       IndexCleaner
+
+		indexPrefix   string
+		documentKind  string
+		alias         string
+		retiredIndex  string
+		orphanedIndex string
+		currentIndex  string
+		taskId        string
+
+		options      *CleanupOptions
+		actualReport *CleanupReport
+		actualError  error
+	)
+
+	BeforeEach(func() {
+		indexPrefix = fake.Word()
+		documentKind = fake.Word()
+		config = &Config{
+			IndexPrefix: indexPrefix,
+		}
+		mockTransport = &mockEsTransport{}
+		mockEsClient := &elasticsearch.Client{Transport: mockTransport, API: esapi.New(mockTransport)}
+		mockRegistry = &mocks.FakeMappingsRegistry{}
+		mockRepo = &mocks.FakeIndexRepository{}
+
+		alias = createIndexOrAliasName(indexPrefix, documentKind)
+		retiredIndex = createIndexOrAliasName(indexPrefix, "v1", "retired"+documentKind)
+		orphanedIndex = createIndexOrAliasName(indexPrefix, "v1", documentKind)
+		currentIndex = createIndexOrAliasName(indexPrefix, "v2", documentKind)
+		taskId = fake.Word()
+		options = &CleanupOptions{}
+
+		mockRegistry.AliasNameReturns(alias)
+		mockRegistry.ParseIndexNameStub = func(indexName string) *IndexName {
+			parts := strings.Split(indexName, "-")
+			if parts[len(parts)-1] != documentKind {
+				return nil
+			}
+
+			return &IndexName{
+				DocumentKind: documentKind,
+				Version:      parts[1],
+			}
+		}
+
+		mockTransport.preparedHttpResponses = []*http.Response{
+			// get all indices
+			{
+				StatusCode: http.StatusOK,
+				Body: createESBody(map[string]interface{}{
+					retiredIndex:  managedIndexResponse(indexPrefix, nil, false),
+					orphanedIndex: managedIndexResponse(indexPrefix, nil, true),
+					currentIndex: managedIndexResponse(indexPrefix, map[string]interface{}{
+						alias: map[string]interface{}{},
+					}, false),
+					fake.Word(): map[string]interface{}{
+						"mappings": map[string]interface{}{},
+					},
+				}),
+			},
+			// search task documents
+			{
+				StatusCode: http.StatusOK,
+				Body: createESBody(map[string]interface{}{
+					"hits": map[string]interface{}{
+						"hits": []interface{}{
+							map[string]interface{}{
+								"_id": taskId,
+								"_source": map[string]interface{}{
+									"completed": true,
+									"task": map[string]interface{}{
+										"action":      "indices:data/write/reindex",
+										"description": fmt.Sprintf("reindex from [%s] to [%s][_doc]", orphanedIndex, currentIndex),
+									},
+								},
+							},
+							map[string]interface{}{
+								"_id": fake.Word(),
+								"_source": map[string]interface{}{
+									"completed": true,
+									"task": map[string]interface{}{
+										"action":      "indices:data/write/reindex",
+										"description": "reindex from [other-v1-foo] to [other-v2-foo][_doc]",
+									},
+								},
+							},
+						},
+					},
+				}),
+			},
+			// delete task document
+			{
+				StatusCode: http.StatusOK,
+			},
+		}
+
+		cleaner = NewIndexCleaner(logger, mockEsClient, mockRegistry, mockRepo, config)
+	})
+
+	JustBeforeEach(func() {
+		actualReport, actualError = cleaner.Cleanup(ctx, options)
+	})
+
+	When("only reporting garbage", func() {
+		It("should not return an error", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+		})
+
+		It("should report indices whose document kind isn't registered", func() {
+			Expect(actualReport.RetiredIndices).To(ConsistOf(retiredIndex))
+		})
+
+		It("should report older indices the alias doesn't point to", func() {
+			Expect(actualReport.OrphanedIndices).To(ConsistOf(orphanedIndex))
+		})
+
+		It("should report the application's completed reindex tasks", func() {
+			Expect(actualReport.TaskDocuments).To(ConsistOf(taskId))
+
+			Expect(mockTransport.receivedHttpRequests[1].URL.Path).To(Equal("/.tasks/_search"))
+		})
+
+		It("should not delete anything", func() {
+			Expect(actualReport.Deleted).To(BeFalse())
+			Expect(mockRepo.DeleteIndexCallCount()).To(Equal(0))
+			Expect(mockTransport.receivedHttpRequests).To(HaveLen(2))
+		})
+	})
+
+	When("deletion is confirmed", func() {
+		BeforeEach(func() {
+			options.Delete = true
+		})
+
+		It("should delete the indices", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+			Expect(actualReport.Deleted).To(BeTrue())
+			Expect(mockRepo.DeleteIndexCallCount()).To(Equal(2))
+
+			_, firstIndex := mockRepo.DeleteIndexArgsForCall(0)
+			_, secondIndex := mockRepo.DeleteIndexArgsForCall(1)
+			Expect([]string{firstIndex, secondIndex}).To(ConsistOf(retiredIndex, orphanedIndex))
+		})
+
+		It("should delete the task documents", func() {
+			Expect(mockTransport.receivedHttpRequests[2].Method).To(Equal(http.MethodDelete))
+			Expect(mockTransport.receivedHttpRequests[2].URL.Path).To(Equal(fmt.Sprintf("/.tasks/_doc/%s", taskId)))
+		})
+
+		When("an index is in the allowlist", func() {
+			BeforeEach(func() {
+				options.Allowlist = []string{retiredIndex}
+			})
+
+			It("should not delete it", func() {
+				Expect(actualReport.RetiredIndices).To(BeEmpty())
+				Expect(actualReport.Protected).To(ConsistOf(retiredIndex))
+
+				Expect(mockRepo.DeleteIndexCallCount()).To(Equal(1))
+				_, actualIndex := mockRepo.DeleteIndexArgsForCall(0)
+				Expect(actualIndex).To(Equal(orphanedIndex))
+			})
+		})
+
+		When("deleting an index fails", func() {
+			BeforeEach(func() {
+				mockRepo.DeleteIndexReturns(errors.New(fake.Word()))
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(actualReport.Deleted).To(BeFalse())
+			})
+		})
+
+		When("deleting a task document fails", func() {
+			BeforeEach(func() {
+				mockTransport.preparedHttpResponses[2].StatusCode = http.StatusInternalServerError
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(actualError.Error()).To(ContainSubstring("error deleting task document"))
+			})
+		})
+	})
+
+	When("the alias doesn't point to any version of an index", func() {
+		BeforeEach(func() {
+			mockTransport.preparedHttpResponses[0].Body = createESBody(map[string]interface{}{
+				orphanedIndex: managedIndexResponse(indexPrefix, nil, false),
+				currentIndex:  managedIndexResponse(indexPrefix, nil, false),
+			})
+		})
+
+		It("should not report either index", func() {
+			Expect(actualReport.OrphanedIndices).To(BeEmpty())
+		})
+	})
+
+	When("a newer index doesn't have the alias yet", func() {
+		var targetIndex string
+
+		BeforeEach(func() {
+			targetIndex = createIndexOrAliasName(indexPrefix, "v3", documentKind)
+			mockTransport.preparedHttpResponses[0].Body = createESBody(map[string]interface{}{
+				orphanedIndex: managedIndexResponse(indexPrefix, nil, true),
+				currentIndex: managedIndexResponse(indexPrefix, map[string]interface{}{
+					alias: map[string]interface{}{},
+				}, false),
+				targetIndex: managedIndexResponse(indexPrefix, nil, false),
+			})
+		})
+
+		It("should only report the older index", func() {
+			Expect(actualReport.OrphanedIndices).To(ConsistOf(orphanedIndex))
+		})
+	})
+
+	When("the index the alias points to has a write block", func() {
+		BeforeEach(func() {
+			mockTransport.preparedHttpResponses[0].Body = createESBody(map[string]interface{}{
+				orphanedIndex: managedIndexResponse(indexPrefix, nil, true),
+				currentIndex: managedIndexResponse(indexPrefix, map[string]interface{}{
+					alias: map[string]interface{}{},
+				}, true),
+			})
+		})
+
+		It("should not report any index, since a migration may be running", func() {
+			Expect(actualReport.OrphanedIndices).To(BeEmpty())
+		})
+	})
+
+	When("there is more than one page of task documents", func() {
+		var lastTaskId string
+
+		BeforeEach(func() {
+			var hits []interface{}
+			for i := 0; i < 1000; i++ {
+				hits = append(hits, map[string]interface{}{
+					"_id":  fmt.Sprintf("other%d", i),
+					"sort": []interface{}{i},
+					"_source": map[string]interface{}{
+						"completed": true,
+						"task": map[string]interface{}{
+							"action":      "indices:data/write/reindex",
+							"description": "reindex from [other-v1-foo] to [other-v2-foo][_doc]",
+						},
+					},
+				})
+			}
+			lastTaskId = fake.Word()
+			hits[999].(map[string]interface{})["_id"] = lastTaskId
+			hits[999].(map[string]interface{})["_source"].(map[string]interface{})["task"].(map[string]interface{})["description"] =
+				fmt.Sprintf("reindex from [%s] to [%s][_doc]", orphanedIndex, currentIndex)
+
+			firstPage := &http.Response{
+				StatusCode: http.StatusOK,
+				Body: createESBody(map[string]interface{}{
+					"hits": map[string]interface{}{"hits": hits},
+				}),
+			}
+			mockTransport.preparedHttpResponses = append(
+				[]*http.Response{mockTransport.preparedHttpResponses[0], firstPage},
+				mockTransport.preparedHttpResponses[1:]...,
+			)
+		})
+
+		It("should search for the next page after the last document", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+			Expect(actualReport.TaskDocuments).To(ConsistOf(lastTaskId, taskId))
+
+			actualBody := map[string]interface{}{}
+			readRequestBody(mockTransport.receivedHttpRequests[2], &actualBody)
+			Expect(mockTransport.receivedHttpRequests[2].URL.Path).To(Equal("/.tasks/_search"))
+			Expect(actualBody["search_after"]).To(Equal([]interface{}{float64(999)}))
+		})
+	})
+
+	When("the .tasks index doesn't exist", func() {
+		BeforeEach(func() {
+			mockTransport.preparedHttpResponses[1] = &http.Response{StatusCode: http.StatusNotFound}
+		})
+
+		It("should not report any task documents", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+			Expect(actualReport.TaskDocuments).To(BeEmpty())
+		})
+	})
+
+	When("searching for task documents fails", func() {
+		BeforeEach(func() {
+			mockTransport.preparedHttpResponses[1] = &http.Response{StatusCode: http.StatusInternalServerError}
+		})
+
+		It("should return an error", func() {
+			Expect(actualError).To(HaveOccurred())
+			Expect(actualError.Error()).To(ContainSubstring("error searching for task documents"))
+		})
+	})
+
+	When("fetching the indices fails", func() {
+		BeforeEach(func() {
+			mockTransport.preparedHttpResponses[0] = &http.Response{StatusCode: http.StatusInternalServerError}
+		})
+
+		It("should return an error", func() {
+			Expect(actualError).To(HaveOccurred())
+			Expect(actualError.Error()).To(ContainSubstring("error finding indices"))
+		})
+	})
+})
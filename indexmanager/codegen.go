@@ -0,0 +1,255 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const geoPointTypeName = "GeoPoint"
+
+// goTypes maps Elasticsearch field types to the Go types used in generated structs. Objects, nested fields, and geo
+// points are handled separately; any other type becomes interface{}. Fields whose zero value is a meaningful value,
+// like false or 0, are generated as pointers (see optionalType).
+var goTypes = map[string]string{
+	"binary":           "string",
+	"boolean":          "bool",
+	"byte":             "int8",
+	"constant_keyword": "string",
+	"date":             "time.Time",
+	"date_nanos":       "time.Time",
+	"double":           "float64",
+	"flattened":        "map[string]interface{}",
+	"float":            "float32",
+	"half_float":       "float32",
+	"integer":          "int32",
+	"ip":               "string",
+	"keyword":          "string",
+	"long":             "int64",
+	"match_only_text":  "string",
+	"scaled_float":     "float64",
+	"short":            "int16",
+	"text":             "string",
+	"unsigned_long":    "uint64",
+	"wildcard":         "string",
+}
+
+// commonInitialisms are kept upper case in generated identifiers, following the Go naming conventions.
+var commonInitialisms = map[string]bool{
+	"API":  true,
+	"HTTP": true,
+	"ID":   true,
+	"IP":   true,
+	"JSON": true,
+	"URI":  true,
+	"URL":  true,
+	"UUID": true,
+}
+
+// GenerateStructs returns the source of a Go file in the given package with a struct for each document kind in the
+// registry, with a field and JSON tag for each property in its mapping. Object fields become their own structs, and
+// nested fields become slices of them. Constants are generated for the name and version of each document kind, so the
+// generated file can be compared against the registry at runtime.
+func GenerateStructs(registry MappingsRegistry, packageName string) ([]byte, error) {
+	g := &structGenerator{names: map[string]string{}}
+	for _, documentKind := range registry.DocumentKinds() {
+		mapping := registry.Mapping(documentKind)
+		typeName := goIdentifier(documentKind)
+		if typeName == "" {
+			return nil, fmt.Errorf(`unable to generate a type name for document kind "%s"`, documentKind)
+		}
+
+		for _, constant := range []string{typeName + "DocumentKind", typeName + "MappingVersion"} {
+			if err := g.declare(constant, fmt.Sprintf("a constant for document kind %s", documentKind)); err != nil {
+				return nil, err
+			}
+		}
+		fmt.Fprintf(&g.constants, "%sDocumentKind = %q\n", typeName, documentKind)
+		fmt.Fprintf(&g.constants, "%sMappingVersion = %q\n", typeName, mapping.Version)
+
+		properties, _ := mapping.Mappings["properties"].(map[string]interface{})
+		if err := g.generateStruct(typeName, fmt.Sprintf("document kind %s", documentKind), properties); err != nil {
+			return nil, fmt.Errorf(`error generating struct for document kind "%s": %s`, documentKind, err)
+		}
+	}
+
+	if g.usesGeoPoint {
+		if err := g.declare(geoPointTypeName, "the geo_point type"); err != nil {
+			return nil, err
+		}
+	}
+
+	source := &bytes.Buffer{}
+	source.WriteString("// Code generated by es-index-manager. DO NOT EDIT.\n\n")
+	fmt.Fprintf(source, "package %s\n\n", packageName)
+	if g.usesTime {
+		source.WriteString("import \"time\"\n\n")
+	}
+
+	if g.constants.Len() > 0 {
+		fmt.Fprintf(source, "const (\n%s)\n\n", g.constants.String())
+	}
+
+	source.Write(g.types.Bytes())
+
+	if g.usesGeoPoint {
+		fmt.Fprintf(source, "// %s is a geo_point field in object format.\n", geoPointTypeName)
+		fmt.Fprintf(source, "type %s struct {\nLat float64 `json:\"lat\"`\nLon float64 `json:\"lon\"`\n}\n", geoPointTypeName)
+	}
+
+	formatted, err := format.Source(source.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("error formatting generated source: %s", err)
+	}
+
+	return formatted, nil
+}
+
+type structGenerator struct {
+	constants    bytes.Buffer
+	types        bytes.Buffer
+	usesTime     bool
+	usesGeoPoint bool
+	// names maps each top-level identifier in the generated file to what it was generated from
+	names map[string]string
+}
+
+// declare records a top-level identifier, returning an error if something else already uses it.
+func (g *structGenerator) declare(name, description string) error {
+	if existing, ok := g.names[name]; ok {
+		return fmt.Errorf("%s and %s both become %s", existing, description, name)
+	}
+	g.names[name] = description
+
+	return nil
+}
+
+// generateStruct writes a struct with the given name for the properties, and any structs for its object fields after it.
+func (g *structGenerator) generateStruct(typeName, description string, properties map[string]interface{}) error {
+	if err := g.declare(typeName, description); err != nil {
+		return err
+	}
+
+	var names []string
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	body := &bytes.Buffer{}
+	fieldNames := map[string]string{}
+	var objects []func() error
+	for _, name := range names {
+		field, ok := properties[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		fieldName := goIdentifier(name)
+		if fieldName == "" {
+			return fmt.Errorf(`unable to generate a field name for "%s"`, name)
+		}
+
+		if existing, ok := fieldNames[fieldName]; ok {
+			return fmt.Errorf(`fields "%s" and "%s" both become %s.%s`, existing, name, typeName, fieldName)
+		}
+		fieldNames[fieldName] = name
+
+		fieldType, _ := field["type"].(string)
+		nestedProperties, hasProperties := field["properties"].(map[string]interface{})
+		goType := ""
+		switch {
+		case (fieldType == "" || fieldType == "object" || fieldType == "nested") && hasProperties:
+			objectName := typeName + fieldName
+			objectDescription := fmt.Sprintf("the %s field of %s", name, typeName)
+			objects = append(objects, func() error {
+				return g.generateStruct(objectName, objectDescription, nestedProperties)
+			})
+
+			goType = "*" + objectName
+			if fieldType == "nested" {
+				goType = "[]*" + objectName
+			}
+		case fieldType == "" || fieldType == "object" || fieldType == "nested":
+			goType = "map[string]interface{}"
+		case fieldType == "geo_point":
+			goType = optionalType(geoPointTypeName)
+			g.usesGeoPoint = true
+		default:
+			goType, ok = goTypes[fieldType]
+			if !ok {
+				goType = "interface{}"
+			}
+			g.usesTime = g.usesTime || strings.HasPrefix(goType, "time.")
+			goType = optionalType(goType)
+		}
+
+		fmt.Fprintf(body, "%s %s `json:\"%s,omitempty\"`\n", fieldName, goType, name)
+	}
+
+	fmt.Fprintf(&g.types, "// %s is generated from the mapping for %s.\n", typeName, description)
+	fmt.Fprintf(&g.types, "type %s struct {\n%s}\n\n", typeName, body.String())
+
+	for _, generateObject := range objects {
+		if err := generateObject(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// optionalType returns a pointer to the type if omitempty can't tell an unset field from its zero value: otherwise a
+// false, 0, or 0,0 location would be dropped when the document is written, and a zero time.Time would be written
+// instead of being left out. Strings, maps, slices, and interfaces are left as they are.
+func optionalType(goType string) string {
+	if goType == "string" || goType == "interface{}" || strings.HasPrefix(goType, "map[") || strings.HasPrefix(goType, "[]") {
+		return goType
+	}
+
+	return "*" + goType
+}
+
+// goIdentifier converts a field or document kind name like "created_at" or "@timestamp" into an exported Go identifier,
+// like "CreatedAt" or "Timestamp". An empty string is returned if the name doesn't contain any letters or digits.
+func goIdentifier(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	identifier := &strings.Builder{}
+	for _, word := range words {
+		if upper := strings.ToUpper(word); commonInitialisms[upper] {
+			identifier.WriteString(upper)
+			continue
+		}
+
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		identifier.WriteString(string(runes))
+	}
+
+	result := identifier.String()
+	if result != "" && unicode.IsDigit([]rune(result)[0]) {
+		result = "X" + result
+	}
+
+	return result
+}
@@ -0,0 +1,238 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/rode/es-index-manager/indexmanager"
+	"github.com/rode/es-index-manager/mocks"
+)
+
+var _ = Describe("GenerateStructs", func() {
+	var (
+		mockRegistry *mocks.FakeMappingsRegistry
+		mappings     map[string]*VersionedMapping
+
+		actualSource []byte
+		actualError  error
+	)
+
+	BeforeEach(func() {
+		mockRegistry = &mocks.FakeMappingsRegistry{}
+		mappings = map[string]*VersionedMapping{
+			"build-event": {
+				Version: "v2",
+				Mappings: map[string]interface{}{
+					"properties": map[string]interface{}{
+						"@timestamp": map[string]interface{}{"type": "date"},
+						"id":         map[string]interface{}{"type": "keyword"},
+						"duration":   map[string]interface{}{"type": "long"},
+						"message": map[string]interface{}{
+							"type":   "text",
+							"fields": map[string]interface{}{"raw": map[string]interface{}{"type": "keyword"}},
+						},
+						"location": map[string]interface{}{"type": "geo_point"},
+						"labels":   map[string]interface{}{"type": "object"},
+						"source": map[string]interface{}{
+							"properties": map[string]interface{}{
+								"repository_url": map[string]interface{}{"type": "keyword"},
+							},
+						},
+						"steps": map[string]interface{}{
+							"type": "nested",
+							"properties": map[string]interface{}{
+								"name":   map[string]interface{}{"type": "keyword"},
+								"passed": map[string]interface{}{"type": "boolean"},
+							},
+						},
+						"shape": map[string]interface{}{"type": "geo_shape"},
+					},
+				},
+			},
+			"policy": {
+				Version: "v1",
+				Mappings: map[string]interface{}{
+					"properties": map[string]interface{}{
+						"score": map[string]interface{}{"type": "float"},
+					},
+				},
+			},
+		}
+		mockRegistry.DocumentKindsReturns([]string{"build-event", "policy"})
+		mockRegistry.MappingStub = func(documentKind string) *VersionedMapping {
+			return mappings[documentKind]
+		}
+	})
+
+	JustBeforeEach(func() {
+		actualSource, actualError = GenerateStructs(mockRegistry, "models")
+	})
+
+	It("should generate a struct and constants for each document kind", func() {
+		Expect(actualError).NotTo(HaveOccurred())
+		Expect(string(actualSource)).To(Equal(`// Code generated by es-index-manager. DO NOT EDIT.
+
+package models
+
+import "time"
+
+const (
+	BuildEventDocumentKind   = "build-event"
+	BuildEventMappingVersion = "v2"
+	PolicyDocumentKind       = "policy"
+	PolicyMappingVersion     = "v1"
+)
+
+// BuildEvent is generated from the mapping for document kind build-event.
+type BuildEvent struct {
+	Timestamp *time.Time             ` + "`" + `json:"@timestamp,omitempty"` + "`" + `
+	Duration  *int64                 ` + "`" + `json:"duration,omitempty"` + "`" + `
+	ID        string                 ` + "`" + `json:"id,omitempty"` + "`" + `
+	Labels    map[string]interface{} ` + "`" + `json:"labels,omitempty"` + "`" + `
+	Location  *GeoPoint              ` + "`" + `json:"location,omitempty"` + "`" + `
+	Message   string                 ` + "`" + `json:"message,omitempty"` + "`" + `
+	Shape     interface{}            ` + "`" + `json:"shape,omitempty"` + "`" + `
+	Source    *BuildEventSource      ` + "`" + `json:"source,omitempty"` + "`" + `
+	Steps     []*BuildEventSteps     ` + "`" + `json:"steps,omitempty"` + "`" + `
+}
+
+// BuildEventSource is generated from the mapping for the source field of BuildEvent.
+type BuildEventSource struct {
+	RepositoryURL string ` + "`" + `json:"repository_url,omitempty"` + "`" + `
+}
+
+// BuildEventSteps is generated from the mapping for the steps field of BuildEvent.
+type BuildEventSteps struct {
+	Name   string ` + "`" + `json:"name,omitempty"` + "`" + `
+	Passed *bool  ` + "`" + `json:"passed,omitempty"` + "`" + `
+}
+
+// Policy is generated from the mapping for document kind policy.
+type Policy struct {
+	Score *float32 ` + "`" + `json:"score,omitempty"` + "`" + `
+}
+
+// GeoPoint is a geo_point field in object format.
+type GeoPoint struct {
+	Lat float64 ` + "`" + `json:"lat"` + "`" + `
+	Lon float64 ` + "`" + `json:"lon"` + "`" + `
+}
+`))
+	})
+
+	When("a document is round-tripped through the generated structs", func() {
+		const roundTrip = `package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+func main() {
+	policy := &Policy{}
+	steps := &BuildEventSteps{}
+	event := &BuildEvent{}
+	_ = json.Unmarshal([]byte(os.Args[1]), policy)
+	_ = json.Unmarshal([]byte(os.Args[2]), steps)
+	_ = json.Unmarshal([]byte(os.Args[3]), event)
+	_ = json.NewEncoder(os.Stdout).Encode([]interface{}{policy, steps, event})
+}
+`
+
+		It("should keep false and zero values, and leave out unset fields", func() {
+			goBinary, err := exec.LookPath("go")
+			if err != nil {
+				Skip("the go command is needed to compile the generated structs")
+			}
+
+			source, err := GenerateStructs(mockRegistry, "main")
+			Expect(err).NotTo(HaveOccurred())
+
+			dir, err := os.MkdirTemp("", "codegen")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+			Expect(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module roundtrip\n\ngo 1.16\n"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "models.go"), source, 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "main.go"), []byte(roundTrip), 0644)).To(Succeed())
+
+			cmd := exec.Command(goBinary, "run", ".", `{"score":0}`, `{"name":"","passed":false}`, `{"duration":0,"location":{"lat":0,"lon":0}}`)
+			cmd.Dir = dir
+			output, err := cmd.CombinedOutput()
+			Expect(err).NotTo(HaveOccurred(), string(output))
+			Expect(output).To(MatchJSON(`[{"score":0},{"passed":false},{"duration":0,"location":{"lat":0,"lon":0}}]`))
+		})
+	})
+
+	When("no mapping has a date", func() {
+		BeforeEach(func() {
+			mockRegistry.DocumentKindsReturns([]string{"policy"})
+		})
+
+		It("should not import the time package", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+			Expect(string(actualSource)).NotTo(ContainSubstring("import"))
+			Expect(string(actualSource)).NotTo(ContainSubstring("GeoPoint"))
+		})
+	})
+
+	When("two fields have the same Go name", func() {
+		BeforeEach(func() {
+			mappings["policy"].Mappings["properties"].(map[string]interface{})["Score"] = map[string]interface{}{"type": "long"}
+		})
+
+		It("should return an error", func() {
+			Expect(actualError).To(HaveOccurred())
+			Expect(actualError.Error()).To(ContainSubstring(`fields "Score" and "score" both become Policy.Score`))
+		})
+	})
+
+	When("a document kind has the same Go name as an object field's struct", func() {
+		BeforeEach(func() {
+			mappings["build-event-source"] = &VersionedMapping{Version: "v1"}
+			mockRegistry.DocumentKindsReturns([]string{"build-event", "build-event-source"})
+		})
+
+		It("should return an error", func() {
+			Expect(actualError).To(MatchError(ContainSubstring("the source field of BuildEvent and document kind build-event-source both become BuildEventSource")))
+		})
+	})
+
+	When("a document kind has the same Go name as another document kind's constant", func() {
+		BeforeEach(func() {
+			mappings["policy-document-kind"] = &VersionedMapping{Version: "v1"}
+			mockRegistry.DocumentKindsReturns([]string{"policy", "policy-document-kind"})
+		})
+
+		It("should return an error", func() {
+			Expect(actualError).To(MatchError(ContainSubstring("a constant for document kind policy and document kind policy-document-kind both become PolicyDocumentKind")))
+		})
+	})
+
+	When("a document kind has the same Go name as the geo point struct", func() {
+		BeforeEach(func() {
+			mappings["geo-point"] = &VersionedMapping{Version: "v1"}
+			mockRegistry.DocumentKindsReturns([]string{"build-event", "geo-point"})
+		})
+
+		It("should return an error", func() {
+			Expect(actualError).To(MatchError(ContainSubstring("document kind geo-point and the geo_point type both become GeoPoint")))
+		})
+	})
+})
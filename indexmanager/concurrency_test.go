@@ -0,0 +1,322 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/rode/es-index-manager/indexmanager"
+)
+
+// These tests are meant to be run with the race detector, e.g. go test -race ./...
+
+// fakeCluster is a transport that keeps just enough state about indices and aliases to run migrations and create
+// indices, and that can be used from multiple goroutines.
+type fakeCluster struct {
+	mu      sync.Mutex
+	indices map[string]map[string]interface{}
+	// beforeRequest, if set, is called before each request is handled
+	beforeRequest func() error
+}
+
+func newFakeCluster() *fakeCluster {
+	return &fakeCluster{indices: map[string]map[string]interface{}{}}
+}
+
+func (c *fakeCluster) addIndex(name string, body map[string]interface{}) {
+	aliases, _ := body["aliases"].(map[string]interface{})
+	if aliases == nil {
+		aliases = map[string]interface{}{}
+	}
+
+	c.indices[name] = map[string]interface{}{
+		"aliases":  aliases,
+		"mappings": body["mappings"],
+	}
+}
+
+func (c *fakeCluster) hasIndex(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.indices[name]
+	return ok
+}
+
+// mappingVersions returns the version in the _meta of each index's mappings, keyed by index name.
+func (c *fakeCluster) mappingVersions() map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	versions := map[string]interface{}{}
+	for name, index := range c.indices {
+		mappings, _ := index["mappings"].(map[string]interface{})
+		meta, _ := mappings["_meta"].(map[string]interface{})
+		versions[name] = meta["version"]
+	}
+
+	return versions
+}
+
+func (c *fakeCluster) Perform(req *http.Request) (*http.Response, error) {
+	if c.beforeRequest != nil {
+		if err := c.beforeRequest(); err != nil {
+			return nil, err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	body := map[string]interface{}{}
+	if req.Body != nil {
+		_ = json.NewDecoder(req.Body).Decode(&body)
+	}
+
+	path := strings.Split(strings.TrimPrefix(req.URL.Path, "/"), "/")
+	switch {
+	case req.Method == http.MethodGet && req.URL.Path == "/_all":
+		return c.respond(http.StatusOK, c.indices), nil
+	case req.Method == http.MethodHead && len(path) == 1:
+		if _, ok := c.indices[path[0]]; ok {
+			return c.respond(http.StatusOK, nil), nil
+		}
+		return c.respond(http.StatusNotFound, nil), nil
+	case req.Method == http.MethodPut && len(path) == 1:
+		c.addIndex(path[0], body)
+		return c.respond(http.StatusOK, map[string]interface{}{"acknowledged": true}), nil
+	case req.Method == http.MethodDelete && len(path) == 1:
+		delete(c.indices, path[0])
+		return c.respond(http.StatusOK, map[string]interface{}{"acknowledged": true}), nil
+	case req.Method == http.MethodGet && len(path) == 2 && path[1] == "_settings":
+		return c.respond(http.StatusOK, map[string]interface{}{
+			path[0]: map[string]interface{}{
+				"settings": map[string]interface{}{
+					"index": map[string]interface{}{"blocks": map[string]interface{}{"write": "true"}},
+				},
+			},
+		}), nil
+	case req.URL.Path == "/_aliases":
+		actions, _ := body["actions"].([]interface{})
+		for _, action := range actions {
+			for actionType, value := range action.(map[string]interface{}) {
+				alias := value.(map[string]interface{})
+				index, ok := c.indices[alias["index"].(string)]
+				if !ok {
+					continue
+				}
+
+				if actionType == "add" {
+					index["aliases"].(map[string]interface{})[alias["alias"].(string)] = map[string]interface{}{}
+				} else {
+					delete(index["aliases"].(map[string]interface{}), alias["alias"].(string))
+				}
+			}
+		}
+		return c.respond(http.StatusOK, map[string]interface{}{"acknowledged": true}), nil
+	case req.URL.Path == "/_reindex":
+		return c.respond(http.StatusOK, map[string]interface{}{"task": "node:1"}), nil
+	case strings.HasPrefix(req.URL.Path, "/_tasks/"):
+		return c.respond(http.StatusOK, map[string]interface{}{"completed": true}), nil
+	}
+
+	return c.respond(http.StatusOK, map[string]interface{}{"acknowledged": true, "shards_acknowledged": true}), nil
+}
+
+func (c *fakeCluster) respond(status int, body interface{}) *http.Response {
+	res := &http.Response{StatusCode: status, Header: http.Header{}}
+	if body != nil {
+		res.Body = createESBody(body)
+	} else {
+		res.Body = createESBody(map[string]interface{}{})
+	}
+
+	return res
+}
+
+// alternatingSource switches the version of a document kind between v<base> and v<base+1> every time the mappings
+// are loaded.
+type alternatingSource struct {
+	prefix string
+	base   int64
+	loads  int64
+}
+
+func (s *alternatingSource) Name() string {
+	return "alternating"
+}
+
+func (s *alternatingSource) Mappings() (map[string]*VersionedMapping, error) {
+	version := fmt.Sprintf("v%d", atomic.AddInt64(&s.loads, 1)%2+s.base)
+
+	return map[string]*VersionedMapping{
+		"build": {
+			Version:  version,
+			Mappings: map[string]interface{}{"_meta": map[string]interface{}{"type": s.prefix}},
+		},
+	}, nil
+}
+
+var _ = Describe("Concurrent use", func() {
+	var (
+		ctx         = context.Background()
+		indexPrefix string
+	)
+
+	BeforeEach(func() {
+		indexPrefix = fake.LetterN(10)
+	})
+
+	Context("MappingsRegistry", func() {
+		It("should allow reading the mappings while they're reloaded", func() {
+			registry := NewMappingsRegistry(&Config{
+				IndexPrefix:    indexPrefix,
+				MappingSources: []MappingSource{&alternatingSource{prefix: indexPrefix, base: 1}},
+			}, nil)
+			Expect(registry.LoadMappings()).To(Succeed())
+
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					Expect(registry.LoadMappings()).To(Succeed())
+				}
+			}()
+
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						Expect(registry.Version("build")).To(Or(Equal("v1"), Equal("v2")))
+						Expect(registry.DocumentKinds()).To(Equal([]string{"build"}))
+						Expect(registry.CurrentDocumentKind("build")).To(Equal("build"))
+						Expect(registry.ParseIndexName(registry.IndexName("build", "foo"))).NotTo(BeNil())
+						Expect(registry.Mapping("build")).NotTo(BeNil())
+					}
+				}()
+			}
+
+			wg.Wait()
+		})
+	})
+
+	Context("IndexManager", func() {
+		var (
+			cluster *fakeCluster
+			manager IndexManager
+		)
+
+		BeforeEach(func() {
+			cluster = newFakeCluster()
+			client := &elasticsearch.Client{Transport: cluster, API: esapi.New(cluster)}
+
+			meta := map[string]interface{}{"_meta": map[string]interface{}{"type": indexPrefix}}
+			registered := NewRegisteredSource()
+			registered.Register("policy", &VersionedMapping{Version: "v1", Mappings: meta})
+
+			manager = NewIndexManager(logger, client, &Config{
+				IndexPrefix:    indexPrefix,
+				MappingSources: []MappingSource{registered, &alternatingSource{prefix: indexPrefix, base: 2}},
+				Migration: &MigrationConfig{
+					PollAttempts: 1,
+					PollInterval: time.Millisecond,
+					Concurrency:  2,
+				},
+			})
+			Expect(manager.LoadMappings()).To(Succeed())
+			// reload between every step of the migrations, so that the build version changes while they run
+			cluster.beforeRequest = manager.LoadMappings
+
+			for _, inner := range []string{"a", "b", "c"} {
+				cluster.addIndex(fmt.Sprintf("%s-v1-%s-build", indexPrefix, inner), map[string]interface{}{
+					"aliases":  map[string]interface{}{manager.AliasName("build", inner): map[string]interface{}{}},
+					"mappings": meta,
+				})
+			}
+		})
+
+		It("should allow creating indices and reloading the mappings while migrations run", func() {
+			var (
+				wg     sync.WaitGroup
+				report *MigrationReport
+				err    error
+			)
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				report, err = manager.RunMigrations(ctx)
+			}()
+
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for i := 0; i < 10; i++ {
+					Expect(manager.LoadMappings()).To(Succeed())
+				}
+			}()
+
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func(inner string) {
+					defer GinkgoRecover()
+					defer wg.Done()
+					indexName := manager.IndexName("policy", inner)
+					Expect(manager.CreateIndex(ctx, indexName, manager.AliasName("policy", inner), "policy")).To(Succeed())
+				}(fmt.Sprintf("inner%d", i))
+			}
+
+			wg.Wait()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Succeeded()).To(HaveLen(3))
+			// the build version changes with every reload, but each target has to be created from the mapping of the
+			// version in its name
+			versions := cluster.mappingVersions()
+			for _, inner := range []string{"a", "b", "c"} {
+				Expect(cluster.hasIndex(fmt.Sprintf("%s-v1-%s-build", indexPrefix, inner))).To(BeFalse())
+
+				var targets []string
+				for _, version := range []string{"v2", "v3"} {
+					target := fmt.Sprintf("%s-%s-%s-build", indexPrefix, version, inner)
+					if cluster.hasIndex(target) {
+						targets = append(targets, target)
+						Expect(versions[target]).To(Equal(version))
+					}
+				}
+				Expect(targets).To(HaveLen(1))
+			}
+			for i := 0; i < 10; i++ {
+				Expect(cluster.hasIndex(manager.IndexName("policy", fmt.Sprintf("inner%d", i)))).To(BeTrue())
+			}
+		})
+	})
+})
@@ -0,0 +1,343 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	. "github.com/rode/es-index-manager/indexmanager/internal"
+	"go.uber.org/zap"
+)
+
+// dataStreamTemplatePriority is added to the length of the document kind to get the index template priority, so that
+// a data stream name shared by two document kinds (myapp-dated-audit is audit with an inner name of dated, or dated-audit
+// without one) doesn't match two templates with the same priority.
+const dataStreamTemplatePriority = 200
+
+// putDataStreamTemplate installs the index template that data streams for the document kind are created from. The
+// template only matches the names of data streams that were created for the document kind, since a wildcard like
+// myapp-*-audit would also match regular indices, such as myapp-v1-group-audit, and Elasticsearch refuses to create
+// an index that matches a data stream template. dataStreamName is added to the names in the existing template.
+// Unless overwrite is true, an existing template that already matches dataStreamName is left alone, since updating
+// it is the job of a migration.
+func putDataStreamTemplate(ctx context.Context, client *elasticsearch.Client, registry MappingsRegistry, documentKind, dataStreamName string, mapping *VersionedMapping, overwrite bool) error {
+	templateName := registry.AliasName(documentKind, "")
+
+	res, err := client.Indices.GetIndexTemplate(
+		client.Indices.GetIndexTemplate.WithContext(ctx),
+		client.Indices.GetIndexTemplate.WithName(templateName),
+	)
+	if err != nil {
+		return fmt.Errorf("error getting index template %s: %s", templateName, err)
+	}
+
+	var indexPatterns []string
+	switch {
+	case res.StatusCode == http.StatusOK:
+		templateResponse := &EsIndexTemplateResponse{}
+		if err := decodeResponse(res.Body, templateResponse); err != nil {
+			return fmt.Errorf("error decoding index template response: %s", err)
+		}
+
+		for _, template := range templateResponse.IndexTemplates {
+			if template.Name == templateName && template.IndexTemplate != nil {
+				indexPatterns = template.IndexTemplate.IndexPatterns
+			}
+		}
+	case res.StatusCode != http.StatusNotFound:
+		return fmt.Errorf("unexpected status code (%d) when getting index template", res.StatusCode)
+	}
+
+	if containsString(indexPatterns, dataStreamName) {
+		if !overwrite {
+			return nil
+		}
+	} else {
+		indexPatterns = append(indexPatterns, dataStreamName)
+		sort.Strings(indexPatterns)
+	}
+
+	template := &EsIndexTemplate{
+		IndexPatterns: indexPatterns,
+		Priority:      dataStreamTemplatePriority + len(documentKind),
+		DataStream:    &EsDataStreamTemplate{},
+		Template: &EsIndexTemplateBody{
+			// the template outlives any one backing index, so it doesn't record a creation time
+			Mappings: indexMappings(registry, documentKind, mapping, time.Time{}),
+			Settings: indexSettings(registry, mapping, ""),
+		},
+	}
+
+	templateBody, _ := encodeRequest(template)
+	res, err = client.Indices.PutIndexTemplate(templateName, templateBody, client.Indices.PutIndexTemplate.WithContext(ctx))
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return fmt.Errorf("error installing index template %s: %s", templateName, err)
+	}
+
+	return nil
+}
+
+func getDataStreams(ctx context.Context, client *elasticsearch.Client, name string) ([]*EsDataStream, error) {
+	res, err := client.Indices.GetDataStream(
+		client.Indices.GetDataStream.WithContext(ctx),
+		client.Indices.GetDataStream.WithName(name),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting data streams: %s", err)
+	}
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if res.IsError() {
+		return nil, fmt.Errorf("error getting data streams, status: %d", res.StatusCode)
+	}
+
+	dataStreamResponse := &EsDataStreamResponse{}
+	if err := decodeResponse(res.Body, dataStreamResponse); err != nil {
+		return nil, fmt.Errorf("error decoding data stream response: %s", err)
+	}
+
+	return dataStreamResponse.DataStreams, nil
+}
+
+// getIndexMetadata returns the _meta of each index's mappings.
+func getIndexMetadata(ctx context.Context, client *elasticsearch.Client, indexNames []string) (map[string]*EsMeta, error) {
+	res, err := client.Indices.GetMapping(
+		client.Indices.GetMapping.WithContext(ctx),
+		client.Indices.GetMapping.WithIndex(indexNames...),
+	)
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return nil, fmt.Errorf("error getting index mappings: %s", err)
+	}
+
+	mappingResponse := map[string]EsIndex{}
+	if err := decodeResponse(res.Body, &mappingResponse); err != nil {
+		return nil, fmt.Errorf("error decoding mapping response: %s", err)
+	}
+
+	metadata := map[string]*EsMeta{}
+	for indexName, index := range mappingResponse {
+		if index.Mappings != nil && index.Mappings.Meta != nil {
+			metadata[indexName] = index.Mappings.Meta
+		}
+	}
+
+	return metadata, nil
+}
+
+// createDataStream installs the index template for the document kind if it's missing, then creates the data stream.
+func (ir *indexRepository) createDataStream(ctx context.Context, log *zap.Logger, dataStreamName, documentKind string, mapping *VersionedMapping) (bool, error) {
+	if dataStreamName == "" {
+		return false, fmt.Errorf("document kind %s is a data stream and requires an alias to use as the data stream name", documentKind)
+	}
+
+	if err := putDataStreamTemplate(ctx, ir.client, ir.registry, documentKind, dataStreamName, mapping, false); err != nil {
+		return false, err
+	}
+
+	res, err := ir.client.Indices.CreateDataStream(dataStreamName, ir.client.Indices.CreateDataStream.WithContext(ctx))
+	if err != nil {
+		return false, fmt.Errorf("error creating data stream %s: %s", dataStreamName, err)
+	}
+
+	if res.IsError() {
+		if res.StatusCode == http.StatusBadRequest {
+			errResponse := EsErrorResponse{}
+			if err := decodeResponse(res.Body, &errResponse); err != nil {
+				return false, fmt.Errorf("error decoding Elasticsearch error response: %s", err)
+			}
+
+			if errResponse.Error.Type == ElasticsearchResourceAlreadyExists {
+				log.Info("data stream already exists")
+				return false, nil
+			}
+		}
+
+		return false, fmt.Errorf("unexpected status code after creating data stream: %d", res.StatusCode)
+	}
+
+	log.Info("data stream created", zap.String("dataStream", dataStreamName))
+
+	return true, nil
+}
+
+// dataStreamMigrations finds the data streams whose write index was created from an older mapping.
+func (m *migrator) dataStreamMigrations(ctx context.Context, log *zap.Logger) ([]*Migration, error) {
+	hasDataStreams := false
+	for _, documentKind := range m.registry.DocumentKinds() {
+		if mapping := m.registry.Mapping(documentKind); mapping != nil && mapping.DataStream != nil {
+			hasDataStreams = true
+			break
+		}
+	}
+
+	if !hasDataStreams {
+		return nil, nil
+	}
+
+	dataStreams, err := getDataStreams(ctx, m.client, m.config.IndexPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+
+	var writeIndices []string
+	for _, dataStream := range dataStreams {
+		if len(dataStream.Indices) > 0 {
+			writeIndices = append(writeIndices, dataStream.Indices[len(dataStream.Indices)-1].IndexName)
+		}
+	}
+
+	if len(writeIndices) == 0 {
+		return nil, nil
+	}
+
+	metadata, err := getIndexMetadata(ctx, m.client, writeIndices)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(dataStreams, func(i, j int) bool {
+		return dataStreams[i].Name < dataStreams[j].Name
+	})
+
+	var migrations []*Migration
+	for _, dataStream := range dataStreams {
+		if len(dataStream.Indices) == 0 {
+			continue
+		}
+
+		writeIndex := dataStream.Indices[len(dataStream.Indices)-1].IndexName
+		meta := metadata[writeIndex]
+		if meta == nil || meta.Type != m.config.IndexPrefix || meta.DocumentKind == "" {
+			continue
+		}
+
+		mapping := m.registry.Mapping(meta.DocumentKind)
+		if mapping == nil || mapping.DataStream == nil {
+			log.Warn("Data stream belongs to a document kind that isn't managed as a data stream", zap.String("dataStream", dataStream.Name))
+			continue
+		}
+
+		comparison, err := CompareVersions(meta.Version, mapping.Version)
+		if err != nil {
+			return nil, fmt.Errorf("unable to determine if data stream %s needs to be migrated: %s", dataStream.Name, err)
+		}
+
+		if comparison == 0 {
+			if err := m.verifyChecksum(log, writeIndex, meta.DocumentKind, meta.Version, meta.Checksum); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if comparison > 0 && !m.config.Migration.AllowDowngrade {
+			log.Warn("Data stream is newer than the current mapping version, refusing to downgrade",
+				zap.String("dataStream", dataStream.Name),
+				zap.String("indexVersion", meta.Version),
+				zap.String("currentVersion", mapping.Version))
+			continue
+		}
+
+		migrations = append(migrations, &Migration{
+			Alias:        dataStream.Name,
+			SourceIndex:  writeIndex,
+			TargetIndex:  dataStream.Name,
+			DocumentKind: meta.DocumentKind,
+			DependsOn:    m.dependencies(meta.DocumentKind),
+			Mapping:      mapping,
+		})
+	}
+
+	return migrations, nil
+}
+
+// migrateDataStream updates the index template and rolls the data stream over, so that the new write index uses the
+// current mapping. Writes aren't blocked, since the data stream always has a write index.
+func (m *migrator) migrateDataStream(ctx context.Context, log *zap.Logger, migration *Migration, mapping *VersionedMapping) error {
+	log = log.With(zap.String("dataStream", migration.Alias))
+
+	log.Info("Updating index template")
+	if err := putDataStreamTemplate(ctx, m.client, m.registry, migration.DocumentKind, migration.Alias, mapping, true); err != nil {
+		return err
+	}
+
+	log.Info("Rolling over data stream")
+	res, err := m.client.Indices.Rollover(migration.Alias, m.client.Indices.Rollover.WithContext(ctx))
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return fmt.Errorf("error rolling over data stream %s: %s", migration.Alias, err)
+	}
+
+	if mapping.DataStream.ReindexOnMigration {
+		if err := m.reindexDataStream(ctx, log, migration, mapping); err != nil {
+			return err
+		}
+	}
+
+	log.Info("Migration complete")
+	return nil
+}
+
+// reindexDataStream copies the documents in outdated backing indices into the data stream, where they land in the
+// new write index, and then deletes the outdated backing indices.
+func (m *migrator) reindexDataStream(ctx context.Context, log *zap.Logger, migration *Migration, mapping *VersionedMapping) error {
+	dataStreams, err := getDataStreams(ctx, m.client, migration.Alias)
+	if err != nil {
+		return err
+	}
+
+	if len(dataStreams) != 1 || len(dataStreams[0].Indices) < 2 {
+		return nil
+	}
+
+	// the last backing index is the write index, which was just created from the current mapping
+	var backingIndices []string
+	for _, index := range dataStreams[0].Indices[:len(dataStreams[0].Indices)-1] {
+		backingIndices = append(backingIndices, index.IndexName)
+	}
+
+	metadata, err := getIndexMetadata(ctx, m.client, backingIndices)
+	if err != nil {
+		return err
+	}
+
+	for _, indexName := range backingIndices {
+		if meta := metadata[indexName]; meta != nil && meta.Version == mapping.Version {
+			continue
+		}
+
+		indexMigration := &Migration{
+			Alias:        migration.Alias,
+			SourceIndex:  indexName,
+			TargetIndex:  migration.Alias,
+			DocumentKind: migration.DocumentKind,
+		}
+		if err := m.reindex(ctx, log.With(zap.String("source", indexName)), indexMigration, nil); err != nil {
+			return err
+		}
+
+		if err := m.repo.DeleteIndex(ctx, indexName); err != nil {
+			return fmt.Errorf("error deleting backing index %s: %s", indexName, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,363 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing/fstest"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	. "github.com/rode/es-index-manager/indexmanager"
+	. "github.com/rode/es-index-manager/indexmanager/internal"
+	"github.com/rode/es-index-manager/mocks"
+)
+
+var _ = Describe("Data streams", func() {
+	var (
+		ctx           = context.Background()
+		config        *Config
+		mockTransport *mockEsTransport
+		mockEsClient  *elasticsearch.Client
+		mockRepo      *mocks.FakeIndexRepository
+
+		eventMapping *VersionedMapping
+
+		registry   MappingsRegistry
+		repository IndexRepository
+		migrator   Migrator
+	)
+
+	BeforeEach(func() {
+		config = &Config{
+			IndexPrefix:  "myapp",
+			MappingsPath: "mappings",
+			Migration: &MigrationConfig{
+				PollAttempts: 1,
+				PollInterval: time.Second,
+			},
+		}
+		eventMapping = &VersionedMapping{
+			Version: "v2",
+			Mappings: map[string]interface{}{
+				"_meta":      map[string]interface{}{"type": "myapp"},
+				"properties": map[string]interface{}{"@timestamp": map[string]interface{}{"type": "date"}},
+			},
+			DataStream: &DataStreamConfig{},
+		}
+
+		mockTransport = &mockEsTransport{}
+		mockEsClient = &elasticsearch.Client{Transport: mockTransport, API: esapi.New(mockTransport)}
+		mockRepo = &mocks.FakeIndexRepository{}
+	})
+
+	JustBeforeEach(func() {
+		registry = NewMappingsRegistry(config, fstest.MapFS{
+			"mappings/event.json":  mappingsFile(eventMapping),
+			"mappings/policy.json": mappingsFile(&VersionedMapping{Version: "v1"}),
+			// a regular document kind whose indices end in the data stream's document kind
+			"mappings/group-event.json": mappingsFile(&VersionedMapping{Version: "v1"}),
+		})
+		Expect(registry.LoadMappings()).To(Succeed())
+
+		repository = NewIndexRepository(logger, mockEsClient, registry)
+		migrator = NewMigrator(logger, mockEsClient, registry, mockRepo, func(time.Duration) {}, config)
+	})
+
+	Context("CreateIndex", func() {
+		var actualError error
+
+		JustBeforeEach(func() {
+			actualError = repository.CreateIndex(ctx, registry.IndexName("event", "foo"), registry.AliasName("event", "foo"), "event")
+		})
+
+		When("the index template doesn't exist", func() {
+			BeforeEach(func() {
+				mockTransport.preparedHttpResponses = []*http.Response{
+					{StatusCode: http.StatusNotFound},
+					{StatusCode: http.StatusOK},
+					{StatusCode: http.StatusOK},
+				}
+			})
+
+			It("should install the index template", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(mockTransport.receivedHttpRequests[0].Method).To(Equal(http.MethodGet))
+				Expect(mockTransport.receivedHttpRequests[0].URL.Path).To(Equal("/_index_template/myapp-event"))
+				Expect(mockTransport.receivedHttpRequests[1].Method).To(Equal(http.MethodPut))
+				Expect(mockTransport.receivedHttpRequests[1].URL.Path).To(Equal("/_index_template/myapp-event"))
+
+				actualPayload := map[string]interface{}{}
+				readRequestBody(mockTransport.receivedHttpRequests[1], &actualPayload)
+				Expect(actualPayload).To(MatchAllKeys(Keys{
+					"index_patterns": ConsistOf("myapp-foo-event"),
+					"priority":       BeNumerically(">", 200),
+					"data_stream":    BeEmpty(),
+					"template": MatchAllKeys(Keys{
+						"mappings": MatchAllKeys(Keys{
+							"_meta": Equal(map[string]interface{}{
+								"type":         "myapp",
+								"version":      "v2",
+								"checksum":     MappingChecksum(eventMapping),
+								"documentKind": "event",
+							}),
+							"properties": Equal(eventMapping.Mappings["properties"]),
+						}),
+					}),
+				}))
+			})
+
+			It("should create the data stream using the alias name", func() {
+				Expect(mockTransport.receivedHttpRequests).To(HaveLen(3))
+				Expect(mockTransport.receivedHttpRequests[2].Method).To(Equal(http.MethodPut))
+				Expect(mockTransport.receivedHttpRequests[2].URL.Path).To(Equal("/_data_stream/myapp-foo-event"))
+			})
+
+			It("should not match the indices of other document kinds", func() {
+				actualPayload := &EsIndexTemplate{}
+				readRequestBody(mockTransport.receivedHttpRequests[1], actualPayload)
+
+				for _, indexName := range []string{registry.IndexName("group-event", ""), registry.AliasName("group-event", "")} {
+					for _, pattern := range actualPayload.IndexPatterns {
+						Expect(filepath.Match(pattern, indexName)).To(BeFalse(), "pattern %s matches %s", pattern, indexName)
+					}
+				}
+			})
+		})
+
+		When("the index template exists for other data streams", func() {
+			BeforeEach(func() {
+				mockTransport.preparedHttpResponses = []*http.Response{
+					{StatusCode: http.StatusOK, Body: indexTemplateResponse("myapp-event", "myapp-bar-event")},
+					{StatusCode: http.StatusOK},
+					{StatusCode: http.StatusOK},
+				}
+			})
+
+			It("should add the data stream name to the index template", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(mockTransport.receivedHttpRequests).To(HaveLen(3))
+				Expect(mockTransport.receivedHttpRequests[1].Method).To(Equal(http.MethodPut))
+
+				actualPayload := &EsIndexTemplate{}
+				readRequestBody(mockTransport.receivedHttpRequests[1], actualPayload)
+				Expect(actualPayload.IndexPatterns).To(Equal([]string{"myapp-bar-event", "myapp-foo-event"}))
+			})
+		})
+
+		When("the index template and data stream already exist", func() {
+			BeforeEach(func() {
+				mockTransport.preparedHttpResponses = []*http.Response{
+					{StatusCode: http.StatusOK, Body: indexTemplateResponse("myapp-event", "myapp-foo-event")},
+					{
+						StatusCode: http.StatusBadRequest,
+						Body:       createEsErrorResponse("resource_already_exists_exception"),
+					},
+				}
+			})
+
+			It("should leave the template alone and not return an error", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(mockTransport.receivedHttpRequests).To(HaveLen(2))
+				Expect(mockTransport.receivedHttpRequests[1].URL.Path).To(Equal("/_data_stream/myapp-foo-event"))
+			})
+		})
+	})
+
+	Context("GetMigrations", func() {
+		var (
+			writeIndexVersion string
+			actualMigrations  []*Migration
+			actualError       error
+		)
+
+		BeforeEach(func() {
+			writeIndexVersion = "v1"
+		})
+
+		JustBeforeEach(func() {
+			mockTransport.preparedHttpResponses = []*http.Response{
+				{StatusCode: http.StatusOK, Body: createESBody(map[string]interface{}{})},
+				{
+					StatusCode: http.StatusOK,
+					Body: createESBody(map[string]interface{}{
+						"data_streams": []interface{}{
+							map[string]interface{}{
+								"name":     "myapp-foo-event",
+								"template": "myapp-event",
+								"indices": []interface{}{
+									map[string]interface{}{"index_name": ".ds-myapp-foo-event-000001"},
+									map[string]interface{}{"index_name": ".ds-myapp-foo-event-000002"},
+								},
+							},
+						},
+					}),
+				},
+				{
+					StatusCode: http.StatusOK,
+					Body: createESBody(map[string]interface{}{
+						".ds-myapp-foo-event-000002": map[string]interface{}{
+							"mappings": map[string]interface{}{
+								"_meta": map[string]interface{}{
+									"type":         "myapp",
+									"version":      writeIndexVersion,
+									"documentKind": "event",
+								},
+							},
+						},
+					}),
+				},
+			}
+
+			actualMigrations, actualError = migrator.GetMigrations(ctx)
+		})
+
+		It("should look up the data streams and the mapping of their write indices", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+			Expect(mockTransport.receivedHttpRequests[1].URL.Path).To(Equal("/_data_stream/myapp*"))
+			Expect(mockTransport.receivedHttpRequests[2].URL.Path).To(Equal("/.ds-myapp-foo-event-000002/_mapping"))
+		})
+
+		It("should plan a migration for the outdated data stream", func() {
+			Expect(actualMigrations).To(HaveLen(1))
+			Expect(actualMigrations[0]).To(PointTo(MatchFields(IgnoreExtras, Fields{
+				"Alias":        Equal("myapp-foo-event"),
+				"SourceIndex":  Equal(".ds-myapp-foo-event-000002"),
+				"TargetIndex":  Equal("myapp-foo-event"),
+				"DocumentKind": Equal("event"),
+			})))
+		})
+
+		When("the write index is at the current version", func() {
+			BeforeEach(func() {
+				writeIndexVersion = "v2"
+			})
+
+			It("should not plan a migration", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(actualMigrations).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("Migrate", func() {
+		var actualError error
+
+		BeforeEach(func() {
+			mockTransport.preparedHttpResponses = []*http.Response{
+				{StatusCode: http.StatusOK, Body: indexTemplateResponse("myapp-event", "myapp-foo-event")},
+				{StatusCode: http.StatusOK},
+				{StatusCode: http.StatusOK},
+			}
+		})
+
+		JustBeforeEach(func() {
+			actualError = migrator.Migrate(ctx, &Migration{
+				Alias:        "myapp-foo-event",
+				SourceIndex:  ".ds-myapp-foo-event-000002",
+				TargetIndex:  "myapp-foo-event",
+				DocumentKind: "event",
+			})
+		})
+
+		It("should update the index template and roll over the data stream", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+			Expect(mockTransport.receivedHttpRequests).To(HaveLen(3))
+			Expect(mockTransport.receivedHttpRequests[1].Method).To(Equal(http.MethodPut))
+			Expect(mockTransport.receivedHttpRequests[1].URL.Path).To(Equal("/_index_template/myapp-event"))
+			Expect(mockTransport.receivedHttpRequests[2].Method).To(Equal(http.MethodPost))
+			Expect(mockTransport.receivedHttpRequests[2].URL.Path).To(Equal("/myapp-foo-event/_rollover"))
+		})
+
+		It("should not create or delete any indices", func() {
+			Expect(mockRepo.CreateIndexCallCount()).To(Equal(0))
+			Expect(mockRepo.DeleteIndexCallCount()).To(Equal(0))
+		})
+
+		When("backing indices should be reindexed", func() {
+			BeforeEach(func() {
+				eventMapping.DataStream.ReindexOnMigration = true
+				mockTransport.preparedHttpResponses = append(mockTransport.preparedHttpResponses,
+					&http.Response{
+						StatusCode: http.StatusOK,
+						Body: createESBody(map[string]interface{}{
+							"data_streams": []interface{}{
+								map[string]interface{}{
+									"name": "myapp-foo-event",
+									"indices": []interface{}{
+										map[string]interface{}{"index_name": ".ds-myapp-foo-event-000001"},
+										map[string]interface{}{"index_name": ".ds-myapp-foo-event-000002"},
+										map[string]interface{}{"index_name": ".ds-myapp-foo-event-000003"},
+									},
+								},
+							},
+						}),
+					},
+					&http.Response{
+						StatusCode: http.StatusOK,
+						Body: createESBody(map[string]interface{}{
+							".ds-myapp-foo-event-000001": map[string]interface{}{
+								"mappings": map[string]interface{}{"_meta": map[string]interface{}{"version": "v1"}},
+							},
+							".ds-myapp-foo-event-000002": map[string]interface{}{
+								"mappings": map[string]interface{}{"_meta": map[string]interface{}{"version": "v2"}},
+							},
+						}),
+					},
+					&http.Response{StatusCode: http.StatusOK, Body: createESBody(map[string]interface{}{"task": "task-id"})},
+					&http.Response{StatusCode: http.StatusOK, Body: createESBody(map[string]interface{}{"completed": true})},
+					&http.Response{StatusCode: http.StatusOK},
+				)
+			})
+
+			It("should reindex outdated backing indices into the data stream", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(mockTransport.receivedHttpRequests).To(HaveLen(8))
+
+				reindexPayload := map[string]interface{}{}
+				readRequestBody(mockTransport.receivedHttpRequests[5], &reindexPayload)
+				Expect(reindexPayload["source"]).To(HaveKeyWithValue("index", ".ds-myapp-foo-event-000001"))
+				Expect(reindexPayload["dest"]).To(MatchAllKeys(Keys{
+					"index":   Equal("myapp-foo-event"),
+					"op_type": Equal("create"),
+				}))
+			})
+
+			It("should delete the outdated backing indices", func() {
+				Expect(mockRepo.DeleteIndexCallCount()).To(Equal(1))
+				_, actualIndexName := mockRepo.DeleteIndexArgsForCall(0)
+				Expect(actualIndexName).To(Equal(".ds-myapp-foo-event-000001"))
+			})
+		})
+	})
+})
+
+func indexTemplateResponse(name string, indexPatterns ...string) io.ReadCloser {
+	return createESBody(&EsIndexTemplateResponse{
+		IndexTemplates: []*EsNamedIndexTemplate{
+			{
+				Name:          name,
+				IndexTemplate: &EsIndexTemplate{IndexPatterns: indexPatterns},
+			},
+		},
+	})
+}
@@ -0,0 +1,226 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"go.uber.org/zap"
+)
+
+//counterfeiter:generate -o ../mocks . DriftDetector
+type DriftDetector interface {
+	// IndexDrift compares the mappings and settings of each index at the current version with the registry. Indices
+	// at other versions are left to the migration.
+	IndexDrift(ctx context.Context) ([]*IndexDrift, error)
+	// CheckDrift runs IndexDrift and handles any drift according to Config.DriftCheck.
+	CheckDrift(ctx context.Context) error
+}
+
+type driftDetector struct {
+	client   *elasticsearch.Client
+	config   *Config
+	logger   *zap.Logger
+	registry MappingsRegistry
+	settings SettingsManager
+}
+
+func NewDriftDetector(
+	logger *zap.Logger,
+	client *elasticsearch.Client,
+	registry MappingsRegistry,
+	settings SettingsManager,
+	config *Config,
+) DriftDetector {
+	return &driftDetector{
+		client,
+		config,
+		logger,
+		registry,
+		settings,
+	}
+}
+
+func (d *driftDetector) IndexDrift(ctx context.Context) ([]*IndexDrift, error) {
+	indices, err := currentIndices(ctx, d.client, d.registry, d.config.IndexPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var drift []*IndexDrift
+	if len(indices) != 0 {
+		drift, err = d.mappingDrift(ctx, indices)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	settingsChanges, err := d.settings.GetSettingsChanges(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range settingsChanges {
+		drift = append(drift, &IndexDrift{
+			Index:    change.Index,
+			Type:     IndexDriftSetting,
+			Name:     change.Setting,
+			Expected: change.Desired,
+			Actual:   change.Current,
+		})
+	}
+
+	sort.SliceStable(drift, func(i, j int) bool {
+		return drift[i].Index < drift[j].Index
+	})
+
+	return drift, nil
+}
+
+func (d *driftDetector) CheckDrift(ctx context.Context) error {
+	if d.config.DriftCheck == DriftCheckOff {
+		return nil
+	}
+
+	log := d.logger.Named("CheckDrift")
+	drift, err := d.IndexDrift(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, difference := range drift {
+		log.Warn("Index differs from the registry",
+			zap.String("index", difference.Index),
+			zap.String("type", string(difference.Type)),
+			zap.String("name", difference.Name),
+			zap.Any("expected", difference.Expected),
+			zap.Any("actual", difference.Actual),
+		)
+	}
+
+	if len(drift) != 0 && d.config.DriftCheck == DriftCheckFail {
+		return fmt.Errorf("found %d differences between the indices and the registry", len(drift))
+	}
+
+	return nil
+}
+
+// mappingDrift compares the field types in the registry with the ones in each index.
+func (d *driftDetector) mappingDrift(ctx context.Context, indices map[string]*IndexName) ([]*IndexDrift, error) {
+	var indexNames []string
+	for indexName := range indices {
+		indexNames = append(indexNames, indexName)
+	}
+	sort.Strings(indexNames)
+
+	res, err := d.client.Indices.GetMapping(
+		d.client.Indices.GetMapping.WithContext(ctx),
+		d.client.Indices.GetMapping.WithIndex(indexNames...),
+	)
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return nil, fmt.Errorf("error getting index mappings: %s", err)
+	}
+
+	liveMappings := map[string]struct {
+		Mappings map[string]interface{} `json:"mappings"`
+	}{}
+	if err := decodeResponse(res.Body, &liveMappings); err != nil {
+		return nil, fmt.Errorf("error decoding index mappings: %s", err)
+	}
+
+	var drift []*IndexDrift
+	for _, indexName := range indexNames {
+		expected := mappingFields(d.registry.Mapping(indices[indexName].DocumentKind).Mappings)
+		actual := mappingFields(liveMappings[indexName].Mappings)
+
+		var fieldNames []string
+		for field := range expected {
+			fieldNames = append(fieldNames, field)
+		}
+		for field := range actual {
+			if _, ok := expected[field]; !ok {
+				fieldNames = append(fieldNames, field)
+			}
+		}
+		sort.Strings(fieldNames)
+
+		for _, field := range fieldNames {
+			expectedType, inRegistry := expected[field]
+			actualType, inIndex := actual[field]
+			difference := &IndexDrift{Index: indexName, Name: field}
+
+			switch {
+			case !inIndex:
+				difference.Type = IndexDriftMissingField
+				difference.Expected = expectedType
+			case !inRegistry:
+				difference.Type = IndexDriftExtraField
+				difference.Actual = actualType
+			case expectedType != actualType:
+				difference.Type = IndexDriftChangedType
+				difference.Expected = expectedType
+				difference.Actual = actualType
+			default:
+				continue
+			}
+
+			drift = append(drift, difference)
+		}
+	}
+
+	return drift, nil
+}
+
+// mappingFields returns the type of every field in the mappings, keyed by its full path. Multi-fields are included
+// (e.g., name.keyword), and object fields without a type are treated as "object", like Elasticsearch does.
+func mappingFields(mappings map[string]interface{}) map[string]string {
+	fields := map[string]string{}
+	if properties, ok := mappings["properties"].(map[string]interface{}); ok {
+		collectFields("", properties, fields)
+	}
+
+	return fields
+}
+
+func collectFields(prefix string, properties map[string]interface{}, fields map[string]string) {
+	for name, value := range properties {
+		field, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		fieldType, _ := field["type"].(string)
+		if fieldType == "" {
+			fieldType = "object"
+		}
+		fields[path] = fieldType
+
+		if nested, ok := field["properties"].(map[string]interface{}); ok {
+			collectFields(path, nested, fields)
+		}
+
+		if multiFields, ok := field["fields"].(map[string]interface{}); ok {
+			collectFields(path, multiFields, fields)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/rode/es-index-manager/indexmanager"
+	"github.com/rode/es-index-manager/mocks"
+)
+
+var _ = Describe("DriftDetector", func() {
+	var (
+		ctx           = context.Background()
+		config        *Config
+		mockTransport *mockEsTransport
+		mockRegistry  *mocks.FakeMappingsRegistry
+		mockSettings  *mocks.FakeSettingsManager
+		detector      DriftDetector
+
+		indexPrefix   string
+		documentKind  string
+		currentIndex  string
+		olderIndex    string
+		liveMappings  map[string]interface{}
+		settingChange *SettingsChange
+
+		mappingsResponse *http.Response
+	)
+
+	BeforeEach(func() {
+		indexPrefix = fake.Word()
+		documentKind = fake.Word()
+		config = &Config{IndexPrefix: indexPrefix}
+		mockTransport = &mockEsTransport{}
+		mockEsClient := &elasticsearch.Client{Transport: mockTransport, API: esapi.New(mockTransport)}
+		mockRegistry = &mocks.FakeMappingsRegistry{}
+		mockSettings = &mocks.FakeSettingsManager{}
+
+		currentIndex = createIndexOrAliasName(indexPrefix, "v2", documentKind)
+		olderIndex = createIndexOrAliasName(indexPrefix, "v1", documentKind)
+
+		mockRegistry.MappingReturns(&VersionedMapping{
+			Version: "v2",
+			Mappings: map[string]interface{}{
+				"properties": map[string]interface{}{
+					"name": map[string]interface{}{
+						"type": "text",
+						"fields": map[string]interface{}{
+							"keyword": map[string]interface{}{"type": "keyword"},
+						},
+					},
+					"count": map[string]interface{}{"type": "integer"},
+					"user": map[string]interface{}{
+						"properties": map[string]interface{}{
+							"id": map[string]interface{}{"type": "keyword"},
+						},
+					},
+				},
+			},
+		})
+		mockRegistry.ParseIndexNameStub = func(indexName string) *IndexName {
+			version := "v2"
+			if indexName == olderIndex {
+				version = "v1"
+			}
+
+			return &IndexName{DocumentKind: documentKind, Version: version}
+		}
+
+		liveMappings = map[string]interface{}{
+			"properties": map[string]interface{}{
+				"name": map[string]interface{}{
+					"type": "text",
+					"fields": map[string]interface{}{
+						"keyword": map[string]interface{}{"type": "keyword"},
+					},
+				},
+				"count": map[string]interface{}{"type": "integer"},
+				"user": map[string]interface{}{
+					"properties": map[string]interface{}{
+						"id": map[string]interface{}{"type": "keyword"},
+					},
+				},
+			},
+		}
+		settingChange = &SettingsChange{
+			Index:   currentIndex,
+			Setting: "index.number_of_shards",
+			Current: "1",
+			Desired: "3",
+		}
+
+		mappingsResponse = &http.Response{StatusCode: http.StatusOK}
+		mockTransport.preparedHttpResponses = []*http.Response{
+			// get all indices
+			{
+				StatusCode: http.StatusOK,
+				Body: createESBody(map[string]interface{}{
+					currentIndex: managedIndexResponse(indexPrefix, nil, false),
+					olderIndex:   managedIndexResponse(indexPrefix, nil, false),
+				}),
+			},
+			mappingsResponse,
+		}
+
+		detector = NewDriftDetector(logger, mockEsClient, mockRegistry, mockSettings, config)
+	})
+
+	JustBeforeEach(func() {
+		// the body is built last, so that tests can change the live mappings
+		mappingsResponse.Body = createESBody(map[string]interface{}{
+			currentIndex: map[string]interface{}{"mappings": liveMappings},
+		})
+	})
+
+	Context("IndexDrift", func() {
+		var (
+			actualDrift []*IndexDrift
+			actualError error
+		)
+
+		JustBeforeEach(func() {
+			actualDrift, actualError = detector.IndexDrift(ctx)
+		})
+
+		When("the index matches the registry", func() {
+			It("should not report any drift", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(actualDrift).To(BeEmpty())
+			})
+
+			It("should only fetch the mappings of indices at the current version", func() {
+				Expect(mockTransport.receivedHttpRequests).To(HaveLen(2))
+				Expect(mockTransport.receivedHttpRequests[1].URL.Path).To(Equal(fmt.Sprintf("/%s/_mapping", currentIndex)))
+			})
+		})
+
+		When("the index has drifted", func() {
+			BeforeEach(func() {
+				properties := liveMappings["properties"].(map[string]interface{})
+				delete(properties, "count")
+				properties["name"] = map[string]interface{}{"type": "keyword"}
+				properties["extra"] = map[string]interface{}{"type": "long"}
+				properties["user"].(map[string]interface{})["properties"].(map[string]interface{})["email"] = map[string]interface{}{"type": "text"}
+
+				mockSettings.GetSettingsChangesReturns([]*SettingsChange{settingChange}, nil)
+			})
+
+			It("should categorize the differences", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(actualDrift).To(Equal([]*IndexDrift{
+					{Index: currentIndex, Type: IndexDriftMissingField, Name: "count", Expected: "integer"},
+					{Index: currentIndex, Type: IndexDriftExtraField, Name: "extra", Actual: "long"},
+					{Index: currentIndex, Type: IndexDriftChangedType, Name: "name", Expected: "text", Actual: "keyword"},
+					{Index: currentIndex, Type: IndexDriftMissingField, Name: "name.keyword", Expected: "keyword"},
+					{Index: currentIndex, Type: IndexDriftExtraField, Name: "user.email", Actual: "text"},
+					{Index: currentIndex, Type: IndexDriftSetting, Name: "index.number_of_shards", Expected: "3", Actual: "1"},
+				}))
+			})
+		})
+
+		When("getting the mappings fails", func() {
+			BeforeEach(func() {
+				mappingsResponse.StatusCode = http.StatusInternalServerError
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(actualError.Error()).To(ContainSubstring("error getting index mappings"))
+			})
+		})
+
+		When("getting the settings changes fails", func() {
+			BeforeEach(func() {
+				mockSettings.GetSettingsChangesReturns(nil, errors.New(fake.Word()))
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(HaveOccurred())
+			})
+		})
+	})
+
+	Context("CheckDrift", func() {
+		var actualError error
+
+		BeforeEach(func() {
+			mockSettings.GetSettingsChangesReturns([]*SettingsChange{settingChange}, nil)
+		})
+
+		JustBeforeEach(func() {
+			actualError = detector.CheckDrift(ctx)
+		})
+
+		When("the check is off", func() {
+			It("should not look for drift", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(mockTransport.receivedHttpRequests).To(BeEmpty())
+			})
+		})
+
+		When("the check warns", func() {
+			BeforeEach(func() {
+				config.DriftCheck = DriftCheckWarn
+			})
+
+			It("should not return an error", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(mockSettings.GetSettingsChangesCallCount()).To(Equal(1))
+			})
+		})
+
+		When("the check fails", func() {
+			BeforeEach(func() {
+				config.DriftCheck = DriftCheckFail
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(actualError.Error()).To(ContainSubstring("found 1 differences"))
+			})
+
+			When("there is no drift", func() {
+				BeforeEach(func() {
+					mockSettings.GetSettingsChangesReturns(nil, nil)
+				})
+
+				It("should not return an error", func() {
+					Expect(actualError).NotTo(HaveOccurred())
+				})
+			})
+		})
+	})
+})
@@ -17,7 +17,6 @@ package indexmanager
 import (
 	"context"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/elastic/go-elasticsearch/v7"
@@ -26,21 +25,42 @@ import (
 
 //go:generate counterfeiter -generate
 
+// IndexManager is safe for concurrent use. In particular, request handlers can call CreateIndex while RunMigrations,
+// LoadMappings, or Watch are running in another goroutine.
+//
 //counterfeiter:generate -o ../mocks . IndexManager
 type IndexManager interface {
 	MappingsRegistry
 	IndexRepository
+	Adopter
 	MigrationOrchestrator
-	// Initialize loads document kind mappings from the path specified in Config.MappingsPath.
-	// Then, using the prefix from Config.IndexPrefix, it finds any indices associated with the application; and, if
-	// necessary, runs a migration to apply schema changes.
+	IndexCleaner
+	ResourceManager
+	SettingsManager
+	DriftDetector
+	DocumentTransfer
+	// Initialize loads document kind mappings from the path specified in Config.MappingsPath, and installs any
+	// resources they depend on, like ILM policies. Then, using the prefix from Config.IndexPrefix, it finds any indices associated with the application; and, if
+	// necessary, runs a migration to apply schema changes. Dynamic settings that changed without a version bump are applied afterwards.
+	// Finally, the indices are checked for drift from the registry, according to Config.DriftCheck.
+	// Once initialized, indices created through the IndexManager are seeded when Config.Environment is one of
+	// Config.SeedEnvironments.
 	Initialize(context.Context) error
 }
 
 type indexManager struct {
 	MappingsRegistry
 	IndexRepository
+	Adopter
 	MigrationOrchestrator
+	IndexCleaner
+	ResourceManager
+	SettingsManager
+	DriftDetector
+	DocumentTransfer
+	config *Config
+	// repo is the same as the embedded IndexRepository, which reports whether an index was created
+	repo *indexRepository
 }
 
 func NewIndexManager(logger *zap.Logger, client *elasticsearch.Client, config *Config) IndexManager {
@@ -51,13 +71,23 @@ func NewIndexManager(logger *zap.Logger, client *elasticsearch.Client, config *C
 		}
 	}
 
-	registry := NewMappingsRegistry(config, os.DirFS("."))
-	repo := NewIndexRepository(logger, client, registry)
-	orchestrator := NewMigrationOrchestrator(logger, NewMigrator(logger, client, registry, repo, time.Sleep, config))
+	registry := NewDirectoryMappingsRegistry(config, ".")
+	repo := newIndexRepository(logger, client, registry)
+	migrator := NewMigrator(logger, client, registry, repo, time.Sleep, config)
+	orchestrator := NewMigrationOrchestrator(logger, migrator, config)
+	settings := NewSettingsManager(logger, client, registry, config)
 	return &indexManager{
 		registry,
 		repo,
+		migrator,
 		orchestrator,
+		NewIndexCleaner(logger, client, registry, repo, config),
+		NewResourceManager(logger, client, registry, config),
+		settings,
+		NewDriftDetector(logger, client, registry, settings, config),
+		NewDocumentTransfer(logger, client, registry, repo, config),
+		config,
+		repo,
 	}
 }
 
@@ -66,9 +96,60 @@ func (im *indexManager) Initialize(ctx context.Context) error {
 		return fmt.Errorf("error occurred loading index mappings: %s", err)
 	}
 
-	if err := im.RunMigrations(ctx); err != nil {
+	if err := im.InstallResources(ctx); err != nil {
+		return fmt.Errorf("error installing resources: %s", err)
+	}
+
+	if _, err := im.RunMigrations(ctx); err != nil {
 		return fmt.Errorf("error running migrations: %s", err)
 	}
 
+	if _, err := im.UpdateSettings(ctx); err != nil {
+		return fmt.Errorf("error updating settings: %s", err)
+	}
+
+	if err := im.CheckDrift(ctx); err != nil {
+		return fmt.Errorf("error checking for drift: %s", err)
+	}
+
+	return nil
+}
+
+// CreateIndex creates the index using the IndexRepository, and then loads any seed documents for the document kind if
+// seeding is enabled for the environment. Indices that already existed aren't seeded again.
+func (im *indexManager) CreateIndex(ctx context.Context, indexName, aliasName, documentKind string) error {
+	created, err := im.repo.createIfMissing(ctx, indexName, aliasName, documentKind)
+	if err != nil || !created {
+		return err
+	}
+
+	if !im.seedingEnabled() || im.Seeds(documentKind) == nil {
+		return nil
+	}
+
+	name := im.ParseIndexName(indexName)
+	if name == nil {
+		return nil
+	}
+
+	report, err := im.Seed(ctx, documentKind, name.Inner)
+	if err != nil {
+		return fmt.Errorf("error seeding index %s: %s", indexName, err)
+	}
+
+	if len(report.Failed) != 0 {
+		return fmt.Errorf("error seeding index %s: %d documents failed", indexName, len(report.Failed))
+	}
+
 	return nil
 }
+
+func (im *indexManager) seedingEnabled() bool {
+	for _, environment := range im.config.SeedEnvironments {
+		if environment == im.config.Environment {
+			return true
+		}
+	}
+
+	return false
+}
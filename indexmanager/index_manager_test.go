@@ -0,0 +1,163 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/rode/es-index-manager/indexmanager"
+)
+
+var _ = Describe("IndexManager", func() {
+	var (
+		ctx           = context.Background()
+		workingDir    string
+		mockTransport *mockEsTransport
+		manager       IndexManager
+	)
+
+	BeforeEach(func() {
+		var err error
+		workingDir, err = os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+
+		tempDir, err := os.MkdirTemp("", "mappings")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir(tempDir)).To(Succeed())
+
+		Expect(os.MkdirAll(filepath.Join("mappings", "seeds"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join("mappings", "policy.json"), []byte(`{"version":"v1"}`), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join("mappings", "seeds", "policy.ndjson"), []byte(`{"_id":"a","_source":{"name":"a"}}`), 0644)).To(Succeed())
+
+		mockTransport = &mockEsTransport{}
+		client := &elasticsearch.Client{Transport: mockTransport, API: esapi.New(mockTransport)}
+		manager = NewIndexManager(logger, client, &Config{
+			IndexPrefix:      fake.Word(),
+			MappingsPath:     "mappings",
+			Environment:      "local",
+			SeedEnvironments: []string{"local"},
+		})
+		Expect(manager.LoadMappings()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		tempDir, _ := os.Getwd()
+		Expect(os.Chdir(workingDir)).To(Succeed())
+		Expect(os.RemoveAll(tempDir)).To(Succeed())
+	})
+
+	Context("CreateIndex", func() {
+		var actualError error
+
+		JustBeforeEach(func() {
+			actualError = manager.CreateIndex(ctx, manager.IndexName("policy", ""), manager.AliasName("policy", ""), "policy")
+		})
+
+		When("the index doesn't exist", func() {
+			BeforeEach(func() {
+				mockTransport.preparedHttpResponses = []*http.Response{
+					// check if the index exists
+					{StatusCode: http.StatusNotFound},
+					// create the index
+					{StatusCode: http.StatusOK},
+					// check again before seeding
+					{StatusCode: http.StatusOK},
+					{
+						StatusCode: http.StatusOK,
+						Body: createESBody(map[string]interface{}{
+							"errors": false,
+							"items": []interface{}{
+								map[string]interface{}{"create": map[string]interface{}{"_id": "a", "status": 201}},
+							},
+						}),
+					},
+				}
+			})
+
+			It("should create and seed the index", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(mockTransport.receivedHttpRequests).To(HaveLen(4))
+				Expect(mockTransport.receivedHttpRequests[3].URL.Path).To(Equal("/" + manager.IndexName("policy", "") + "/_bulk"))
+			})
+		})
+
+		When("the index already exists", func() {
+			BeforeEach(func() {
+				mockTransport.preparedHttpResponses = []*http.Response{
+					{StatusCode: http.StatusOK},
+				}
+			})
+
+			It("should not seed the index again", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(mockTransport.receivedHttpRequests).To(HaveLen(1))
+			})
+		})
+	})
+
+	When("a document kind is defined by a struct", func() {
+		var (
+			client *elasticsearch.Client
+			prefix string
+		)
+
+		newConfig := func(version string) *Config {
+			return &Config{
+				IndexPrefix: prefix,
+				DocumentStructs: map[string]*DocumentStruct{
+					"build": {Version: version, Document: &testBuild{}},
+				},
+				Migration: &MigrationConfig{},
+			}
+		}
+
+		newRegistry := func(config *Config) MappingsRegistry {
+			registry := NewMappingsRegistry(config, os.DirFS("."))
+			Expect(registry.LoadMappings()).To(Succeed())
+
+			return registry
+		}
+
+		BeforeEach(func() {
+			cluster := newFakeCluster()
+			client = &elasticsearch.Client{Transport: cluster, API: esapi.New(cluster)}
+			prefix = fake.Word()
+		})
+
+		It("should migrate indices created from an older version of the struct", func() {
+			v1 := newRegistry(newConfig("v1"))
+			Expect(NewIndexRepository(logger, client, v1).CreateIndex(ctx, v1.IndexName("build", ""), v1.AliasName("build", ""), "build")).To(Succeed())
+
+			config := newConfig("v2")
+			v2 := newRegistry(config)
+			migrator := NewMigrator(logger, client, v2, NewIndexRepository(logger, client, v2), func(time.Duration) {}, config)
+
+			migrations, err := migrator.GetMigrations(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(migrations).To(HaveLen(1))
+			Expect(migrations[0].DocumentKind).To(Equal("build"))
+			Expect(migrations[0].SourceIndex).To(Equal(v1.IndexName("build", "")))
+			Expect(migrations[0].TargetIndex).To(Equal(v2.IndexName("build", "")))
+		})
+	})
+})
@@ -16,8 +16,12 @@ package indexmanager
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/elastic/go-elasticsearch/v7"
 	. "github.com/rode/es-index-manager/indexmanager/internal"
@@ -28,9 +32,13 @@ import (
 type IndexRepository interface {
 	// CreateIndex makes a new index using the mappings supplied for the document kind.
 	// If there's an alias specified in metadata, it's added to the index.
+	// For data stream document kinds, the alias is used as the name of the data stream instead.
 	CreateIndex(ctx context.Context, indexName, aliasName, documentKind string) error
 	// DeleteIndex deletes the index, which also removes any associated aliases.
 	DeleteIndex(ctx context.Context, indexName string) error
+	// Rollover creates the next index in a time series and makes it the write index for the alias, if any of the
+	// rollover conditions for the document kind are met. If there are no conditions, the series is always rolled over.
+	Rollover(ctx context.Context, documentKind, inner string) (*RolloverResult, error)
 }
 
 type indexRepository struct {
@@ -40,6 +48,10 @@ type indexRepository struct {
 }
 
 func NewIndexRepository(logger *zap.Logger, client *elasticsearch.Client, registry MappingsRegistry) IndexRepository {
+	return newIndexRepository(logger, client, registry)
+}
+
+func newIndexRepository(logger *zap.Logger, client *elasticsearch.Client, registry MappingsRegistry) *indexRepository {
 	return &indexRepository{
 		client,
 		logger,
@@ -48,69 +60,130 @@ func NewIndexRepository(logger *zap.Logger, client *elasticsearch.Client, regist
 }
 
 func (ir *indexRepository) CreateIndex(ctx context.Context, indexName, aliasName, documentKind string) error {
+	_, err := ir.createIfMissing(ctx, indexName, aliasName, documentKind)
+
+	return err
+}
+
+// createIfMissing does the work of CreateIndex, and returns true if the index was created, or false if it (or the
+// time series or data stream) already existed.
+func (ir *indexRepository) createIfMissing(ctx context.Context, indexName, aliasName, documentKind string) (bool, error) {
+	return ir.createWithMapping(ctx, indexName, aliasName, documentKind, ir.registry.Mapping(documentKind))
+}
+
+// createWithMapping creates the index like CreateIndex, but from a mapping that was read from the registry earlier,
+// such as when a migration was planned.
+func (ir *indexRepository) createWithMapping(ctx context.Context, indexName, aliasName, documentKind string, mapping *VersionedMapping) (bool, error) {
 	log := ir.logger.Named("CreateIndex").With(zap.String("index", indexName))
 
+	if mapping != nil && mapping.DataStream != nil {
+		return ir.createDataStream(ctx, log, aliasName, documentKind, mapping)
+	}
+
+	if mapping != nil && mapping.TimeSeries != nil {
+		// indices that are already part of a series (e.g., targets of a migration) are created as-is
+		if parts := ir.registry.ParseIndexName(indexName); parts == nil || parts.Generation == 0 {
+			return ir.createTimeSeries(ctx, log, indexName, aliasName, documentKind, mapping)
+		}
+	}
+
 	res, err := ir.client.Indices.Exists([]string{indexName}, ir.client.Indices.Exists.WithContext(ctx))
 	if err != nil {
-		return fmt.Errorf("error checking if index %s exists: %s", indexName, err)
+		return false, fmt.Errorf("error checking if index %s exists: %s", indexName, err)
 	}
 
 	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNotFound {
 		log.Error("error checking if index exists", zap.String("response", res.String()))
 
-		return fmt.Errorf("unexpected status code (%d) when checking if index exists", res.StatusCode)
+		return false, fmt.Errorf("unexpected status code (%d) when checking if index exists", res.StatusCode)
 	}
 
 	// if the response was successful, then the index already exists and we can skip creation
 	if !res.IsError() {
-		return nil
+		return false, nil
 	}
 
-	mapping := ir.registry.Mapping(documentKind)
 	if mapping == nil {
-		return fmt.Errorf("unable to find a mapping for document kind %s", documentKind)
+		return false, fmt.Errorf("unable to find a mapping for document kind %s", documentKind)
+	}
+
+	rolloverAlias := ""
+	if parts := ir.registry.ParseIndexName(indexName); mapping.TimeSeries != nil && parts != nil {
+		rolloverAlias = ir.registry.AliasName(parts.DocumentKind, parts.Inner)
+	}
+
+	return ir.createIndex(ctx, log, indexName, indexSettings(ir.registry, mapping, rolloverAlias), indexMappings(ir.registry, documentKind, mapping, time.Now()), aliasName, map[string]interface{}{})
+}
+
+// createTimeSeries creates the first index in a time series, unless the series' alias already exists.
+func (ir *indexRepository) createTimeSeries(ctx context.Context, log *zap.Logger, indexName, aliasName, documentKind string, mapping *VersionedMapping) (bool, error) {
+	if aliasName == "" {
+		return false, fmt.Errorf("document kind %s is a time series and requires an alias", documentKind)
+	}
+
+	res, err := ir.client.Indices.ExistsAlias([]string{aliasName}, ir.client.Indices.ExistsAlias.WithContext(ctx))
+	if err != nil {
+		return false, fmt.Errorf("error checking if alias %s exists: %s", aliasName, err)
+	}
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNotFound {
+		log.Error("error checking if alias exists", zap.String("response", res.String()))
+
+		return false, fmt.Errorf("unexpected status code (%d) when checking if alias exists", res.StatusCode)
 	}
 
+	if !res.IsError() {
+		return false, nil
+	}
+
+	firstIndex := ir.registry.TimeSeriesIndexName(indexName, 1, timeSeriesDate(mapping.TimeSeries, time.Now()))
+
+	return ir.createIndex(ctx, log.With(zap.String("index", firstIndex)), firstIndex, indexSettings(ir.registry, mapping, aliasName), indexMappings(ir.registry, documentKind, mapping, time.Now()), aliasName, map[string]interface{}{
+		"is_write_index": true,
+	})
+}
+
+func (ir *indexRepository) createIndex(ctx context.Context, log *zap.Logger, indexName string, settings, mappings map[string]interface{}, aliasName string, aliasSettings map[string]interface{}) (bool, error) {
 	createIndexReq := map[string]interface{}{
-		"mappings": mapping.Mappings,
+		"mappings": mappings,
 	}
-	if mapping.Settings != nil {
-		createIndexReq["settings"] = mapping.Settings
+	if settings != nil {
+		createIndexReq["settings"] = settings
 	}
 
 	if aliasName != "" {
 		createIndexReq["aliases"] = map[string]interface{}{
-			aliasName: map[string]interface{}{},
+			aliasName: aliasSettings,
 		}
 	}
 
 	payload, _ := encodeRequest(&createIndexReq)
-	res, err = ir.client.Indices.Create(indexName, ir.client.Indices.Create.WithContext(ctx), ir.client.Indices.Create.WithBody(payload))
+	res, err := ir.client.Indices.Create(indexName, ir.client.Indices.Create.WithContext(ctx), ir.client.Indices.Create.WithBody(payload))
 	if err != nil {
-		return fmt.Errorf("error creating index %s: %s", indexName, err)
+		return false, fmt.Errorf("error creating index %s: %s", indexName, err)
 	}
 
 	if res.IsError() {
 		if res.StatusCode == http.StatusBadRequest {
 			errResponse := EsErrorResponse{}
 			if err := decodeResponse(res.Body, &errResponse); err != nil {
-				return fmt.Errorf("error decoding Elasticsearch error response: %s", err)
+				return false, fmt.Errorf("error decoding Elasticsearch error response: %s", err)
 			}
 
 			// there's a chance for another instance of the application to try to create the same index (e.g., during migrations)
 			// so treat that differently than an error
 			if errResponse.Error.Type == ElasticsearchResourceAlreadyExists {
 				log.Info("index already exists")
-				return nil
+				return false, nil
 			}
 		}
 
-		return fmt.Errorf("unexpected status code after creating index: %d", res.StatusCode)
+		return false, fmt.Errorf("unexpected status code after creating index: %d", res.StatusCode)
 	}
 
 	log.Info("index created")
 
-	return nil
+	return true, nil
 }
 
 func (ir *indexRepository) DeleteIndex(ctx context.Context, indexName string) error {
@@ -133,3 +206,118 @@ func (ir *indexRepository) DeleteIndex(ctx context.Context, indexName string) er
 
 	return nil
 }
+
+func (ir *indexRepository) Rollover(ctx context.Context, documentKind, inner string) (*RolloverResult, error) {
+	mapping := ir.registry.Mapping(documentKind)
+	if mapping == nil {
+		return nil, fmt.Errorf("unable to find a mapping for document kind %s", documentKind)
+	}
+
+	if mapping.TimeSeries == nil {
+		return nil, fmt.Errorf("document kind %s is not a time series", documentKind)
+	}
+
+	alias := ir.registry.AliasName(documentKind, inner)
+	log := ir.logger.Named("Rollover").With(zap.String("alias", alias))
+
+	indices, err := getAliasIndices(ctx, ir.client, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	generation := 0
+	for indexName := range indices {
+		if parts := ir.registry.ParseIndexName(indexName); parts != nil && parts.Generation > generation {
+			generation = parts.Generation
+		}
+	}
+
+	newIndex := ir.registry.TimeSeriesIndexName(ir.registry.IndexName(documentKind, inner), generation+1, timeSeriesDate(mapping.TimeSeries, time.Now()))
+	result, err := rollover(ctx, ir.client, alias, newIndex, mapping.TimeSeries.Rollover, indexMappings(ir.registry, documentKind, mapping, time.Now()), indexSettings(ir.registry, mapping, alias))
+	if err != nil {
+		return nil, err
+	}
+
+	if result.RolledOver {
+		log.Info("rolled over time series", zap.String("oldIndex", result.OldIndex), zap.String("newIndex", result.NewIndex))
+	} else {
+		log.Debug("rollover conditions not met")
+	}
+
+	return result, nil
+}
+
+// indexSettings returns the settings for a new index of the document kind, which attach the ILM policy and default
+// pipeline, if there are any. Indices in a time series also need the alias to roll over.
+func indexSettings(registry MappingsRegistry, mapping *VersionedMapping, rolloverAlias string) map[string]interface{} {
+	if mapping.ILMPolicy == "" && mapping.DefaultPipeline == "" {
+		return mapping.Settings
+	}
+
+	settings := map[string]interface{}{}
+	for k, v := range mapping.Settings {
+		settings[k] = v
+	}
+
+	if mapping.ILMPolicy != "" {
+		settings["index.lifecycle.name"] = registry.ResourceName(mapping.ILMPolicy)
+		if rolloverAlias != "" {
+			settings["index.lifecycle.rollover_alias"] = rolloverAlias
+		}
+	}
+
+	if mapping.DefaultPipeline != "" {
+		settings["index.default_pipeline"] = registry.ResourceName(mapping.DefaultPipeline)
+	}
+
+	return settings
+}
+
+// indexMappings adds the version, checksum, and document kind to the _meta of the mappings for a new index, along with
+// the time it was created, if set. This records which mapping the index was created with, beyond the version in its name.
+// The prefix is always set as the type, so that the index is recognized as the application's even if the mapping (e.g.,
+// one built from a struct) doesn't set it.
+func indexMappings(registry MappingsRegistry, documentKind string, mapping *VersionedMapping, createdAt time.Time) map[string]interface{} {
+	mappings := map[string]interface{}{}
+	for k, v := range mapping.Mappings {
+		mappings[k] = v
+	}
+
+	meta := map[string]interface{}{}
+	if existing, ok := mappings["_meta"].(map[string]interface{}); ok {
+		for k, v := range existing {
+			meta[k] = v
+		}
+	}
+	meta["type"] = registry.IndexPrefix()
+	meta["version"] = mapping.Version
+	meta["checksum"] = MappingChecksum(mapping)
+	meta["documentKind"] = documentKind
+	if !createdAt.IsZero() {
+		meta["createdAt"] = createdAt.UTC().Format(time.RFC3339)
+	}
+	mappings["_meta"] = meta
+
+	return mappings
+}
+
+// MappingChecksum hashes the mappings and static settings of a document kind. Dynamic settings are left out, since
+// they can be changed without a new version.
+func MappingChecksum(mapping *VersionedMapping) string {
+	settings := map[string]interface{}{}
+	flattenSettings("", mapping.Settings, settings)
+	for setting := range settings {
+		if IsDynamicSetting(setting) {
+			delete(settings, setting)
+		}
+	}
+
+	// maps are marshalled with sorted keys, so the result doesn't depend on the order of the mapping file
+	content, _ := json.Marshal(map[string]interface{}{
+		"mappings": mapping.Mappings,
+		"settings": settings,
+	})
+	checksum := sha256.Sum256(content)
+
+	return hex.EncodeToString(checksum[:])
+}
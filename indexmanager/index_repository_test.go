@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/elastic/go-elasticsearch/v7"
 	"github.com/elastic/go-elasticsearch/v7/esapi"
@@ -53,6 +54,7 @@ var _ = Describe("IndexRepository", func() {
 		var (
 			documentKind string
 			aliasName    string
+			prefix       string
 
 			actualError     error
 			expectedMapping *VersionedMapping
@@ -61,9 +63,11 @@ var _ = Describe("IndexRepository", func() {
 		BeforeEach(func() {
 			documentKind = fake.Word()
 			aliasName = fake.Word()
+			prefix = fake.Word()
 
 			expectedMapping = createRandomMapping()
 			registry.MappingReturns(expectedMapping)
+			registry.IndexPrefixReturns(prefix)
 		})
 
 		JustBeforeEach(func() {
@@ -98,13 +102,28 @@ var _ = Describe("IndexRepository", func() {
 				readRequestBody(mockTransport.receivedHttpRequests[1], &actualPayload)
 
 				Expect(actualPayload).To(MatchAllKeys(Keys{
-					"mappings": Equal(expectedMapping.Mappings),
+					"mappings": matchIndexMappings(expectedMapping, documentKind),
 					"aliases": MatchAllKeys(Keys{
 						aliasName: BeEmpty(),
 					}),
 				}))
 			})
 
+			It("should record the mapping in the _meta", func() {
+				actualPayload := map[string]interface{}{}
+
+				readRequestBody(mockTransport.receivedHttpRequests[1], &actualPayload)
+
+				meta := actualPayload["mappings"].(map[string]interface{})["_meta"].(map[string]interface{})
+				Expect(meta).To(MatchAllKeys(Keys{
+					"type":         Equal(prefix),
+					"version":      Equal(expectedMapping.Version),
+					"checksum":     Equal(MappingChecksum(expectedMapping)),
+					"documentKind": Equal(documentKind),
+					"createdAt":    WithTransform(parseTime, BeTemporally("~", time.Now(), time.Minute)),
+				}))
+			})
+
 			When("the index mapping includes settings", func() {
 				BeforeEach(func() {
 					expectedMapping.Settings = map[string]interface{}{
@@ -118,7 +137,7 @@ var _ = Describe("IndexRepository", func() {
 					readRequestBody(mockTransport.receivedHttpRequests[1], &actualPayload)
 
 					Expect(actualPayload).To(MatchAllKeys(Keys{
-						"mappings": Equal(expectedMapping.Mappings),
+						"mappings": matchIndexMappings(expectedMapping, documentKind),
 						"aliases": MatchAllKeys(Keys{
 							aliasName: BeEmpty(),
 						}),
@@ -126,6 +145,51 @@ var _ = Describe("IndexRepository", func() {
 					}))
 				})
 			})
+
+			When("the document kind has an ILM policy", func() {
+				var policyName string
+
+				BeforeEach(func() {
+					policyName = fake.Word()
+					expectedMapping.ILMPolicy = fake.Word()
+					expectedMapping.Settings = map[string]interface{}{
+						"foo": "bar",
+					}
+					registry.ResourceNameReturns(policyName)
+				})
+
+				It("should attach the policy in the settings", func() {
+					actualPayload := map[string]interface{}{}
+
+					readRequestBody(mockTransport.receivedHttpRequests[1], &actualPayload)
+
+					Expect(actualPayload["settings"]).To(Equal(map[string]interface{}{
+						"foo":                  "bar",
+						"index.lifecycle.name": policyName,
+					}))
+					Expect(registry.ResourceNameArgsForCall(0)).To(Equal(expectedMapping.ILMPolicy))
+				})
+			})
+
+			When("the document kind has a default pipeline", func() {
+				var pipelineName string
+
+				BeforeEach(func() {
+					pipelineName = fake.Word()
+					expectedMapping.DefaultPipeline = fake.Word()
+					registry.ResourceNameReturns(pipelineName)
+				})
+
+				It("should set the default pipeline in the settings", func() {
+					actualPayload := map[string]interface{}{}
+
+					readRequestBody(mockTransport.receivedHttpRequests[1], &actualPayload)
+
+					Expect(actualPayload["settings"]).To(Equal(map[string]interface{}{
+						"index.default_pipeline": pipelineName,
+					}))
+				})
+			})
 		})
 
 		When("an unexpected status code while checking if the index exists", func() {
@@ -313,4 +377,40 @@ var _ = Describe("IndexRepository", func() {
 			})
 		})
 	})
+
+	Context("MappingChecksum", func() {
+		var mapping *VersionedMapping
+
+		BeforeEach(func() {
+			mapping = &VersionedMapping{
+				Version:  "v1",
+				Mappings: map[string]interface{}{"dynamic": "strict"},
+				Settings: map[string]interface{}{
+					"number_of_shards":   1,
+					"number_of_replicas": 1,
+				},
+			}
+		})
+
+		It("should change when the mappings change", func() {
+			checksum := MappingChecksum(mapping)
+			mapping.Mappings["dynamic"] = "false"
+
+			Expect(MappingChecksum(mapping)).NotTo(Equal(checksum))
+		})
+
+		It("should change when a static setting changes", func() {
+			checksum := MappingChecksum(mapping)
+			mapping.Settings["number_of_shards"] = 2
+
+			Expect(MappingChecksum(mapping)).NotTo(Equal(checksum))
+		})
+
+		It("should not change when a dynamic setting changes", func() {
+			checksum := MappingChecksum(mapping)
+			mapping.Settings["number_of_replicas"] = 2
+
+			Expect(MappingChecksum(mapping)).To(Equal(checksum))
+		})
+	})
 })
@@ -14,11 +14,15 @@
 
 package internal
 
+import "encoding/json"
+
 // Elasticsearch
 const (
 	ElasticsearchAllIndices            = "_all"
 	ElasticsearchTaskIndex             = ".tasks"
+	ElasticsearchReindexAction         = "indices:data/write/reindex"
 	ElasticsearchResourceAlreadyExists = "resource_already_exists_exception"
+	ElasticsearchSnapshotSuccess       = "SUCCESS"
 )
 
 // Elasticsearch 400 response
@@ -31,7 +35,17 @@ type EsError struct {
 }
 
 type EsIndex struct {
-	Mappings *EsMappings `json:"mappings"`
+	Aliases  map[string]EsAlias `json:"aliases"`
+	Mappings *EsMappings        `json:"mappings"`
+	Settings *EsSettingsIndex   `json:"settings"`
+}
+
+type EsAlias struct {
+	Filter        map[string]interface{} `json:"filter,omitempty"`
+	IndexRouting  string                 `json:"index_routing,omitempty"`
+	SearchRouting string                 `json:"search_routing,omitempty"`
+	IsWriteIndex  *bool                  `json:"is_write_index,omitempty"`
+	IsHidden      *bool                  `json:"is_hidden,omitempty"`
 }
 
 type EsMappings struct {
@@ -39,7 +53,11 @@ type EsMappings struct {
 }
 
 type EsMeta struct {
-	Type string `json:"type,omitempty"`
+	Type         string `json:"type,omitempty"`
+	Version      string `json:"version,omitempty"`
+	DocumentKind string `json:"documentKind,omitempty"`
+	Checksum     string `json:"checksum,omitempty"`
+	CreatedAt    string `json:"createdAt,omitempty"`
 }
 
 // Elasticsearch /$INDEX/block/_write response
@@ -82,15 +100,74 @@ type EsTask struct {
 	Completed bool `json:"completed"`
 }
 
+// .tasks documents
+type EsTaskDocument struct {
+	Completed bool                `json:"completed"`
+	Task      *EsTaskDocumentInfo `json:"task"`
+}
+
+type EsTaskDocumentInfo struct {
+	Action      string `json:"action"`
+	Description string `json:"description"`
+}
+
+// Elasticsearch /_search response
+type EsSearchResponse struct {
+	PitID string        `json:"pit_id,omitempty"`
+	Hits  *EsSearchHits `json:"hits"`
+}
+
+type EsSearchHits struct {
+	Hits []*EsSearchHit `json:"hits"`
+}
+
+type EsSearchHit struct {
+	ID     string          `json:"_id"`
+	Source json.RawMessage `json:"_source"`
+	Sort   []interface{}   `json:"sort,omitempty"`
+}
+
+// Elasticsearch /$INDEX/_pit response, and /_pit request
+type EsPointInTime struct {
+	ID string `json:"id"`
+}
+
+// Elasticsearch /_bulk response
+type EsBulkResponse struct {
+	Errors bool                           `json:"errors"`
+	Items  []map[string]*EsBulkItemResult `json:"items"`
+}
+
+type EsBulkItemResult struct {
+	ID     string           `json:"_id"`
+	Status int              `json:"status"`
+	Error  *EsBulkItemError `json:"error,omitempty"`
+}
+
+type EsBulkItemError struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
 // Elasticsearch /_aliases request
 type EsActions struct {
-	Add    *EsIndexAlias `json:"add,omitempty"`
-	Remove *EsIndexAlias `json:"remove,omitempty"`
+	Add         *EsIndexAlias `json:"add,omitempty"`
+	Remove      *EsIndexAlias `json:"remove,omitempty"`
+	RemoveIndex *EsIndexRef   `json:"remove_index,omitempty"`
 }
 
-type EsIndexAlias struct {
+type EsIndexRef struct {
 	Index string `json:"index"`
-	Alias string `json:"alias"`
+}
+
+type EsIndexAlias struct {
+	Index         string                 `json:"index"`
+	Alias         string                 `json:"alias"`
+	Filter        map[string]interface{} `json:"filter,omitempty"`
+	IndexRouting  string                 `json:"index_routing,omitempty"`
+	SearchRouting string                 `json:"search_routing,omitempty"`
+	IsWriteIndex  *bool                  `json:"is_write_index,omitempty"`
+	IsHidden      *bool                  `json:"is_hidden,omitempty"`
 }
 
 type EsIndexAliasRequest struct {
@@ -106,6 +183,96 @@ type EsReindex struct {
 }
 
 type EsReindexFields struct {
-	Index  string `json:"index"`
-	OpType string `json:"op_type,omitempty"`
+	Index  string                 `json:"index"`
+	OpType string                 `json:"op_type,omitempty"`
+	Query  map[string]interface{} `json:"query,omitempty"`
+	Size   int                    `json:"size,omitempty"`
+}
+
+// Elasticsearch /$ALIAS/_rollover request and response
+type EsRolloverRequest struct {
+	Conditions *EsRolloverConditions  `json:"conditions,omitempty"`
+	Mappings   map[string]interface{} `json:"mappings,omitempty"`
+	Settings   map[string]interface{} `json:"settings,omitempty"`
+}
+
+type EsRolloverConditions struct {
+	MaxAge  string `json:"max_age,omitempty"`
+	MaxSize string `json:"max_size,omitempty"`
+	MaxDocs int    `json:"max_docs,omitempty"`
+}
+
+type EsRolloverResponse struct {
+	OldIndex   string `json:"old_index"`
+	NewIndex   string `json:"new_index"`
+	RolledOver bool   `json:"rolled_over"`
+}
+
+// Elasticsearch /_index_template/$NAME request
+type EsIndexTemplate struct {
+	IndexPatterns []string              `json:"index_patterns"`
+	Priority      int                   `json:"priority"`
+	DataStream    *EsDataStreamTemplate `json:"data_stream,omitempty"`
+	Template      *EsIndexTemplateBody  `json:"template"`
+}
+
+type EsDataStreamTemplate struct{}
+
+type EsIndexTemplateBody struct {
+	Mappings map[string]interface{} `json:"mappings,omitempty"`
+	Settings map[string]interface{} `json:"settings,omitempty"`
+}
+
+// Elasticsearch GET /_index_template/$NAME response
+type EsIndexTemplateResponse struct {
+	IndexTemplates []*EsNamedIndexTemplate `json:"index_templates"`
+}
+
+type EsNamedIndexTemplate struct {
+	Name          string           `json:"name"`
+	IndexTemplate *EsIndexTemplate `json:"index_template"`
+}
+
+// Elasticsearch /_data_stream response
+type EsDataStreamResponse struct {
+	DataStreams []*EsDataStream `json:"data_streams"`
+}
+
+type EsDataStream struct {
+	Name     string               `json:"name"`
+	Template string               `json:"template"`
+	Indices  []*EsDataStreamIndex `json:"indices"`
+}
+
+type EsDataStreamIndex struct {
+	IndexName string `json:"index_name"`
+}
+
+// Elasticsearch /_snapshot/$REPOSITORY/$SNAPSHOT request
+type EsSnapshotRequest struct {
+	Indices            string `json:"indices"`
+	IncludeGlobalState bool   `json:"include_global_state"`
+}
+
+// Elasticsearch /_snapshot/$REPOSITORY/$SNAPSHOT response, when waiting for completion
+type EsSnapshotResponse struct {
+	Snapshot *EsSnapshot `json:"snapshot"`
+}
+
+type EsSnapshot struct {
+	Snapshot string            `json:"snapshot"`
+	State    string            `json:"state"`
+	Shards   *EsSnapshotShards `json:"shards"`
+}
+
+type EsSnapshotShards struct {
+	Total  int `json:"total"`
+	Failed int `json:"failed"`
+}
+
+// Elasticsearch /_snapshot/$REPOSITORY/$SNAPSHOT/_restore request
+type EsRestoreRequest struct {
+	Indices            string `json:"indices"`
+	IncludeGlobalState bool   `json:"include_global_state"`
+	IncludeAliases     bool   `json:"include_aliases"`
 }
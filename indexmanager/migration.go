@@ -18,10 +18,11 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"strings"
+	"sort"
 	"time"
 
 	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
 	. "github.com/rode/es-index-manager/indexmanager/internal"
 	"go.uber.org/zap"
 )
@@ -39,6 +40,20 @@ type migrator struct {
 type Migrator interface {
 	GetMigrations(ctx context.Context) ([]*Migration, error)
 	Migrate(ctx context.Context, migration *Migration) error
+	// Rethrottle changes the requests per second of a running reindex task. Use -1 to disable throttling.
+	Rethrottle(ctx context.Context, taskId string, requestsPerSecond int) error
+	Adopter
+	// RestoreSnapshot restores the source index of a migration from the snapshot taken before it ran, and moves the
+	// alias back to it. The target index is left in place. If the source index still exists, it's only replaced when
+	// RestoreOptions.Overwrite is set. Only available when MigrationConfig.Snapshot is set, and not for time series or
+	// data streams.
+	RestoreSnapshot(ctx context.Context, migration *Migration, options *RestoreOptions) error
+}
+
+type RestoreOptions struct {
+	// Overwrite confirms that the source index should be deleted and replaced with the copy in the snapshot, if it
+	// still exists. Otherwise, the restore fails.
+	Overwrite bool
 }
 
 func NewMigrator(
@@ -59,50 +74,359 @@ func NewMigrator(
 	}
 }
 
+// managedIndex is an index that belongs to the application and has a known document kind.
+type managedIndex struct {
+	name         string
+	parts        *IndexName
+	aliases      map[string]EsAlias
+	writeBlocked bool
+	// checksum is from the index's _meta, and is empty for indices created before it was recorded
+	checksum string
+}
+
+func (i *managedIndex) hasAlias(alias string) bool {
+	_, ok := i.aliases[alias]
+
+	return ok
+}
+
+func (i *managedIndex) isWriteIndex(alias string) bool {
+	a, ok := i.aliases[alias]
+
+	return ok && a.IsWriteIndex != nil && *a.IsWriteIndex
+}
+
 func (m *migrator) GetMigrations(ctx context.Context) ([]*Migration, error) {
 	log := m.logger.Named("GetMigrations")
-	res, err := m.client.Indices.Get([]string{ElasticsearchAllIndices}, m.client.Indices.Get.WithContext(ctx))
-	if err := getErrorFromESResponse(res, err); err != nil {
+	indices, err := m.managedIndices(ctx, log)
+	if err != nil {
 		return nil, err
 	}
 
-	allIndices := map[string]EsIndex{}
+	for _, index := range indices {
+		if err := m.verifyChecksum(log, index.name, index.parts.DocumentKind, index.parts.Version, index.checksum); err != nil {
+			return nil, err
+		}
+	}
+
+	var migrations []*Migration
+	for _, group := range groupIndices(indices) {
+		migration, err := m.planMigration(log, group)
+		if err != nil {
+			return nil, err
+		}
 
-	if err := decodeResponse(res.Body, &allIndices); err != nil {
+		if migration != nil {
+			migrations = append(migrations, migration)
+		}
+	}
+
+	dataStreamMigrations, err := m.dataStreamMigrations(ctx, log)
+	if err != nil {
 		return nil, err
 	}
+	migrations = append(migrations, dataStreamMigrations...)
 
-	var migrations []*Migration
-	for indexName, indexValue := range allIndices {
-		meta := indexValue.Mappings.Meta
-		if !(strings.HasPrefix(indexName, m.config.IndexPrefix) && meta != nil && meta.Type == m.config.IndexPrefix) {
-			continue
+	// the indices are returned as a map, so sort the migrations to make runs reproducible
+	sort.Slice(migrations, func(i, j int) bool {
+		if migrations[i].DocumentKind != migrations[j].DocumentKind {
+			return migrations[i].DocumentKind < migrations[j].DocumentKind
 		}
 
+		return migrations[i].SourceIndex < migrations[j].SourceIndex
+	})
+
+	return migrations, nil
+}
+
+// managedIndices returns the indices that belong to the application, skipping any whose document kind is unknown.
+func (m *migrator) managedIndices(ctx context.Context, log *zap.Logger) ([]*managedIndex, error) {
+	applicationIndices, err := getApplicationIndices(ctx, m.client, m.config.IndexPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var indices []*managedIndex
+	for indexName, indexValue := range applicationIndices {
 		indexParts := m.registry.ParseIndexName(indexName)
 		if indexParts == nil {
 			log.Warn("Discovered index matching criteria, but wasn't able to determine document kind.", zap.String("index", indexName))
 			continue
 		}
 
-		currentVersion := m.registry.Version(indexParts.DocumentKind)
+		indices = append(indices, newManagedIndex(indexName, indexParts, indexValue))
+	}
+
+	return indices, nil
+}
+
+// verifyChecksum compares the checksum an index was created with to the current mapping, if they have the same version.
+// A difference means the mapping was changed without bumping the version, so the index won't be migrated.
+func (m *migrator) verifyChecksum(log *zap.Logger, indexName, documentKind, version, checksum string) error {
+	mapping := m.registry.Mapping(documentKind)
+	if checksum == "" || mapping == nil || mapping.Version != version {
+		return nil
+	}
+
+	if checksum == MappingChecksum(mapping) {
+		return nil
+	}
+
+	if m.config.Migration.FailOnChecksumMismatch {
+		return fmt.Errorf("the mapping for document kind %s changed without a version bump (index %s was created with different content at version %s)", documentKind, indexName, version)
+	}
+
+	log.Error("The mapping changed without a version bump, so the index won't be migrated. Bump the version in the mapping file.",
+		zap.String("index", indexName),
+		zap.String("documentKind", documentKind),
+		zap.String("version", version))
+
+	return nil
+}
+
+func newManagedIndex(indexName string, indexParts *IndexName, indexValue EsIndex) *managedIndex {
+	settings := indexValue.Settings
+	checksum := ""
+	if indexValue.Mappings != nil && indexValue.Mappings.Meta != nil {
+		checksum = indexValue.Mappings.Meta.Checksum
+	}
+
+	return &managedIndex{
+		name:         indexName,
+		parts:        indexParts,
+		aliases:      indexValue.Aliases,
+		writeBlocked: settings != nil && settings.Index != nil && settings.Index.Blocks != nil && settings.Index.Blocks.Write == "true",
+		checksum:     checksum,
+	}
+}
+
+// groupIndices collects the indices that hold the same logical data (i.e., document kind and inner name),
+// which may exist at several versions at once if a migration was interrupted.
+func groupIndices(indices []*managedIndex) [][]*managedIndex {
+	groups := map[string][]*managedIndex{}
+	var keys []string
+	for _, index := range indices {
+		key := index.parts.DocumentKind + "/" + index.parts.Inner
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], index)
+	}
+	sort.Strings(keys)
+
+	var result [][]*managedIndex
+	for _, key := range keys {
+		group := groups[key]
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].name < group[j].name
+		})
+		result = append(result, group)
+	}
+
+	return result
+}
+
+// planMigration decides what, if anything, should be migrated for a group of indices with the same document kind and
+// inner name. If an index already exists at the current version, the alias is used to tell whether the earlier
+// migration finished, and whether the existing target can be reused.
+func (m *migrator) planMigration(log *zap.Logger, group []*managedIndex) (*Migration, error) {
+	documentKind := group[0].parts.DocumentKind
+	inner := group[0].parts.Inner
+
+	if currentKind := m.registry.CurrentDocumentKind(documentKind); currentKind != "" && currentKind != documentKind {
+		return m.planRename(log, group, currentKind), nil
+	}
+
+	mapping, currentVersion, targetIndex := m.currentMapping(documentKind, inner)
+	if mapping != nil && mapping.TimeSeries != nil {
+		return m.planTimeSeriesMigration(log, group, mapping)
+	}
+
+	var (
+		target  *managedIndex
+		sources []*managedIndex
+	)
+	for _, index := range group {
+		comparison, err := CompareVersions(index.parts.Version, currentVersion)
+		if err != nil {
+			return nil, fmt.Errorf("unable to determine if index %s needs to be migrated: %s", index.name, err)
+		}
 
-		if indexParts.Version == currentVersion {
+		switch {
+		case comparison == 0:
+			target = index
+		case comparison > 0 && !m.config.Migration.AllowDowngrade:
+			log.Warn("Index is newer than the current mapping version, refusing to downgrade",
+				zap.String("index", index.name),
+				zap.String("indexVersion", index.parts.Version),
+				zap.String("currentVersion", currentVersion))
+			return nil, nil
+		default:
+			sources = append(sources, index)
+		}
+	}
+
+	if len(sources) == 0 {
+		return nil, nil
+	}
+
+	alias := m.registry.AliasName(documentKind, inner)
+	source := chooseSource(sources, alias)
+	for _, index := range sources {
+		if index != source {
+			log.Warn("Skipping index, another index with the same document kind will be migrated instead",
+				zap.String("index", index.name),
+				zap.String("migrating", source.name))
+		}
+	}
+
+	migration := &Migration{
+		SourceIndex:  source.name,
+		TargetIndex:  targetIndex,
+		DocumentKind: documentKind,
+		Alias:        alias,
+		DependsOn:    m.dependencies(documentKind),
+		Mapping:      mapping,
+	}
+
+	if target == nil {
+		return migration, nil
+	}
+
+	switch {
+	case target.hasAlias(alias) && !source.hasAlias(alias):
+		log.Warn("Index was already migrated, but wasn't deleted", zap.String("index", source.name), zap.String("target", target.name))
+		return nil, nil
+	case target.isWriteIndex(alias) || source.writeBlocked:
+		// the target either holds writes that can't be lost, or only contains documents copied from the (unchanged) source
+		migration.Recovery = MigrationRecoveryResume
+	default:
+		migration.Recovery = MigrationRecoveryRecreateTarget
+	}
+
+	log.Info("Found existing target index", zap.String("target", target.name), zap.String("recovery", string(migration.Recovery)))
+
+	return migration, nil
+}
+
+// planRename creates a migration from an index for a document kind that was renamed (or merged into another kind),
+// into the index for the current document kind. The version of the source index doesn't matter, since the mapping
+// it was created with is no longer in the registry.
+func (m *migrator) planRename(log *zap.Logger, group []*managedIndex, currentKind string) *Migration {
+	documentKind := group[0].parts.DocumentKind
+	inner := group[0].parts.Inner
+	previousAlias := m.registry.AliasName(documentKind, inner)
+	source := chooseSource(group, previousAlias)
+
+	for _, index := range group {
+		if index != source {
+			log.Warn("Skipping index, another index with the same document kind will be migrated instead",
+				zap.String("index", index.name),
+				zap.String("migrating", source.name))
+		}
+	}
+
+	log.Info("Found index for a renamed document kind",
+		zap.String("index", source.name),
+		zap.String("previousKind", documentKind),
+		zap.String("currentKind", currentKind))
+
+	mapping := m.registry.Mapping(currentKind)
+
+	return &Migration{
+		Alias:         m.registry.AliasName(currentKind, inner),
+		PreviousAlias: previousAlias,
+		SourceIndex:   source.name,
+		TargetIndex:   m.indexName(mapping, currentKind, inner),
+		DocumentKind:  currentKind,
+		DependsOn:     m.dependencies(currentKind),
+		Mapping:       mapping,
+	}
+}
+
+// currentMapping reads the mapping for the document kind once, and names the index for its version, so that a
+// migration isn't planned from two different versions if the mappings are reloaded in the meantime.
+func (m *migrator) currentMapping(documentKind, inner string) (*VersionedMapping, string, string) {
+	mapping := m.registry.Mapping(documentKind)
+	if mapping == nil {
+		return nil, m.registry.Version(documentKind), m.registry.IndexName(documentKind, inner)
+	}
+
+	return mapping, mapping.Version, m.indexName(mapping, documentKind, inner)
+}
+
+// indexName names the index for the document kind at the mapping's version. Other implementations of the
+// MappingsRegistry can only name the index for their current version.
+func (m *migrator) indexName(mapping *VersionedMapping, documentKind, inner string) string {
+	if registry, ok := m.registry.(*mappingsRegistry); ok && mapping != nil {
+		return registry.versionedIndexName(mapping.Version, documentKind, inner)
+	}
+
+	return m.registry.IndexName(documentKind, inner)
+}
+
+// createTarget creates an index for the migration from the mapping it was planned with. Other implementations of the
+// IndexRepository create it from the current mapping.
+func (m *migrator) createTarget(ctx context.Context, migration *Migration, indexName, aliasName string) error {
+	if repo, ok := m.repo.(*indexRepository); ok && migration.Mapping != nil {
+		_, err := repo.createWithMapping(ctx, indexName, aliasName, migration.DocumentKind, migration.Mapping)
+		return err
+	}
+
+	return m.repo.CreateIndex(ctx, indexName, aliasName, migration.DocumentKind)
+}
+
+// chooseSource picks the index the alias points to, or the newest index if the alias doesn't point to any of them.
+func chooseSource(sources []*managedIndex, alias string) *managedIndex {
+	candidates := sources
+	var aliased []*managedIndex
+	for _, index := range sources {
+		if index.hasAlias(alias) {
+			aliased = append(aliased, index)
+		}
+	}
+
+	if len(aliased) > 0 {
+		candidates = aliased
+	}
+
+	newest := candidates[0]
+	for _, index := range candidates[1:] {
+		if comparison, _ := CompareVersions(index.parts.Version, newest.parts.Version); comparison > 0 {
+			newest = index
+		}
+	}
+
+	return newest
+}
+
+// dependencies returns every document kind that the given kind depends on, directly or transitively.
+func (m *migrator) dependencies(documentKind string) []string {
+	var dependencies []string
+	seen := map[string]bool{documentKind: true}
+	queue := []string{documentKind}
+
+	for len(queue) > 0 {
+		mapping := m.registry.Mapping(queue[0])
+		queue = queue[1:]
+		if mapping == nil {
 			continue
 		}
 
-		alias := m.registry.AliasName(indexParts.DocumentKind, indexParts.Inner)
-		targetIndex := m.registry.IndexName(indexParts.DocumentKind, indexParts.Inner)
-		migration := &Migration{
-			SourceIndex:  indexName,
-			TargetIndex:  targetIndex,
-			DocumentKind: indexParts.DocumentKind,
-			Alias:        alias,
+		for _, dependency := range mapping.DependsOn {
+			if seen[dependency] {
+				continue
+			}
+
+			seen[dependency] = true
+			dependencies = append(dependencies, dependency)
+			queue = append(queue, dependency)
 		}
-		migrations = append(migrations, migration)
 	}
 
-	return migrations, nil
+	sort.Strings(dependencies)
+
+	return dependencies
 }
 
 func (m *migrator) Migrate(ctx context.Context, migration *Migration) error {
@@ -112,21 +436,61 @@ func (m *migrator) Migrate(ctx context.Context, migration *Migration) error {
 
 	log.Info("Starting migration")
 
-	if err := m.blockWritesOnIndex(ctx, log, migration.SourceIndex); err != nil {
-		return err
+	if migration.Mapping == nil {
+		migration.Mapping = m.registry.Mapping(migration.DocumentKind)
+	}
+	mapping := migration.Mapping
+	limited := m.reindexConfig(migration.DocumentKind).MaxDocs != 0
+	if limited && mapping != nil && (mapping.DataStream != nil || mapping.TimeSeries != nil) {
+		return fmt.Errorf("MaxDocs can't be used to migrate document kind %s, since its indices are replaced after reindexing", migration.DocumentKind)
 	}
 
-	err := m.repo.CreateIndex(ctx, migration.TargetIndex, migration.Alias, migration.DocumentKind)
-	if err != nil {
-		return fmt.Errorf("error creating target index: %s", err)
+	if m.config.Migration.Snapshot != nil {
+		if err := m.snapshot(ctx, log, migration, mapping); err != nil {
+			return err
+		}
 	}
 
-	if err := m.reindex(ctx, log, migration.SourceIndex, migration.TargetIndex); err != nil {
-		return err
+	if mapping != nil {
+		switch {
+		case mapping.DataStream != nil:
+			return m.migrateDataStream(ctx, log, migration, mapping)
+		case mapping.TimeSeries != nil:
+			return m.migrateTimeSeries(ctx, log, migration, mapping)
+		}
 	}
 
-	if err := m.swapAlias(ctx, log, migration.Alias, migration.SourceIndex, migration.TargetIndex); err != nil {
-		return err
+	if migration.Recovery == MigrationRecoveryRecreateTarget {
+		log.Info("Deleting target index left behind by an earlier migration")
+		if err := m.repo.DeleteIndex(ctx, migration.TargetIndex); err != nil {
+			return fmt.Errorf("error deleting existing target index: %s", err)
+		}
+	}
+
+	strategy := m.config.Migration.Strategy
+	if migration.PreviousAlias != "" {
+		// applications may still be writing through the previous alias, so it's not possible to redirect their writes
+		strategy = MigrationStrategyWriteBlock
+	}
+
+	switch strategy {
+	case MigrationStrategyWriteBlock, "":
+		if err := m.migrateWithWriteBlock(ctx, log, migration); err != nil {
+			return err
+		}
+	case MigrationStrategyDualWrite:
+		if err := m.migrateWithDualWrite(ctx, log, migration); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown migration strategy: %s", strategy)
+	}
+
+	if limited {
+		// documents past the limit were never copied, so the source index is left for Cleanup
+		log.Info("Keeping source index, since MaxDocs may have left documents behind")
+		log.Info("Migration complete")
+		return nil
 	}
 
 	log.Info("Deleting source index")
@@ -147,6 +511,126 @@ func (m *migrator) Migrate(ctx context.Context, migration *Migration) error {
 	return nil
 }
 
+func (m *migrator) migrateWithWriteBlock(ctx context.Context, log *zap.Logger, migration *Migration) error {
+	if err := m.blockWritesOnIndex(ctx, log, migration.SourceIndex); err != nil {
+		return err
+	}
+
+	err := m.createTarget(ctx, migration, migration.TargetIndex, migration.Alias)
+	if err != nil {
+		return fmt.Errorf("error creating target index: %s", err)
+	}
+
+	if err := m.reindex(ctx, log, migration, nil); err != nil {
+		return err
+	}
+
+	return m.swapAlias(ctx, log, migration)
+}
+
+func (m *migrator) migrateWithDualWrite(ctx context.Context, log *zap.Logger, migration *Migration) error {
+	// the alias is added to the target index when it becomes the write index
+	err := m.createTarget(ctx, migration, migration.TargetIndex, "")
+	if err != nil {
+		return fmt.Errorf("error creating target index: %s", err)
+	}
+
+	checkpoint := time.Now()
+	if err := m.moveWriteIndex(ctx, log, migration.Alias, migration.SourceIndex, migration.TargetIndex); err != nil {
+		return err
+	}
+
+	// the reindex only sees refreshed documents, so anything written to the source index in the last refresh interval
+	// before the write index moved would be left behind
+	if err := m.refreshSource(ctx, log, migration); err != nil {
+		return err
+	}
+
+	if err := m.reindex(ctx, log, migration, nil); err != nil {
+		return err
+	}
+
+	if m.config.Migration.CatchUpField != "" {
+		if err := m.catchUp(ctx, log, migration, checkpoint); err != nil {
+			return err
+		}
+	}
+
+	return m.swapAlias(ctx, log, migration)
+}
+
+func (m *migrator) moveWriteIndex(ctx context.Context, log *zap.Logger, alias, sourceIndex, targetIndex string) error {
+	log = log.With(zap.String("alias", alias))
+	isWriteIndex := true
+	isNotWriteIndex := false
+
+	aliasReq := &EsIndexAliasRequest{
+		Actions: []EsActions{
+			{
+				Add: &EsIndexAlias{
+					Index:        sourceIndex,
+					Alias:        alias,
+					IsWriteIndex: &isNotWriteIndex,
+				},
+			},
+			{
+				Add: &EsIndexAlias{
+					Index:        targetIndex,
+					Alias:        alias,
+					IsWriteIndex: &isWriteIndex,
+				},
+			},
+		},
+	}
+
+	aliasReqBody, _ := encodeRequest(aliasReq)
+	log.Info("Making the target index the write index for the alias")
+	res, err := m.client.Indices.UpdateAliases(
+		aliasReqBody,
+		m.client.Indices.UpdateAliases.WithContext(ctx),
+	)
+
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return fmt.Errorf("error occurred while moving the write index: %s", err)
+	}
+
+	return nil
+}
+
+// catchUp copies documents that were written to the source index after the checkpoint, like writes that resolved the
+// alias before the write index moved but landed after the first refresh. The source is refreshed again first. Since
+// the reindex uses the create op type, only documents missing from the target index are copied: documents already in
+// the target, whether copied earlier or written by the application, aren't overwritten.
+func (m *migrator) catchUp(ctx context.Context, log *zap.Logger, migration *Migration, checkpoint time.Time) error {
+	if err := m.refreshSource(ctx, log, migration); err != nil {
+		return err
+	}
+
+	query := map[string]interface{}{
+		"range": map[string]interface{}{
+			m.config.Migration.CatchUpField: map[string]interface{}{
+				"gte":    checkpoint.UnixMilli(),
+				"format": "epoch_millis",
+			},
+		},
+	}
+
+	return m.reindex(ctx, log.With(zap.Bool("catchUp", true)), migration, query)
+}
+
+func (m *migrator) refreshSource(ctx context.Context, log *zap.Logger, migration *Migration) error {
+	log.Info("Refreshing source index")
+	res, err := m.client.Indices.Refresh(
+		m.client.Indices.Refresh.WithContext(ctx),
+		m.client.Indices.Refresh.WithIndex(migration.SourceIndex),
+	)
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return fmt.Errorf("error refreshing source index: %s", err)
+	}
+
+	return nil
+}
+
 func (m *migrator) blockWritesOnIndex(ctx context.Context, log *zap.Logger, indexName string) error {
 	res, err := m.client.Indices.GetSettings(m.client.Indices.GetSettings.WithContext(ctx), m.client.Indices.GetSettings.WithIndex(indexName))
 	if err := getErrorFromESResponse(res, err); err != nil {
@@ -158,10 +642,14 @@ func (m *migrator) blockWritesOnIndex(ctx context.Context, log *zap.Logger, inde
 		return fmt.Errorf("error decoding settings response: %s", err)
 	}
 
-	blocks := settingsResponse[indexName].Settings.Index.Blocks
+	settings, ok := settingsResponse[indexName]
+	if !ok {
+		return fmt.Errorf("settings response didn't include index %s", indexName)
+	}
 
 	// index already has a write block in place
-	if blocks != nil && blocks.Write == "true" {
+	if settings.Settings != nil && settings.Settings.Index != nil &&
+		settings.Settings.Index.Blocks != nil && settings.Settings.Index.Blocks.Write == "true" {
 		return nil
 	}
 
@@ -184,18 +672,30 @@ func (m *migrator) blockWritesOnIndex(ctx context.Context, log *zap.Logger, inde
 	return nil
 }
 
-func (m *migrator) reindex(ctx context.Context, log *zap.Logger, sourceIndex, targetIndex string) error {
+func (m *migrator) reindex(ctx context.Context, log *zap.Logger, migration *Migration, query map[string]interface{}) error {
+	reindexConfig := m.reindexConfig(migration.DocumentKind)
 	reindexReq := &EsReindex{
 		Conflicts:   "proceed",
-		Source:      &EsReindexFields{Index: sourceIndex},
-		Destination: &EsReindexFields{Index: targetIndex, OpType: "create"},
+		Source:      &EsReindexFields{Index: migration.SourceIndex, Query: query, Size: reindexConfig.BatchSize},
+		Destination: &EsReindexFields{Index: migration.TargetIndex, OpType: "create"},
 	}
 	reindexBody, _ := encodeRequest(reindexReq)
-	log.Info("Starting reindex")
-	res, err := m.client.Reindex(
-		reindexBody,
+	opts := []func(*esapi.ReindexRequest){
 		m.client.Reindex.WithContext(ctx),
-		m.client.Reindex.WithWaitForCompletion(false))
+		m.client.Reindex.WithWaitForCompletion(false),
+	}
+	if reindexConfig.Slices != "" {
+		opts = append(opts, m.client.Reindex.WithSlices(reindexConfig.Slices))
+	}
+	if reindexConfig.RequestsPerSecond != 0 {
+		opts = append(opts, m.client.Reindex.WithRequestsPerSecond(reindexConfig.RequestsPerSecond))
+	}
+	if reindexConfig.MaxDocs != 0 {
+		opts = append(opts, m.client.Reindex.WithMaxDocs(reindexConfig.MaxDocs))
+	}
+
+	log.Info("Starting reindex")
+	res, err := m.client.Reindex(reindexBody, opts...)
 	if err := getErrorFromESResponse(res, err); err != nil {
 		return fmt.Errorf("error initiating reindex: %s", err)
 	}
@@ -206,6 +706,7 @@ func (m *migrator) reindex(ctx context.Context, log *zap.Logger, sourceIndex, ta
 	}
 	log.Info("Reindex started", zap.String("taskId", taskCreationResponse.Task))
 
+	requestsPerSecond := reindexConfig.RequestsPerSecond
 	reindexCompleted := false
 	for i := 0; i < m.config.Migration.PollAttempts; i++ {
 		log.Info("Polling task API", zap.String("taskId", taskCreationResponse.Task))
@@ -229,6 +730,7 @@ func (m *migrator) reindex(ctx context.Context, log *zap.Logger, sourceIndex, ta
 		}
 
 		log.Info("Task incomplete, waiting before polling again", zap.String("taskId", taskCreationResponse.Task))
+		requestsPerSecond = m.throttle(ctx, log, migration, taskCreationResponse.Task, requestsPerSecond)
 		m.sleep(m.config.Migration.PollInterval)
 	}
 
@@ -244,24 +746,104 @@ func (m *migrator) reindex(ctx context.Context, log *zap.Logger, sourceIndex, ta
 	return nil
 }
 
-func (m *migrator) swapAlias(ctx context.Context, log *zap.Logger, alias, sourceIndex, targetIndex string) error {
-	log = log.With(zap.String("alias", alias))
+func (m *migrator) Rethrottle(ctx context.Context, taskId string, requestsPerSecond int) error {
+	res, err := m.client.ReindexRethrottle(taskId, &requestsPerSecond, m.client.ReindexRethrottle.WithContext(ctx))
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return fmt.Errorf("error rethrottling reindex task %s: %s", taskId, err)
+	}
 
-	aliasReq := &EsIndexAliasRequest{
-		Actions: []EsActions{
-			{
-				Remove: &EsIndexAlias{
-					Index: sourceIndex,
-					Alias: alias,
-				},
-			},
-			{
-				Add: &EsIndexAlias{
-					Index: targetIndex,
-					Alias: alias,
-				},
+	return nil
+}
+
+// throttle consults the configured ThrottleFunc and rethrottles the reindex task if the desired rate changed.
+// It returns the rate the task is running at.
+func (m *migrator) throttle(ctx context.Context, log *zap.Logger, migration *Migration, taskId string, current int) int {
+	if m.config.Migration.Throttle == nil {
+		return current
+	}
+
+	requestsPerSecond, rethrottle := m.config.Migration.Throttle(ctx, migration, taskId)
+	if !rethrottle || requestsPerSecond == current {
+		return current
+	}
+
+	log.Info("Rethrottling reindex", zap.String("taskId", taskId), zap.Int("requestsPerSecond", requestsPerSecond))
+	if err := m.Rethrottle(ctx, taskId, requestsPerSecond); err != nil {
+		log.Warn("Error rethrottling reindex", zap.Error(err))
+		return current
+	}
+
+	return requestsPerSecond
+}
+
+// reindexConfig merges the reindex configuration for the document kind over the defaults.
+func (m *migrator) reindexConfig(documentKind string) *ReindexConfig {
+	merged := ReindexConfig{}
+	if m.config.Migration.Reindex != nil {
+		merged = *m.config.Migration.Reindex
+	}
+
+	override, ok := m.config.Migration.DocumentKindReindex[documentKind]
+	if !ok || override == nil {
+		return &merged
+	}
+
+	if override.Slices != "" {
+		merged.Slices = override.Slices
+	}
+	if override.RequestsPerSecond != 0 {
+		merged.RequestsPerSecond = override.RequestsPerSecond
+	}
+	if override.BatchSize != 0 {
+		merged.BatchSize = override.BatchSize
+	}
+	if override.MaxDocs != 0 {
+		merged.MaxDocs = override.MaxDocs
+	}
+
+	return &merged
+}
+
+func (m *migrator) swapAlias(ctx context.Context, log *zap.Logger, migration *Migration) error {
+	log = log.With(zap.String("alias", migration.Alias))
+
+	sourceAlias := migration.Alias
+	removeSourceAlias := true
+	if migration.PreviousAlias != "" {
+		sourceAlias = migration.PreviousAlias
+
+		// a rename that was interrupted after the swap has nothing to remove, and Elasticsearch rejects the request
+		// if it tries to remove an alias that doesn't exist
+		existingAliases, err := m.getAliases(ctx, migration.SourceIndex)
+		if err != nil {
+			return err
+		}
+		_, removeSourceAlias = existingAliases[sourceAlias]
+	}
+
+	aliasReq := &EsIndexAliasRequest{}
+	if removeSourceAlias {
+		aliasReq.Actions = append(aliasReq.Actions, EsActions{
+			Remove: &EsIndexAlias{
+				Index: migration.SourceIndex,
+				Alias: sourceAlias,
 			},
+		})
+	}
+	aliasReq.Actions = append(aliasReq.Actions, EsActions{
+		Add: &EsIndexAlias{
+			Index: migration.TargetIndex,
+			Alias: migration.Alias,
 		},
+	})
+
+	if migration.PreviousAlias != "" && m.config.Migration.KeepPreviousAliases {
+		aliasReq.Actions = append(aliasReq.Actions, EsActions{
+			Add: &EsIndexAlias{
+				Index: migration.TargetIndex,
+				Alias: migration.PreviousAlias,
+			},
+		})
 	}
 
 	aliasReqBody, _ := encodeRequest(aliasReq)
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/elastic/go-elasticsearch/v7"
@@ -70,11 +71,11 @@ var _ = Describe("Migrator", func() {
 			Expect(duration).To(Equal(config.Migration.PollInterval))
 		}
 
-		expectedVersion = fake.Word()
+		expectedVersion = "v2"
 		documentKind = fake.Word()
 		expectedInnerName = fake.Word()
 
-		expectedSourceIndex = createIndexOrAliasName(expectedIndexPrefix, fake.Word(), expectedInnerName, documentKind)
+		expectedSourceIndex = createIndexOrAliasName(expectedIndexPrefix, "v1", expectedInnerName, documentKind)
 		expectedTargetIndex = createIndexOrAliasName(expectedIndexPrefix, expectedVersion, expectedInnerName, documentKind)
 		expectedAlias = createIndexOrAliasName(expectedIndexPrefix, expectedInnerName, documentKind)
 
@@ -106,7 +107,7 @@ var _ = Describe("Migrator", func() {
 			mockRegistry.ParseIndexNameReturns(&IndexName{
 				Inner:        expectedInnerName,
 				DocumentKind: documentKind,
-				Version:      fake.Word(),
+				Version:      "v1",
 			})
 
 			mockRegistry.VersionReturns(expectedVersion)
@@ -157,6 +158,69 @@ var _ = Describe("Migrator", func() {
 			})
 		})
 
+		When("the document kind has dependencies", func() {
+			BeforeEach(func() {
+				mockRegistry.MappingStub = func(kind string) *VersionedMapping {
+					switch kind {
+					case documentKind:
+						return &VersionedMapping{Version: expectedVersion, DependsOn: []string{"foo-dependency"}}
+					case "foo-dependency":
+						return &VersionedMapping{DependsOn: []string{"bar-dependency"}}
+					}
+
+					return &VersionedMapping{}
+				}
+			})
+
+			It("should include the direct and transitive dependencies", func() {
+				Expect(actualMigrations).To(HaveLen(1))
+				Expect(actualMigrations[0].DependsOn).To(Equal([]string{"bar-dependency", "foo-dependency"}))
+			})
+		})
+
+		When("there are multiple indices to migrate", func() {
+			var indexNames []string
+
+			BeforeEach(func() {
+				indices := map[string]interface{}{}
+				indexNames = []string{}
+				for i := 0; i < 10; i++ {
+					indexName := createIndexOrAliasName(expectedIndexPrefix, fake.Word(), fake.UUID(), fake.RandomString([]string{"a", "b"}))
+					indexNames = append(indexNames, indexName)
+					indices[indexName] = map[string]interface{}{
+						"mappings": map[string]interface{}{
+							"_meta": map[string]interface{}{
+								"type": config.IndexPrefix,
+							},
+						},
+					}
+				}
+				mockTransport.preparedHttpResponses[0].Body = createESBody(indices)
+
+				mockRegistry.ParseIndexNameStub = func(indexName string) *IndexName {
+					return &IndexName{
+						DocumentKind: indexName[len(indexName)-1:],
+						Version:      "v1",
+						Inner:        indexName,
+					}
+				}
+			})
+
+			It("should sort the migrations by document kind and index name", func() {
+				Expect(actualMigrations).To(HaveLen(len(indexNames)))
+
+				for i := 1; i < len(actualMigrations); i++ {
+					previous := actualMigrations[i-1]
+					current := actualMigrations[i]
+
+					Expect(previous.DocumentKind <= current.DocumentKind).To(BeTrue())
+					if previous.DocumentKind == current.DocumentKind {
+						Expect(previous.SourceIndex < current.SourceIndex).To(BeTrue())
+					}
+				}
+			})
+		})
+
 		When("the index is up to date", func() {
 			BeforeEach(func() {
 				expectedSourceIndex = createIndexOrAliasName(expectedIndexPrefix, expectedVersion, expectedInnerName, documentKind)
@@ -180,6 +244,274 @@ var _ = Describe("Migrator", func() {
 			It("should not return any migrations", func() {
 				Expect(actualMigrations).To(BeEmpty())
 			})
+
+			When("the index was created from different content at the same version", func() {
+				var mapping *VersionedMapping
+
+				BeforeEach(func() {
+					mapping = &VersionedMapping{
+						Version:  expectedVersion,
+						Mappings: map[string]interface{}{"dynamic": "strict"},
+					}
+					mockRegistry.MappingReturns(mapping)
+					mockTransport.preparedHttpResponses[0].Body = createESBody(map[string]interface{}{
+						expectedSourceIndex: map[string]interface{}{
+							"mappings": map[string]interface{}{
+								"_meta": map[string]interface{}{
+									"type":     config.IndexPrefix,
+									"version":  expectedVersion,
+									"checksum": fake.UUID(),
+								},
+							},
+						},
+					})
+				})
+
+				It("should only warn by default", func() {
+					Expect(actualError).NotTo(HaveOccurred())
+					Expect(actualMigrations).To(BeEmpty())
+				})
+
+				When("checksum mismatches should fail", func() {
+					BeforeEach(func() {
+						config.Migration.FailOnChecksumMismatch = true
+					})
+
+					It("should return an error", func() {
+						Expect(actualError).To(HaveOccurred())
+						Expect(actualError.Error()).To(ContainSubstring("changed without a version bump"))
+						Expect(actualError.Error()).To(ContainSubstring(expectedSourceIndex))
+					})
+
+					When("the checksum matches", func() {
+						BeforeEach(func() {
+							mockTransport.preparedHttpResponses[0].Body = createESBody(map[string]interface{}{
+								expectedSourceIndex: map[string]interface{}{
+									"mappings": map[string]interface{}{
+										"_meta": map[string]interface{}{
+											"type":     config.IndexPrefix,
+											"checksum": MappingChecksum(mapping),
+										},
+									},
+								},
+							})
+						})
+
+						It("should not return an error", func() {
+							Expect(actualError).NotTo(HaveOccurred())
+						})
+					})
+
+					When("the index was created before checksums were recorded", func() {
+						BeforeEach(func() {
+							mockTransport.preparedHttpResponses[0].Body = createESBody(map[string]interface{}{
+								expectedSourceIndex: managedIndexResponse(config.IndexPrefix, nil, false),
+							})
+						})
+
+						It("should not return an error", func() {
+							Expect(actualError).NotTo(HaveOccurred())
+						})
+					})
+				})
+			})
+		})
+
+		Context("multiple versions of the same index exist", func() {
+			var (
+				existingTargetIndex string
+				indices             map[string]interface{}
+				targetAliases       map[string]interface{}
+				sourceAliases       map[string]interface{}
+				sourceWriteBlocked  bool
+			)
+
+			BeforeEach(func() {
+				existingTargetIndex = createIndexOrAliasName(expectedIndexPrefix, expectedVersion, expectedInnerName, documentKind)
+				targetAliases = map[string]interface{}{}
+				sourceAliases = map[string]interface{}{
+					expectedAlias: map[string]interface{}{},
+				}
+				sourceWriteBlocked = false
+				indices = map[string]interface{}{}
+
+				mockRegistry.ParseIndexNameStub = func(indexName string) *IndexName {
+					return &IndexName{
+						Inner:        expectedInnerName,
+						DocumentKind: documentKind,
+						Version:      strings.Split(strings.TrimPrefix(indexName, expectedIndexPrefix+"-"), "-")[0],
+					}
+				}
+			})
+
+			JustBeforeEach(func() {
+				// GetMigrations has already run in the outer JustBeforeEach, so run it again with the prepared indices
+				indices[expectedSourceIndex] = managedIndexResponse(config.IndexPrefix, sourceAliases, sourceWriteBlocked)
+				indices[existingTargetIndex] = managedIndexResponse(config.IndexPrefix, targetAliases, false)
+				mockTransport.preparedHttpResponses = []*http.Response{
+					{
+						StatusCode: http.StatusOK,
+						Body:       createESBody(indices),
+					},
+				}
+
+				actualMigrations, actualError = migrator.GetMigrations(ctx)
+			})
+
+			When("the alias points to the source index and it has a write block", func() {
+				BeforeEach(func() {
+					sourceWriteBlocked = true
+					targetAliases[expectedAlias] = map[string]interface{}{}
+				})
+
+				It("should resume the migration", func() {
+					Expect(actualError).NotTo(HaveOccurred())
+					Expect(actualMigrations).To(HaveLen(1))
+					Expect(actualMigrations[0].SourceIndex).To(Equal(expectedSourceIndex))
+					Expect(actualMigrations[0].TargetIndex).To(Equal(expectedTargetIndex))
+					Expect(actualMigrations[0].Recovery).To(Equal(MigrationRecoveryResume))
+				})
+			})
+
+			When("the target index is the write index for the alias", func() {
+				BeforeEach(func() {
+					targetAliases[expectedAlias] = map[string]interface{}{
+						"is_write_index": true,
+					}
+				})
+
+				It("should resume the migration", func() {
+					Expect(actualMigrations).To(HaveLen(1))
+					Expect(actualMigrations[0].Recovery).To(Equal(MigrationRecoveryResume))
+				})
+			})
+
+			When("the source index may have changed since the target was populated", func() {
+				It("should recreate the target index", func() {
+					Expect(actualMigrations).To(HaveLen(1))
+					Expect(actualMigrations[0].Recovery).To(Equal(MigrationRecoveryRecreateTarget))
+				})
+			})
+
+			When("the alias already points to the target index", func() {
+				BeforeEach(func() {
+					sourceAliases = map[string]interface{}{}
+					targetAliases[expectedAlias] = map[string]interface{}{}
+				})
+
+				It("should not return any migrations", func() {
+					Expect(actualError).NotTo(HaveOccurred())
+					Expect(actualMigrations).To(BeEmpty())
+				})
+			})
+
+			When("there are several older versions", func() {
+				var olderIndex string
+
+				BeforeEach(func() {
+					olderIndex = createIndexOrAliasName(expectedIndexPrefix, "v1alpha1", expectedInnerName, documentKind)
+					indices[olderIndex] = managedIndexResponse(config.IndexPrefix, nil, false)
+					existingTargetIndex = olderIndex
+				})
+
+				It("should only migrate the index the alias points to", func() {
+					Expect(actualMigrations).To(HaveLen(1))
+					Expect(actualMigrations[0].SourceIndex).To(Equal(expectedSourceIndex))
+					Expect(actualMigrations[0].Recovery).To(BeEmpty())
+				})
+
+				When("the alias doesn't point to any of them", func() {
+					BeforeEach(func() {
+						sourceAliases = map[string]interface{}{}
+					})
+
+					It("should migrate the newest index", func() {
+						Expect(actualMigrations).To(HaveLen(1))
+						Expect(actualMigrations[0].SourceIndex).To(Equal(expectedSourceIndex))
+					})
+				})
+			})
+		})
+
+		When("the index is for a document kind that was renamed", func() {
+			var (
+				newDocumentKind string
+				previousAlias   string
+			)
+
+			BeforeEach(func() {
+				newDocumentKind = "new" + documentKind
+				previousAlias = createIndexOrAliasName(expectedIndexPrefix, expectedInnerName, documentKind)
+				expectedAlias = createIndexOrAliasName(expectedIndexPrefix, expectedInnerName, newDocumentKind)
+				expectedTargetIndex = createIndexOrAliasName(expectedIndexPrefix, expectedVersion, expectedInnerName, newDocumentKind)
+
+				mockRegistry.CurrentDocumentKindReturns(newDocumentKind)
+				mockRegistry.AliasNameStub = func(kind, inner string) string {
+					return createIndexOrAliasName(expectedIndexPrefix, inner, kind)
+				}
+				mockRegistry.IndexNameStub = func(kind, inner string) string {
+					return createIndexOrAliasName(expectedIndexPrefix, expectedVersion, inner, kind)
+				}
+			})
+
+			It("should migrate the index into the index for the new document kind", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(actualMigrations).To(HaveLen(1))
+
+				actualMigration := actualMigrations[0]
+				Expect(actualMigration.SourceIndex).To(Equal(expectedSourceIndex))
+				Expect(actualMigration.TargetIndex).To(Equal(expectedTargetIndex))
+				Expect(actualMigration.DocumentKind).To(Equal(newDocumentKind))
+				Expect(actualMigration.Alias).To(Equal(expectedAlias))
+				Expect(actualMigration.PreviousAlias).To(Equal(previousAlias))
+			})
+
+			It("should not compare versions across document kinds", func() {
+				Expect(mockRegistry.VersionCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the index is newer than the current version", func() {
+			BeforeEach(func() {
+				mockRegistry.ParseIndexNameReturns(&IndexName{
+					Inner:        expectedInnerName,
+					DocumentKind: documentKind,
+					Version:      "v3",
+				})
+			})
+
+			It("should refuse to downgrade the index", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(actualMigrations).To(BeEmpty())
+			})
+
+			When("downgrades are allowed", func() {
+				BeforeEach(func() {
+					config.Migration.AllowDowngrade = true
+				})
+
+				It("should return a migration", func() {
+					Expect(actualError).NotTo(HaveOccurred())
+					Expect(actualMigrations).To(HaveLen(1))
+					Expect(actualMigrations[0].TargetIndex).To(Equal(expectedTargetIndex))
+				})
+			})
+		})
+
+		When("the index version can't be parsed", func() {
+			BeforeEach(func() {
+				mockRegistry.ParseIndexNameReturns(&IndexName{
+					Inner:        expectedInnerName,
+					DocumentKind: documentKind,
+					Version:      fake.Word(),
+				})
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(actualError.Error()).To(ContainSubstring("unable to parse version"))
+				Expect(actualMigrations).To(BeNil())
+			})
 		})
 
 		When("no indices belong to the application", func() {
@@ -268,11 +600,15 @@ var _ = Describe("Migrator", func() {
 		var (
 			actualError error
 
-			taskId string
+			taskId        string
+			recovery      MigrationRecovery
+			previousAlias string
 		)
 
 		BeforeEach(func() {
 			taskId = fake.Word()
+			recovery = ""
+			previousAlias = ""
 			mockTransport.preparedHttpResponses = []*http.Response{
 				// get index settings
 				{
@@ -328,10 +664,12 @@ var _ = Describe("Migrator", func() {
 
 		JustBeforeEach(func() {
 			actualError = migrator.Migrate(ctx, &Migration{
-				Alias:        expectedAlias,
-				SourceIndex:  expectedSourceIndex,
-				TargetIndex:  expectedTargetIndex,
-				DocumentKind: documentKind,
+				Alias:         expectedAlias,
+				SourceIndex:   expectedSourceIndex,
+				TargetIndex:   expectedTargetIndex,
+				DocumentKind:  documentKind,
+				Recovery:      recovery,
+				PreviousAlias: previousAlias,
 			})
 		})
 
@@ -421,6 +759,487 @@ var _ = Describe("Migrator", func() {
 			})
 		})
 
+		When("reindex options are configured", func() {
+			BeforeEach(func() {
+				config.Migration.Reindex = &ReindexConfig{
+					Slices:            "auto",
+					RequestsPerSecond: 500,
+					BatchSize:         100,
+				}
+			})
+
+			It("should pass the options to the reindex request", func() {
+				actualBody := &EsReindex{}
+				readRequestBody(mockTransport.receivedHttpRequests[2], actualBody)
+				query := mockTransport.receivedHttpRequests[2].URL.Query()
+
+				Expect(query.Get("slices")).To(Equal("auto"))
+				Expect(query.Get("requests_per_second")).To(Equal("500"))
+				Expect(query.Has("max_docs")).To(BeFalse())
+				Expect(actualBody.Source.Size).To(Equal(100))
+			})
+
+			When("the document kind has overrides", func() {
+				BeforeEach(func() {
+					config.Migration.DocumentKindReindex = map[string]*ReindexConfig{
+						documentKind: {
+							Slices:  "5",
+							MaxDocs: 1000,
+						},
+					}
+				})
+
+				It("should use the overridden values", func() {
+					actualBody := &EsReindex{}
+					readRequestBody(mockTransport.receivedHttpRequests[2], actualBody)
+					query := mockTransport.receivedHttpRequests[2].URL.Query()
+
+					Expect(query.Get("slices")).To(Equal("5"))
+					Expect(query.Get("max_docs")).To(Equal("1000"))
+					Expect(query.Get("requests_per_second")).To(Equal("500"))
+					Expect(actualBody.Source.Size).To(Equal(100))
+				})
+
+				It("should keep the source index", func() {
+					Expect(actualError).NotTo(HaveOccurred())
+					Expect(mockTransport.receivedHttpRequests).To(HaveLen(6))
+				})
+
+				When("the document kind is a time series", func() {
+					BeforeEach(func() {
+						mockRegistry.MappingReturns(&VersionedMapping{TimeSeries: &TimeSeriesConfig{}})
+					})
+
+					It("should return an error without making any requests", func() {
+						Expect(actualError).To(HaveOccurred())
+						Expect(actualError.Error()).To(ContainSubstring("MaxDocs"))
+						Expect(mockTransport.receivedHttpRequests).To(BeEmpty())
+					})
+				})
+			})
+		})
+
+		When("a throttle func is configured", func() {
+			var (
+				throttleCalls     int
+				requestsPerSecond int
+				rethrottle        bool
+			)
+
+			BeforeEach(func() {
+				throttleCalls = 0
+				requestsPerSecond = fake.Number(1, 1000)
+				rethrottle = true
+
+				config.Migration.Throttle = func(_ context.Context, migration *Migration, actualTaskId string) (int, bool) {
+					throttleCalls++
+					Expect(migration.SourceIndex).To(Equal(expectedSourceIndex))
+					Expect(actualTaskId).To(Equal(taskId))
+
+					return requestsPerSecond, rethrottle
+				}
+
+				incompleteTaskResponse := &http.Response{
+					StatusCode: http.StatusOK,
+					Body: createESBody(&EsTask{
+						Completed: false,
+					}),
+				}
+				rethrottleResponse := &http.Response{
+					StatusCode: http.StatusOK,
+				}
+				mockTransport.preparedHttpResponses = insertResponseAt(mockTransport.preparedHttpResponses, incompleteTaskResponse, 3)
+				mockTransport.preparedHttpResponses = insertResponseAt(mockTransport.preparedHttpResponses, rethrottleResponse, 4)
+			})
+
+			It("should rethrottle the running task", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(throttleCalls).To(Equal(1))
+
+				Expect(mockTransport.receivedHttpRequests[4].Method).To(Equal(http.MethodPost))
+				Expect(mockTransport.receivedHttpRequests[4].URL.Path).To(Equal(fmt.Sprintf("/_reindex/%s/_rethrottle", taskId)))
+				Expect(mockTransport.receivedHttpRequests[4].URL.Query().Get("requests_per_second")).To(Equal(fmt.Sprint(requestsPerSecond)))
+			})
+
+			When("the throttle func doesn't request a change", func() {
+				BeforeEach(func() {
+					rethrottle = false
+					mockTransport.preparedHttpResponses = append(
+						mockTransport.preparedHttpResponses[:4],
+						mockTransport.preparedHttpResponses[5:]...)
+				})
+
+				It("should not rethrottle the task", func() {
+					Expect(actualError).NotTo(HaveOccurred())
+					Expect(mockTransport.receivedHttpRequests).To(HaveLen(8))
+					for _, request := range mockTransport.receivedHttpRequests {
+						Expect(request.URL.Path).NotTo(HaveSuffix("_rethrottle"))
+					}
+				})
+			})
+
+			When("rethrottling fails", func() {
+				BeforeEach(func() {
+					mockTransport.preparedHttpResponses[4].StatusCode = http.StatusInternalServerError
+				})
+
+				It("should continue the migration", func() {
+					Expect(actualError).NotTo(HaveOccurred())
+				})
+			})
+		})
+
+		When("the document kind was renamed", func() {
+			BeforeEach(func() {
+				previousAlias = fake.Word()
+				config.Migration.Strategy = MigrationStrategyDualWrite
+
+				getAliasesResponse := &http.Response{
+					StatusCode: http.StatusOK,
+					Body: createESBody(map[string]interface{}{
+						expectedSourceIndex: map[string]interface{}{
+							"aliases": map[string]interface{}{previousAlias: map[string]interface{}{}},
+						},
+					}),
+				}
+				responses := append([]*http.Response{}, mockTransport.preparedHttpResponses[:5]...)
+				responses = append(responses, getAliasesResponse)
+				mockTransport.preparedHttpResponses = append(responses, mockTransport.preparedHttpResponses[5:]...)
+			})
+
+			It("should use a write block regardless of the strategy", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(mockTransport.receivedHttpRequests[1].URL.Path).To(Equal(fmt.Sprintf("/%s/_block/write", expectedSourceIndex)))
+			})
+
+			It("should remove the previous alias and add the new one", func() {
+				expectedBody := &EsIndexAliasRequest{
+					Actions: []EsActions{
+						{
+							Remove: &EsIndexAlias{Index: expectedSourceIndex, Alias: previousAlias},
+						},
+						{
+							Add: &EsIndexAlias{Index: expectedTargetIndex, Alias: expectedAlias},
+						},
+					},
+				}
+				actualBody := &EsIndexAliasRequest{}
+				readRequestBody(mockTransport.receivedHttpRequests[6], actualBody)
+
+				Expect(mockTransport.receivedHttpRequests[5].URL.Path).To(Equal(fmt.Sprintf("/%s/_alias", expectedSourceIndex)))
+				Expect(actualBody).To(Equal(expectedBody))
+			})
+
+			When("previous aliases should be kept", func() {
+				BeforeEach(func() {
+					config.Migration.KeepPreviousAliases = true
+				})
+
+				It("should add the previous alias to the target index", func() {
+					actualBody := &EsIndexAliasRequest{}
+					readRequestBody(mockTransport.receivedHttpRequests[6], actualBody)
+
+					Expect(actualBody.Actions).To(HaveLen(3))
+					Expect(actualBody.Actions[2].Add).To(Equal(&EsIndexAlias{Index: expectedTargetIndex, Alias: previousAlias}))
+				})
+			})
+
+			When("the previous alias was already removed by an interrupted migration", func() {
+				BeforeEach(func() {
+					mockTransport.preparedHttpResponses[5].Body = createESBody(map[string]interface{}{
+						expectedSourceIndex: map[string]interface{}{"aliases": map[string]interface{}{}},
+					})
+				})
+
+				It("should only add the new alias, and delete the source index", func() {
+					Expect(actualError).NotTo(HaveOccurred())
+
+					actualBody := &EsIndexAliasRequest{}
+					readRequestBody(mockTransport.receivedHttpRequests[6], actualBody)
+					Expect(actualBody).To(Equal(&EsIndexAliasRequest{
+						Actions: []EsActions{
+							{
+								Add: &EsIndexAlias{Index: expectedTargetIndex, Alias: expectedAlias},
+							},
+						},
+					}))
+					Expect(mockTransport.receivedHttpRequests[7].Method).To(Equal(http.MethodDelete))
+					Expect(mockTransport.receivedHttpRequests[7].URL.Path).To(Equal("/" + expectedSourceIndex))
+				})
+			})
+		})
+
+		When("the target index should be recreated", func() {
+			BeforeEach(func() {
+				recovery = MigrationRecoveryRecreateTarget
+			})
+
+			It("should delete the existing target index before creating it again", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(mockRepo.DeleteIndexCallCount()).To(Equal(1))
+				_, actualIndex := mockRepo.DeleteIndexArgsForCall(0)
+				Expect(actualIndex).To(Equal(expectedTargetIndex))
+
+				Expect(mockRepo.CreateIndexCallCount()).To(Equal(1))
+			})
+
+			When("deleting the target index fails", func() {
+				BeforeEach(func() {
+					mockRepo.DeleteIndexReturns(errors.New(fake.Word()))
+				})
+
+				It("should return an error and not make any requests", func() {
+					Expect(actualError).To(HaveOccurred())
+					Expect(actualError.Error()).To(ContainSubstring("error deleting existing target index"))
+					Expect(mockTransport.receivedHttpRequests).To(BeEmpty())
+				})
+			})
+		})
+
+		When("the migration is resumed", func() {
+			BeforeEach(func() {
+				recovery = MigrationRecoveryResume
+			})
+
+			It("should keep the existing target index", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(mockRepo.DeleteIndexCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the dual-write strategy is used", func() {
+			var (
+				catchUpField string
+				catchUpTask  string
+			)
+
+			BeforeEach(func() {
+				catchUpField = fake.Word()
+				catchUpTask = fake.Word()
+				config.Migration.Strategy = MigrationStrategyDualWrite
+				config.Migration.CatchUpField = catchUpField
+
+				mockTransport.preparedHttpResponses = []*http.Response{
+					// move write index
+					{
+						StatusCode: http.StatusOK,
+					},
+					// refresh source index
+					{
+						StatusCode: http.StatusOK,
+					},
+					// reindex
+					{
+						StatusCode: http.StatusOK,
+						Body: createESBody(&EsTaskCreationResponse{
+							Task: taskId,
+						}),
+					},
+					// poll task
+					{
+						StatusCode: http.StatusOK,
+						Body: createESBody(&EsTask{
+							Completed: true,
+						}),
+					},
+					// delete task document
+					{
+						StatusCode: http.StatusOK,
+					},
+					// refresh source index again
+					{
+						StatusCode: http.StatusOK,
+					},
+					// catch-up reindex
+					{
+						StatusCode: http.StatusOK,
+						Body: createESBody(&EsTaskCreationResponse{
+							Task: catchUpTask,
+						}),
+					},
+					// poll task
+					{
+						StatusCode: http.StatusOK,
+						Body: createESBody(&EsTask{
+							Completed: true,
+						}),
+					},
+					// delete task document
+					{
+						StatusCode: http.StatusOK,
+					},
+					// update aliases
+					{
+						StatusCode: http.StatusOK,
+					},
+					// delete old index
+					{
+						StatusCode: http.StatusOK,
+					},
+				}
+			})
+
+			It("should not return an error", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(mockTransport.receivedHttpRequests).To(HaveLen(11))
+			})
+
+			It("should create the target index without the alias", func() {
+				Expect(mockRepo.CreateIndexCallCount()).To(Equal(1))
+
+				_, actualIndex, actualAlias, actualDocumentKind := mockRepo.CreateIndexArgsForCall(0)
+				Expect(actualIndex).To(Equal(expectedTargetIndex))
+				Expect(actualAlias).To(BeEmpty())
+				Expect(actualDocumentKind).To(Equal(documentKind))
+			})
+
+			It("should make the target the write index before reindexing", func() {
+				isWriteIndex := true
+				isNotWriteIndex := false
+				expectedBody := &EsIndexAliasRequest{
+					Actions: []EsActions{
+						{
+							Add: &EsIndexAlias{
+								Index:        expectedSourceIndex,
+								Alias:        expectedAlias,
+								IsWriteIndex: &isNotWriteIndex,
+							},
+						},
+						{
+							Add: &EsIndexAlias{
+								Index:        expectedTargetIndex,
+								Alias:        expectedAlias,
+								IsWriteIndex: &isWriteIndex,
+							},
+						},
+					},
+				}
+				actualBody := &EsIndexAliasRequest{}
+				readRequestBody(mockTransport.receivedHttpRequests[0], actualBody)
+
+				Expect(mockTransport.receivedHttpRequests[0].Method).To(Equal(http.MethodPost))
+				Expect(mockTransport.receivedHttpRequests[0].URL.Path).To(Equal("/_aliases"))
+				Expect(actualBody).To(Equal(expectedBody))
+			})
+
+			It("should not place a write block on the source index", func() {
+				for _, request := range mockTransport.receivedHttpRequests {
+					Expect(request.URL.Path).NotTo(HaveSuffix("/_block/write"))
+				}
+			})
+
+			It("should refresh the source index before reindexing", func() {
+				Expect(mockTransport.receivedHttpRequests[1].Method).To(Equal(http.MethodPost))
+				Expect(mockTransport.receivedHttpRequests[1].URL.Path).To(Equal(fmt.Sprintf("/%s/_refresh", expectedSourceIndex)))
+			})
+
+			It("should reindex all documents from the source index", func() {
+				actualBody := &EsReindex{}
+				readRequestBody(mockTransport.receivedHttpRequests[2], actualBody)
+
+				Expect(mockTransport.receivedHttpRequests[2].URL.Path).To(Equal("/_reindex"))
+				Expect(actualBody.Source.Query).To(BeNil())
+				Expect(actualBody.Destination.OpType).To(Equal("create"))
+			})
+
+			It("should refresh the source index before catching up", func() {
+				Expect(mockTransport.receivedHttpRequests[5].Method).To(Equal(http.MethodPost))
+				Expect(mockTransport.receivedHttpRequests[5].URL.Path).To(Equal(fmt.Sprintf("/%s/_refresh", expectedSourceIndex)))
+			})
+
+			It("should reindex documents written after the migration started", func() {
+				actualBody := &EsReindex{}
+				readRequestBody(mockTransport.receivedHttpRequests[6], actualBody)
+
+				Expect(mockTransport.receivedHttpRequests[6].URL.Path).To(Equal("/_reindex"))
+				Expect(actualBody.Source.Index).To(Equal(expectedSourceIndex))
+				Expect(actualBody.Source.Query).To(HaveKey("range"))
+				Expect(actualBody.Source.Query["range"]).To(HaveKey(catchUpField))
+				Expect(actualBody.Destination.OpType).To(Equal("create"))
+				Expect(mockTransport.receivedHttpRequests[7].URL.Path).To(Equal("/_tasks/" + catchUpTask))
+			})
+
+			It("should point the alias to the target index", func() {
+				actualBody := &EsIndexAliasRequest{}
+				readRequestBody(mockTransport.receivedHttpRequests[9], actualBody)
+
+				Expect(actualBody.Actions).To(HaveLen(2))
+				Expect(actualBody.Actions[0].Remove.Index).To(Equal(expectedSourceIndex))
+				Expect(actualBody.Actions[1].Add.Index).To(Equal(expectedTargetIndex))
+			})
+
+			It("should delete the source index", func() {
+				Expect(mockTransport.receivedHttpRequests[10].Method).To(Equal(http.MethodDelete))
+				Expect(mockTransport.receivedHttpRequests[10].URL.Path).To(Equal("/" + expectedSourceIndex))
+			})
+
+			When("no catch-up field is configured", func() {
+				BeforeEach(func() {
+					config.Migration.CatchUpField = ""
+					mockTransport.preparedHttpResponses = append(
+						mockTransport.preparedHttpResponses[:5],
+						mockTransport.preparedHttpResponses[9:]...)
+				})
+
+				It("should skip the catch-up reindex", func() {
+					Expect(actualError).NotTo(HaveOccurred())
+					Expect(mockTransport.receivedHttpRequests).To(HaveLen(7))
+				})
+
+				It("should still refresh the source index before reindexing", func() {
+					Expect(mockTransport.receivedHttpRequests[1].URL.Path).To(Equal(fmt.Sprintf("/%s/_refresh", expectedSourceIndex)))
+					Expect(mockTransport.receivedHttpRequests[2].URL.Path).To(Equal("/_reindex"))
+				})
+			})
+
+			When("moving the write index fails", func() {
+				BeforeEach(func() {
+					mockTransport.preparedHttpResponses[0].StatusCode = http.StatusInternalServerError
+				})
+
+				It("should return an error and not make any additional requests", func() {
+					Expect(actualError).To(HaveOccurred())
+					Expect(actualError.Error()).To(ContainSubstring("error occurred while moving the write index"))
+					Expect(mockTransport.receivedHttpRequests).To(HaveLen(1))
+				})
+			})
+
+			When("refreshing the source index fails", func() {
+				BeforeEach(func() {
+					mockTransport.preparedHttpResponses[1].StatusCode = http.StatusInternalServerError
+				})
+
+				It("should return an error without reindexing", func() {
+					Expect(actualError).To(MatchError(ContainSubstring("error refreshing source index")))
+					Expect(mockTransport.receivedHttpRequests).To(HaveLen(2))
+				})
+			})
+
+			When("refreshing the source index before catching up fails", func() {
+				BeforeEach(func() {
+					mockTransport.preparedHttpResponses[5].StatusCode = http.StatusInternalServerError
+				})
+
+				It("should return an error and not make any additional requests", func() {
+					Expect(actualError).To(HaveOccurred())
+					Expect(actualError.Error()).To(ContainSubstring("error refreshing source index"))
+					Expect(mockTransport.receivedHttpRequests).To(HaveLen(6))
+				})
+			})
+		})
+
+		When("the migration strategy is unknown", func() {
+			BeforeEach(func() {
+				config.Migration.Strategy = MigrationStrategy(fake.Word())
+			})
+
+			It("should return an error and not make any requests", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(actualError.Error()).To(ContainSubstring("unknown migration strategy"))
+				Expect(mockTransport.receivedHttpRequests).To(BeEmpty())
+			})
+		})
+
 		Context("placing the write block fails", func() {
 			When("an error occurs checking if the source index has a write block", func() {
 				BeforeEach(func() {
@@ -664,4 +1483,62 @@ var _ = Describe("Migrator", func() {
 			})
 		})
 	})
+
+	Context("Rethrottle", func() {
+		var (
+			taskId            string
+			requestsPerSecond int
+			actualError       error
+		)
+
+		BeforeEach(func() {
+			taskId = fake.Word()
+			requestsPerSecond = fake.Number(1, 1000)
+			mockTransport.preparedHttpResponses = []*http.Response{
+				{
+					StatusCode: http.StatusOK,
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			actualError = migrator.Rethrottle(ctx, taskId, requestsPerSecond)
+		})
+
+		It("should call the rethrottle API", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+			Expect(mockTransport.receivedHttpRequests[0].Method).To(Equal(http.MethodPost))
+			Expect(mockTransport.receivedHttpRequests[0].URL.Path).To(Equal(fmt.Sprintf("/_reindex/%s/_rethrottle", taskId)))
+			Expect(mockTransport.receivedHttpRequests[0].URL.Query().Get("requests_per_second")).To(Equal(fmt.Sprint(requestsPerSecond)))
+		})
+
+		When("the request fails", func() {
+			BeforeEach(func() {
+				mockTransport.preparedHttpResponses[0].StatusCode = http.StatusNotFound
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(actualError.Error()).To(ContainSubstring("error rethrottling reindex task"))
+			})
+		})
+	})
 })
+
+func managedIndexResponse(indexPrefix string, aliases map[string]interface{}, writeBlocked bool) map[string]interface{} {
+	return map[string]interface{}{
+		"aliases": aliases,
+		"mappings": map[string]interface{}{
+			"_meta": map[string]interface{}{
+				"type": indexPrefix,
+			},
+		},
+		"settings": map[string]interface{}{
+			"index": map[string]interface{}{
+				"blocks": map[string]interface{}{
+					"write": fmt.Sprint(writeBlocked),
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NamingStrategy controls how index and alias names are built from their parts, and how index names are parsed.
+type NamingStrategy interface {
+	// IndexName returns the name of an index. The inner name may be empty.
+	IndexName(prefix, version, inner, documentKind string) string
+	// AliasName returns the name of the alias for an index, which shouldn't include the version.
+	AliasName(prefix, inner, documentKind string) string
+	// ParseIndexName splits an index name created by IndexName into its parts. documentKinds contains every document
+	// kind that could appear in the name. If the name doesn't match, nil is returned.
+	ParseIndexName(prefix, indexName string, documentKinds []string) *IndexName
+}
+
+type IndexNamePart string
+
+const (
+	IndexNamePartPrefix       IndexNamePart = "prefix"
+	IndexNamePartVersion      IndexNamePart = "version"
+	IndexNamePartInner        IndexNamePart = "inner"
+	IndexNamePartDocumentKind IndexNamePart = "documentKind"
+)
+
+var defaultIndexNameOrder = []IndexNamePart{
+	IndexNamePartPrefix,
+	IndexNamePartVersion,
+	IndexNamePartInner,
+	IndexNamePartDocumentKind,
+}
+
+// DefaultNamingStrategy joins the name parts with a delimiter. With the zero value, names look like
+// prefix-version-inner-documentKind, and aliases like prefix-inner-documentKind.
+type DefaultNamingStrategy struct {
+	// Delimiter separates the parts of the name. Defaults to "-".
+	Delimiter string
+	// Order is the order the parts appear in the name, and must contain each part exactly once; otherwise LoadMappings
+	// returns an error.
+	// Defaults to prefix, version, inner, document kind.
+	Order []IndexNamePart
+}
+
+func (s *DefaultNamingStrategy) IndexName(prefix, version, inner, documentKind string) string {
+	return s.join(map[IndexNamePart]string{
+		IndexNamePartPrefix:       prefix,
+		IndexNamePartVersion:      version,
+		IndexNamePartInner:        inner,
+		IndexNamePartDocumentKind: documentKind,
+	})
+}
+
+func (s *DefaultNamingStrategy) AliasName(prefix, inner, documentKind string) string {
+	return s.join(map[IndexNamePart]string{
+		IndexNamePartPrefix:       prefix,
+		IndexNamePartInner:        inner,
+		IndexNamePartDocumentKind: documentKind,
+	})
+}
+
+// ParseIndexName matches the name against each document kind, starting with the longest, so that a kind that ends
+// with another kind (e.g., group-policy and policy) is preferred over treating part of it as the inner name.
+// The prefix and document kind may contain the delimiter, as may the inner name, which takes up whatever is left.
+func (s *DefaultNamingStrategy) ParseIndexName(prefix, indexName string, documentKinds []string) *IndexName {
+	delimiter := s.delimiter()
+	tokens := strings.Split(indexName, delimiter)
+
+	candidates := append([]string{}, documentKinds...)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return len(strings.Split(candidates[i], delimiter)) > len(strings.Split(candidates[j], delimiter))
+	})
+
+	for _, documentKind := range candidates {
+		if parsed := s.parseWithDocumentKind(tokens, prefix, documentKind); parsed != nil {
+			return parsed
+		}
+	}
+
+	return nil
+}
+
+func (s *DefaultNamingStrategy) parseWithDocumentKind(tokens []string, prefix, documentKind string) *IndexName {
+	delimiter := s.delimiter()
+	prefixTokens := splitNonEmpty(prefix, delimiter)
+	kindTokens := splitNonEmpty(documentKind, delimiter)
+
+	// the version is always a single token, so the inner name gets whatever is left over
+	innerLength := len(tokens) - len(prefixTokens) - len(kindTokens) - 1
+	if innerLength < 0 || len(kindTokens) == 0 {
+		return nil
+	}
+
+	parsed := &IndexName{DocumentKind: documentKind}
+	position := 0
+	for _, part := range s.order() {
+		switch part {
+		case IndexNamePartPrefix:
+			if !tokensEqual(tokens[position:position+len(prefixTokens)], prefixTokens) {
+				return nil
+			}
+			position += len(prefixTokens)
+		case IndexNamePartVersion:
+			if tokens[position] == "" {
+				return nil
+			}
+			parsed.Version = tokens[position]
+			position++
+		case IndexNamePartInner:
+			parsed.Inner = strings.Join(tokens[position:position+innerLength], delimiter)
+			position += innerLength
+		case IndexNamePartDocumentKind:
+			if !tokensEqual(tokens[position:position+len(kindTokens)], kindTokens) {
+				return nil
+			}
+			position += len(kindTokens)
+		}
+	}
+
+	return parsed
+}
+
+func (s *DefaultNamingStrategy) join(parts map[IndexNamePart]string) string {
+	var ordered []string
+	for _, part := range s.order() {
+		ordered = append(ordered, parts[part])
+	}
+
+	return nonEmptyJoin(ordered, s.delimiter())
+}
+
+// validate checks that the order contains each part exactly once, since names can't be parsed otherwise.
+func (s *DefaultNamingStrategy) validate() error {
+	seen := map[IndexNamePart]bool{}
+	for _, part := range s.order() {
+		switch part {
+		case IndexNamePartPrefix, IndexNamePartVersion, IndexNamePartInner, IndexNamePartDocumentKind:
+		default:
+			return fmt.Errorf(`unknown index name part "%s" in naming order`, part)
+		}
+
+		if seen[part] {
+			return fmt.Errorf(`index name part "%s" appears more than once in naming order`, part)
+		}
+		seen[part] = true
+	}
+
+	for _, part := range defaultIndexNameOrder {
+		if !seen[part] {
+			return fmt.Errorf(`index name part "%s" is missing from naming order`, part)
+		}
+	}
+
+	return nil
+}
+
+func (s *DefaultNamingStrategy) delimiter() string {
+	if s.Delimiter == "" {
+		return indexNamePartsDelimiter
+	}
+
+	return s.Delimiter
+}
+
+func (s *DefaultNamingStrategy) order() []IndexNamePart {
+	if len(s.Order) == 0 {
+		return defaultIndexNameOrder
+	}
+
+	return s.Order
+}
+
+func splitNonEmpty(value, delimiter string) []string {
+	if value == "" {
+		return nil
+	}
+
+	return strings.Split(value, delimiter)
+}
+
+func tokensEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func nonEmptyJoin(parts []string, delimiter string) string {
+	var nonEmpty []string
+	for _, str := range parts {
+		if str != "" {
+			nonEmpty = append(nonEmpty, str)
+		}
+	}
+
+	return strings.Join(nonEmpty, delimiter)
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	. "github.com/rode/es-index-manager/indexmanager"
+)
+
+var _ = Describe("DefaultNamingStrategy", func() {
+	var (
+		strategy      *DefaultNamingStrategy
+		documentKinds = []string{"policy", "group-policy", "policy_group"}
+	)
+
+	BeforeEach(func() {
+		strategy = &DefaultNamingStrategy{}
+	})
+
+	Context("with the default delimiter and order", func() {
+		It("should build index and alias names", func() {
+			Expect(strategy.IndexName("rode", "v1", "inner", "policy")).To(Equal("rode-v1-inner-policy"))
+			Expect(strategy.IndexName("rode", "v1", "", "policy")).To(Equal("rode-v1-policy"))
+			Expect(strategy.AliasName("rode", "inner", "group-policy")).To(Equal("rode-inner-group-policy"))
+		})
+
+		DescribeTable("parsing index names",
+			func(indexName string, expected *IndexName) {
+				Expect(strategy.ParseIndexName("rode", indexName, documentKinds)).To(Equal(expected))
+			},
+			Entry("without an inner name", "rode-v1-policy", &IndexName{DocumentKind: "policy", Version: "v1"}),
+			Entry("prefers the longest document kind", "rode-v1-group-policy", &IndexName{DocumentKind: "group-policy", Version: "v1"}),
+			Entry("with an inner name", "rode-v1-tenant-policy", &IndexName{DocumentKind: "policy", Version: "v1", Inner: "tenant"}),
+			Entry("with an inner name and longer kind", "rode-v1-tenant-group-policy", &IndexName{DocumentKind: "group-policy", Version: "v1", Inner: "tenant"}),
+			Entry("with an inner name containing the delimiter", "rode-v1-foo-bar-policy_group", &IndexName{DocumentKind: "policy_group", Version: "v1", Inner: "foo-bar"}),
+			Entry("with a different prefix", "other-v1-policy", nil),
+			Entry("with an unknown document kind", "rode-v1-unknown", nil),
+			Entry("without a version", "rode-policy", nil),
+		)
+	})
+
+	Context("with a custom delimiter and order", func() {
+		BeforeEach(func() {
+			strategy = &DefaultNamingStrategy{
+				Delimiter: ".",
+				Order: []IndexNamePart{
+					IndexNamePartPrefix,
+					IndexNamePartDocumentKind,
+					IndexNamePartInner,
+					IndexNamePartVersion,
+				},
+			}
+		})
+
+		It("should build index and alias names", func() {
+			Expect(strategy.IndexName("rode", "v1", "inner", "policy")).To(Equal("rode.policy.inner.v1"))
+			Expect(strategy.AliasName("rode", "", "policy")).To(Equal("rode.policy"))
+		})
+
+		It("should parse the names it builds", func() {
+			indexName := strategy.IndexName("rode", "v2", "a-b", "group-policy")
+
+			Expect(strategy.ParseIndexName("rode", indexName, documentKinds)).To(Equal(&IndexName{
+				DocumentKind: "group-policy",
+				Version:      "v2",
+				Inner:        "a-b",
+			}))
+		})
+
+		It("should not parse names using the default scheme", func() {
+			Expect(strategy.ParseIndexName("rode", "rode-v1-policy", documentKinds)).To(BeNil())
+		})
+	})
+})
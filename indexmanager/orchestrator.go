@@ -17,46 +17,251 @@ package indexmanager
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"go.uber.org/zap"
 )
 
 //counterfeiter:generate -o ../mocks . MigrationOrchestrator
 type MigrationOrchestrator interface {
-	RunMigrations(ctx context.Context) error
+	// RunMigrations finds and runs any pending migrations, up to MigrationConfig.Concurrency at a time.
+	// The report describes the outcome of every migration; an error is returned if any of them failed.
+	RunMigrations(ctx context.Context) (*MigrationReport, error)
 }
 
 type migrationOrchestrator struct {
+	config   *Config
 	logger   *zap.Logger
 	migrator Migrator
 }
 
-func NewMigrationOrchestrator(logger *zap.Logger, migrator Migrator) MigrationOrchestrator {
+func NewMigrationOrchestrator(logger *zap.Logger, migrator Migrator, config *Config) MigrationOrchestrator {
 	return &migrationOrchestrator{
+		config:   config,
 		logger:   logger,
 		migrator: migrator,
 	}
 }
 
-func (m *migrationOrchestrator) RunMigrations(ctx context.Context) error {
+func (m *migrationOrchestrator) RunMigrations(ctx context.Context) (*MigrationReport, error) {
 	log := m.logger.Named("RunMigrations")
 	migrations, err := m.migrator.GetMigrations(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	report := &MigrationReport{}
 	if len(migrations) == 0 {
 		log.Info("No migrations to run")
-		return nil
+		return report, nil
 	}
 
 	log.Info(fmt.Sprintf("Discovered %d migrations to run", len(migrations)))
 
+	ordered, err := orderByDependencies(migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsByKind := map[string][]*MigrationResult{}
+	// merged document kinds are migrated into the same target index, so each of those migrations waits for the previous one
+	previousByTarget := map[*MigrationResult]*MigrationResult{}
+	lastByTarget := map[string]*MigrationResult{}
+	for _, migration := range ordered {
+		result := &MigrationResult{
+			Migration: migration,
+			Status:    MigrationStatusSkipped,
+		}
+		report.Results = append(report.Results, result)
+		resultsByKind[migration.DocumentKind] = append(resultsByKind[migration.DocumentKind], result)
+
+		if previous, ok := lastByTarget[migration.TargetIndex]; ok {
+			previousByTarget[result] = previous
+		}
+		lastByTarget[migration.TargetIndex] = result
+	}
+
+	type outcome struct {
+		result *MigrationResult
+		err    error
+	}
+
+	// results are only changed by this goroutine, which starts each migration once it's ready and a slot is free, so a
+	// migration that's waiting doesn't hold up independent ones behind it
+	var (
+		firstErr error
+		running  int
+		stop     bool
+		pending  = append([]*MigrationResult{}, report.Results...)
+		finished = map[*MigrationResult]bool{}
+		outcomes = make(chan outcome)
+	)
+	for {
+		for i := 0; !stop && i < len(pending) && running < m.concurrency(); {
+			result := pending[i]
+			ready, err := m.ready(result, previousByTarget, resultsByKind, finished)
+			if err != nil {
+				log.Warn("Skipping migration", zap.String("index", result.Migration.SourceIndex), zap.Error(err))
+				result.Error = err
+				finished[result] = true
+				pending = append(pending[:i], pending[i+1:]...)
+				// a skipped migration may unblock ones that were already passed over
+				i = 0
+				continue
+			}
+
+			if !ready {
+				i++
+				continue
+			}
+
+			pending = append(pending[:i], pending[i+1:]...)
+			running++
+			go func(result *MigrationResult) {
+				outcomes <- outcome{result, m.migrator.Migrate(ctx, result.Migration)}
+			}(result)
+		}
+
+		if running == 0 {
+			break
+		}
+
+		completed := <-outcomes
+		running--
+		finished[completed.result] = true
+		if completed.err != nil {
+			log.Error("Migration failed", zap.String("index", completed.result.Migration.SourceIndex), zap.Error(completed.err))
+			completed.result.Status = MigrationStatusFailed
+			completed.result.Error = completed.err
+			if firstErr == nil {
+				firstErr = completed.err
+			}
+		} else {
+			completed.result.Status = MigrationStatusSucceeded
+		}
+
+		stop = (firstErr != nil && m.errorPolicy() == MigrationErrorPolicyFailFast) || ctx.Err() != nil
+	}
+
+	failed := report.Failed()
+	log.Info("Finished running migrations",
+		zap.Int("succeeded", len(report.Succeeded())),
+		zap.Int("failed", len(failed)),
+		zap.Int("skipped", len(report.Skipped())))
+
+	if len(failed) == 0 {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		return report, nil
+	}
+
+	if m.errorPolicy() == MigrationErrorPolicyFailFast {
+		return report, firstErr
+	}
+
+	var messages []string
+	for _, result := range failed {
+		messages = append(messages, fmt.Sprintf("%s: %s", result.Migration.SourceIndex, result.Error))
+	}
+
+	return report, fmt.Errorf("%d of %d migrations failed: %s", len(failed), len(report.Results), strings.Join(messages, "; "))
+}
+
+// ready checks if a migration can start: the previous migration into the same target index, and the migrations for
+// every document kind it depends on, must have finished. An error is returned if any of the dependencies didn't succeed.
+func (m *migrationOrchestrator) ready(
+	result *MigrationResult,
+	previousByTarget map[*MigrationResult]*MigrationResult,
+	resultsByKind map[string][]*MigrationResult,
+	finished map[*MigrationResult]bool,
+) (bool, error) {
+	if previous, ok := previousByTarget[result]; ok && !finished[previous] {
+		return false, nil
+	}
+
+	for _, documentKind := range result.Migration.DependsOn {
+		if documentKind == result.Migration.DocumentKind {
+			continue
+		}
+
+		for _, dependency := range resultsByKind[documentKind] {
+			if !finished[dependency] {
+				return false, nil
+			}
+
+			if dependency.Status != MigrationStatusSucceeded {
+				return false, fmt.Errorf("migration of %s for document kind %s did not succeed", dependency.Migration.SourceIndex, documentKind)
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// orderByDependencies sorts the migrations so that each one comes after the migrations of the document kinds it
+// depends on. Otherwise, the original order is preserved.
+func orderByDependencies(migrations []*Migration) ([]*Migration, error) {
+	remaining := map[string]int{}
 	for _, migration := range migrations {
-		if err := m.migrator.Migrate(ctx, migration); err != nil {
-			return err
+		remaining[migration.DocumentKind]++
+	}
+
+	var ordered []*Migration
+	placed := make([]bool, len(migrations))
+
+	for len(ordered) < len(migrations) {
+		progress := false
+		for i, migration := range migrations {
+			if placed[i] || !dependenciesPlaced(migration, remaining) {
+				continue
+			}
+
+			placed[i] = true
+			progress = true
+			ordered = append(ordered, migration)
+			remaining[migration.DocumentKind]--
+			break
+		}
+
+		if !progress {
+			var blocked []string
+			for i, migration := range migrations {
+				if !placed[i] {
+					blocked = append(blocked, migration.DocumentKind)
+				}
+			}
+
+			return nil, fmt.Errorf("dependency cycle detected between document kinds: %s", strings.Join(blocked, ", "))
+		}
+	}
+
+	return ordered, nil
+}
+
+func dependenciesPlaced(migration *Migration, remaining map[string]int) bool {
+	for _, dependency := range migration.DependsOn {
+		if dependency != migration.DocumentKind && remaining[dependency] > 0 {
+			return false
 		}
 	}
 
-	return nil
+	return true
+}
+
+func (m *migrationOrchestrator) concurrency() int {
+	if m.config.Migration.Concurrency < 1 {
+		return 1
+	}
+
+	return m.config.Migration.Concurrency
+}
+
+func (m *migrationOrchestrator) errorPolicy() MigrationErrorPolicy {
+	if m.config.Migration.ErrorPolicy == "" {
+		return MigrationErrorPolicyFailFast
+	}
+
+	return m.config.Migration.ErrorPolicy
 }
@@ -17,6 +17,8 @@ package indexmanager_test
 import (
 	"context"
 	"errors"
+	"sync/atomic"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -28,13 +30,17 @@ var _ = Describe("MigrationOrchestrator", func() {
 
 	var (
 		ctx          = context.Background()
+		config       *Config
 		mockMigrator *mocks.FakeMigrator
 		orchestrator MigrationOrchestrator
 	)
 
 	BeforeEach(func() {
+		config = &Config{
+			Migration: &MigrationConfig{},
+		}
 		mockMigrator = &mocks.FakeMigrator{}
-		orchestrator = NewMigrationOrchestrator(logger, mockMigrator)
+		orchestrator = NewMigrationOrchestrator(logger, mockMigrator, config)
 	})
 
 	Context("RunMigrations", func() {
@@ -42,7 +48,8 @@ var _ = Describe("MigrationOrchestrator", func() {
 			getMigrationsError error
 			migrations         []*Migration
 
-			actualError error
+			actualReport *MigrationReport
+			actualError  error
 		)
 
 		BeforeEach(func() {
@@ -61,7 +68,7 @@ var _ = Describe("MigrationOrchestrator", func() {
 		JustBeforeEach(func() {
 			mockMigrator.GetMigrationsReturns(migrations, getMigrationsError)
 
-			actualError = orchestrator.RunMigrations(ctx)
+			actualReport, actualError = orchestrator.RunMigrations(ctx)
 		})
 
 		When("there are no migrations", func() {
@@ -80,6 +87,10 @@ var _ = Describe("MigrationOrchestrator", func() {
 			It("should not return an error", func() {
 				Expect(actualError).To(BeNil())
 			})
+
+			It("should return an empty report", func() {
+				Expect(actualReport.Results).To(BeEmpty())
+			})
 		})
 
 		When("there are multiple migrations", func() {
@@ -96,6 +107,175 @@ var _ = Describe("MigrationOrchestrator", func() {
 			It("should not return an error", func() {
 				Expect(actualError).To(BeNil())
 			})
+
+			It("should report every migration as succeeded", func() {
+				Expect(actualReport.Results).To(HaveLen(len(migrations)))
+				Expect(actualReport.Succeeded()).To(HaveLen(len(migrations)))
+				Expect(actualReport.Failed()).To(BeEmpty())
+				Expect(actualReport.Skipped()).To(BeEmpty())
+
+				for i, result := range actualReport.Results {
+					Expect(result.Migration).To(Equal(migrations[i]))
+				}
+			})
+		})
+
+		When("concurrency is configured", func() {
+			var (
+				running    int32
+				maxRunning int32
+			)
+
+			BeforeEach(func() {
+				running = 0
+				maxRunning = 0
+				config.Migration.Concurrency = 2
+
+				for i := len(migrations); i < 6; i++ {
+					migrations = append(migrations, &Migration{
+						SourceIndex:  fake.Word(),
+						TargetIndex:  fake.Word(),
+						DocumentKind: fake.Word(),
+					})
+				}
+
+				mockMigrator.MigrateStub = func(_ context.Context, _ *Migration) error {
+					current := atomic.AddInt32(&running, 1)
+					for {
+						previous := atomic.LoadInt32(&maxRunning)
+						if current <= previous || atomic.CompareAndSwapInt32(&maxRunning, previous, current) {
+							break
+						}
+					}
+
+					time.Sleep(10 * time.Millisecond)
+					atomic.AddInt32(&running, -1)
+
+					return nil
+				}
+			})
+
+			It("should run every migration", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(mockMigrator.MigrateCallCount()).To(Equal(len(migrations)))
+			})
+
+			It("should not exceed the concurrency limit", func() {
+				Expect(atomic.LoadInt32(&maxRunning)).To(BeNumerically("<=", 2))
+			})
+
+			When("migrations share a target index", func() {
+				BeforeEach(func() {
+					targetIndex := fake.Word()
+					for _, migration := range migrations {
+						migration.TargetIndex = targetIndex
+					}
+				})
+
+				It("should run them one at a time", func() {
+					Expect(actualError).NotTo(HaveOccurred())
+					Expect(mockMigrator.MigrateCallCount()).To(Equal(len(migrations)))
+					Expect(atomic.LoadInt32(&maxRunning)).To(Equal(int32(1)))
+				})
+			})
+		})
+
+		When("migrations depend on other document kinds", func() {
+			BeforeEach(func() {
+				migrations = []*Migration{
+					{SourceIndex: "evaluation", DocumentKind: "evaluation", DependsOn: []string{"policy"}},
+					{SourceIndex: "other", DocumentKind: "other"},
+					{SourceIndex: "policy", DocumentKind: "policy"},
+				}
+			})
+
+			It("should run the dependencies first", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(mockMigrator.MigrateCallCount()).To(Equal(3))
+
+				var actualOrder []string
+				for i := 0; i < mockMigrator.MigrateCallCount(); i++ {
+					_, migration := mockMigrator.MigrateArgsForCall(i)
+					actualOrder = append(actualOrder, migration.DocumentKind)
+				}
+
+				Expect(actualOrder).To(Equal([]string{"other", "policy", "evaluation"}))
+			})
+
+			It("should return the results in the order they were run", func() {
+				Expect(actualReport.Results[0].Migration.DocumentKind).To(Equal("other"))
+				Expect(actualReport.Results[1].Migration.DocumentKind).To(Equal("policy"))
+				Expect(actualReport.Results[2].Migration.DocumentKind).To(Equal("evaluation"))
+			})
+
+			When("a dependency fails", func() {
+				BeforeEach(func() {
+					config.Migration.ErrorPolicy = MigrationErrorPolicyContinue
+					mockMigrator.MigrateStub = func(_ context.Context, migration *Migration) error {
+						if migration.DocumentKind == "policy" {
+							return errors.New(fake.Word())
+						}
+
+						return nil
+					}
+				})
+
+				It("should skip the dependent migration", func() {
+					Expect(actualError).To(HaveOccurred())
+					Expect(mockMigrator.MigrateCallCount()).To(Equal(2))
+
+					Expect(actualReport.Skipped()).To(HaveLen(1))
+					Expect(actualReport.Skipped()[0].Migration.DocumentKind).To(Equal("evaluation"))
+					Expect(actualReport.Skipped()[0].Error.Error()).To(ContainSubstring("did not succeed"))
+				})
+			})
+
+			When("a dependent migration is waiting and there's a free slot", func() {
+				var otherStarted chan struct{}
+
+				BeforeEach(func() {
+					config.Migration.Concurrency = 2
+					migrations = []*Migration{
+						{SourceIndex: "policy", TargetIndex: "policy", DocumentKind: "policy"},
+						{SourceIndex: "evaluation", TargetIndex: "evaluation", DocumentKind: "evaluation", DependsOn: []string{"policy"}},
+						{SourceIndex: "other", TargetIndex: "other", DocumentKind: "other"},
+					}
+					otherStarted = make(chan struct{})
+
+					mockMigrator.MigrateStub = func(_ context.Context, migration *Migration) error {
+						switch migration.DocumentKind {
+						case "other":
+							close(otherStarted)
+						case "policy":
+							select {
+							case <-otherStarted:
+							case <-time.After(time.Second):
+								return errors.New("other migration did not start while evaluation was waiting")
+							}
+						}
+
+						return nil
+					}
+				})
+
+				It("should start the independent migration", func() {
+					Expect(actualError).NotTo(HaveOccurred())
+					Expect(mockMigrator.MigrateCallCount()).To(Equal(3))
+					Expect(actualReport.Succeeded()).To(HaveLen(3))
+				})
+			})
+
+			When("the dependencies form a cycle", func() {
+				BeforeEach(func() {
+					migrations[2].DependsOn = []string{"evaluation"}
+				})
+
+				It("should return an error without running any migrations", func() {
+					Expect(actualError).To(HaveOccurred())
+					Expect(actualError.Error()).To(ContainSubstring("dependency cycle"))
+					Expect(mockMigrator.MigrateCallCount()).To(Equal(0))
+				})
+			})
 		})
 
 		When("an error occurs discovering migrations to run", func() {
@@ -120,6 +300,37 @@ var _ = Describe("MigrationOrchestrator", func() {
 			It("should return the error", func() {
 				Expect(actualError).To(Equal(expectedError))
 			})
+
+			It("should not start the remaining migrations", func() {
+				Expect(mockMigrator.MigrateCallCount()).To(Equal(1))
+
+				Expect(actualReport.Failed()).To(HaveLen(1))
+				Expect(actualReport.Failed()[0].Error).To(Equal(expectedError))
+				Expect(actualReport.Skipped()).To(HaveLen(len(migrations) - 1))
+			})
+
+			When("the error policy is to continue", func() {
+				BeforeEach(func() {
+					config.Migration.ErrorPolicy = MigrationErrorPolicyContinue
+					mockMigrator.MigrateReturnsOnCall(1, nil)
+				})
+
+				It("should run every migration", func() {
+					Expect(mockMigrator.MigrateCallCount()).To(Equal(len(migrations)))
+				})
+
+				It("should return an error describing the failures", func() {
+					Expect(actualError).To(HaveOccurred())
+					Expect(actualError.Error()).To(ContainSubstring("migrations failed"))
+					Expect(actualError.Error()).To(ContainSubstring(expectedError.Error()))
+				})
+
+				It("should report the outcome of each migration", func() {
+					Expect(actualReport.Succeeded()).To(HaveLen(1))
+					Expect(actualReport.Failed()).To(HaveLen(len(migrations) - 1))
+					Expect(actualReport.Skipped()).To(BeEmpty())
+				})
+			})
 		})
 	})
 })
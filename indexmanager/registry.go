@@ -15,13 +15,33 @@
 package indexmanager
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
+// directories holding resources that indices depend on, relative to Config.MappingsPath
+const (
+	ilmDirectory       = "ilm"
+	pipelinesDirectory = "pipelines"
+	scriptsDirectory   = "scripts"
+	seedsDirectory     = "seeds"
+)
+
+// MappingsRegistry is safe for concurrent use, including calling LoadMappings while other goroutines read from it.
+// Each call sees the mappings from a single load, and a reload replaces them all at once. The mappings, maps, and
+// seed documents it returns are shared, so callers must not modify them.
+//
 //counterfeiter:generate -o ../mocks . MappingsRegistry
 type MappingsRegistry interface {
 	// LoadMappings reads the index mapping and version from JSON file in the directory specified in Config.MappingsPath.
@@ -30,34 +50,343 @@ type MappingsRegistry interface {
 	IndexName(documentKind, inner string) string
 	// AliasName returns the full alias name, using the prefix, inner name, and document kind.
 	AliasName(documentKind, inner string) string
+	// TimeSeriesIndexName appends the date, if any, and the generation number to the name of an index in a time series,
+	// separated by the naming delimiter.
+	TimeSeriesIndexName(indexName string, generation int, date string) string
 	// Version returns the current schema version for the given document kind.
 	Version(documentKind string) string
 	// Mapping returns the current versioned mapping for the document kind.
 	Mapping(documentKind string) *VersionedMapping
 	// ParseIndexName determines the version, document kind, and inner name of an index.
+	// Indices for document kinds listed in a mapping's previousKinds are recognized as well.
 	// If the document kind cannot be determined, nil is returned
 	ParseIndexName(indexName string) *IndexName
+	// CurrentDocumentKind returns the document kind that the given kind was renamed to, the kind itself if it's in the
+	// registry, or the empty string if the kind is unknown.
+	CurrentDocumentKind(documentKind string) string
+	// DocumentKinds returns every document kind in the registry, sorted by name.
+	DocumentKinds() []string
+	// ILMPolicies returns the index lifecycle management policies, keyed by the name used in mapping files.
+	ILMPolicies() map[string]*VersionedPolicy
+	// Pipelines returns the ingest pipelines, keyed by the name used in mapping files.
+	Pipelines() map[string]*VersionedPipeline
+	// StoredScripts returns the stored scripts, keyed by name.
+	StoredScripts() map[string]*StoredScript
+	// ResourceName returns the name of an ILM policy, ingest pipeline, or stored script in the cluster, which includes
+	// the prefix.
+	ResourceName(name string) string
+	// IndexPrefix returns Config.IndexPrefix, which is recorded as the type in the _meta of the application's indices.
+	IndexPrefix() string
+	// Seeds returns the newline-delimited JSON seed documents for the document kind, or nil if it doesn't have any.
+	Seeds(documentKind string) []byte
+	// Watch reloads the mappings whenever a file in Config.MappingsPath changes, until the context is done. The
+	// registry must be created with NewDirectoryMappingsRegistry, since other filesystems (like an embed.FS) can't be
+	// watched. The
+	// loaded mappings are replaced all at once, so readers see either the old or the new mappings. After each reload an
+	// event is sent on the channel, which is closed when watching stops; if a reload fails, the previous mappings are
+	// kept and the event has the error. The channel must be read to keep watching.
+	Watch(ctx context.Context) (<-chan *MappingsReloadEvent, error)
 }
 
 type mappingsRegistry struct {
 	config     *Config
 	filesystem fs.FS
-	mappings   map[string]*VersionedMapping
+	naming     NamingStrategy
+	// directory is where the filesystem reads from on disk, if the registry was created with
+	// NewDirectoryMappingsRegistry; other filesystems can't be watched
+	directory string
+	// namingErr is returned by LoadMappings if the naming strategy is misconfigured
+	namingErr error
+	// delimiter separates the date and generation in time series index names
+	delimiter              string
+	timeSeriesPattern      *regexp.Regexp
+	datedTimeSeriesPattern *regexp.Regexp
+
+	mu    sync.RWMutex
+	state *registryState
+}
+
+// registryState is everything read by LoadMappings. It isn't modified after it's loaded, so that LoadMappings can
+// replace it while other goroutines are reading the previous state.
+type registryState struct {
+	mappings      map[string]*VersionedMapping
+	previousKinds map[string]string
+	policies      map[string]*VersionedPolicy
+	pipelines     map[string]*VersionedPipeline
+	scripts       map[string]*StoredScript
+	seeds         map[string][]byte
+}
+
+func newRegistryState() *registryState {
+	return &registryState{
+		mappings:      make(map[string]*VersionedMapping),
+		previousKinds: make(map[string]string),
+		policies:      make(map[string]*VersionedPolicy),
+		pipelines:     make(map[string]*VersionedPipeline),
+		scripts:       make(map[string]*StoredScript),
+		seeds:         make(map[string][]byte),
+	}
 }
 
 func NewMappingsRegistry(config *Config, filesystem fs.FS) MappingsRegistry {
+	return newMappingsRegistry(config, filesystem, "")
+}
+
+// NewDirectoryMappingsRegistry creates a registry that reads Config.MappingsPath relative to the directory on disk.
+// Unlike a registry from NewMappingsRegistry, it can be watched for changes.
+func NewDirectoryMappingsRegistry(config *Config, directory string) MappingsRegistry {
+	return newMappingsRegistry(config, os.DirFS(directory), directory)
+}
+
+func newMappingsRegistry(config *Config, filesystem fs.FS, directory string) *mappingsRegistry {
+	naming := config.NamingStrategy
+	if naming == nil {
+		naming = &DefaultNamingStrategy{}
+	}
+
+	var namingErr error
+	delimiter := indexNamePartsDelimiter
+	if strategy, ok := naming.(*DefaultNamingStrategy); ok {
+		namingErr = strategy.validate()
+		delimiter = strategy.delimiter()
+	}
+
+	quoted := regexp.QuoteMeta(delimiter)
+
 	return &mappingsRegistry{
-		config:     config,
-		filesystem: filesystem,
-		mappings:   make(map[string]*VersionedMapping),
+		config:                 config,
+		filesystem:             filesystem,
+		directory:              directory,
+		naming:                 naming,
+		namingErr:              namingErr,
+		delimiter:              delimiter,
+		timeSeriesPattern:      regexp.MustCompile(fmt.Sprintf(`^(.+)%s(\d{6,})$`, quoted)),
+		datedTimeSeriesPattern: regexp.MustCompile(fmt.Sprintf(`^(.+)%s(.+?)%s(\d{6,})$`, quoted, quoted)),
+		state:                  newRegistryState(),
 	}
 }
 
 func (mr *mappingsRegistry) LoadMappings() error {
-	mappingsDir := mr.config.MappingsPath
-	files, err := fs.ReadDir(mr.filesystem, mappingsDir)
+	state, err := mr.loadState()
+	if err != nil {
+		return err
+	}
+
+	mr.swap(state)
+
+	return nil
+}
+
+// loadState reads and validates the mappings and resources, without replacing the current ones.
+func (mr *mappingsRegistry) loadState() (*registryState, error) {
+	if mr.namingErr != nil {
+		return nil, fmt.Errorf("invalid naming strategy: %s", mr.namingErr)
+	}
+
+	mappings, err := combineMappingSources(mr.mappingSources())
+	if err != nil {
+		return nil, err
+	}
+
+	state := newRegistryState()
+
+	for _, documentKind := range sortedKeys(mappings) {
+		mapping := mappings[documentKind]
+		if mapping.TimeSeries != nil && mapping.DataStream != nil {
+			return nil, fmt.Errorf(`document kind "%s" can't be both a time series and a data stream`, documentKind)
+		}
+
+		if err := mr.validateVersion(documentKind, mapping.Version, mappings); err != nil {
+			return nil, err
+		}
+
+		if mapping.TimeSeries != nil && mr.containsDelimiter(mapping.TimeSeries.DateFormat) {
+			return nil, fmt.Errorf(`the date format for document kind "%s" can't contain the naming delimiter "%s"`, documentKind, mr.delimiter)
+		}
+
+		state.mappings[documentKind] = mapping
+	}
+
+	if err := state.loadPreviousKinds(); err != nil {
+		return nil, err
+	}
+
+	if err := mr.loadResources(state); err != nil {
+		return nil, err
+	}
+
+	if err := state.validateDependencies(); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// swap replaces the current state, returning the one it replaced.
+func (mr *mappingsRegistry) swap(state *registryState) *registryState {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	previous := mr.state
+	mr.state = state
+
+	return previous
+}
+
+// validateVersion checks that the version can be compared with other versions, and that it can be parsed back out of an
+// index name. A semantic version with a prerelease, like 1.0.0-rc.1, contains the default delimiter, so it would be read
+// as version 1.0.0 with an inner name.
+func (mr *mappingsRegistry) validateVersion(documentKind, version string, mappings map[string]*VersionedMapping) error {
+	if _, err := parseVersion(version); err != nil {
+		return fmt.Errorf(`invalid version for document kind "%s": %s`, documentKind, err)
+	}
+
+	indexName := mr.naming.IndexName(mr.config.IndexPrefix, version, "", documentKind)
+	parsed := mr.naming.ParseIndexName(mr.config.IndexPrefix, indexName, sortedKeys(mappings))
+	if parsed == nil || parsed.Version != version || parsed.DocumentKind != documentKind {
+		return fmt.Errorf(`version "%s" of document kind "%s" can't be used in index names, since it contains the naming delimiter`, version, documentKind)
+	}
+
+	return nil
+}
+
+// containsDelimiter checks if the date layout, or a date formatted with it, contains the delimiter, which would make the
+// date impossible to find in an index name.
+func (mr *mappingsRegistry) containsDelimiter(dateFormat string) bool {
+	if dateFormat == "" {
+		return false
+	}
+
+	return strings.Contains(dateFormat, mr.delimiter) || strings.Contains(time.Now().Format(dateFormat), mr.delimiter)
+}
+
+// current returns the state from the last successful call to LoadMappings.
+func (mr *mappingsRegistry) current() *registryState {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	return mr.state
+}
+
+// mappingSources returns the mapping files in Config.MappingsPath, followed by Config.DocumentStructs and
+// Config.MappingSources. The mappings directory is optional when there are other sources, and isn't read at all if
+// Config.MappingsPath is empty.
+func (mr *mappingsRegistry) mappingSources() []MappingSource {
+	var sources []MappingSource
+	if len(mr.config.DocumentStructs) != 0 {
+		sources = append(sources, NewStructSource(mr.config.DocumentStructs))
+	}
+	sources = append(sources, mr.config.MappingSources...)
+
+	if mr.config.MappingsPath == "" && len(sources) != 0 {
+		return sources
+	}
+
+	return append([]MappingSource{
+		&fileSystemSource{
+			name:       fmt.Sprintf("mapping files in %s", mr.config.MappingsPath),
+			filesystem: mr.filesystem,
+			path:       mr.config.MappingsPath,
+			optional:   len(sources) != 0,
+		},
+	}, sources...)
+}
+
+func (state *registryState) loadPreviousKinds() error {
+	for _, documentKind := range sortedKeys(state.mappings) {
+		for _, previousKind := range state.mappings[documentKind].PreviousKinds {
+			if _, ok := state.mappings[previousKind]; ok {
+				return fmt.Errorf(`document kind "%s" lists "%s" as a previous kind, but it still has a mapping`, documentKind, previousKind)
+			}
+
+			if existing, ok := state.previousKinds[previousKind]; ok && existing != documentKind {
+				return fmt.Errorf(`previous kind "%s" is claimed by both "%s" and "%s"`, previousKind, existing, documentKind)
+			}
+
+			state.previousKinds[previousKind] = documentKind
+		}
+	}
+
+	return nil
+}
+
+// loadResources reads the ILM policies, ingest pipelines, stored scripts, and seed documents from their optional
+// directories, and checks that every policy and pipeline referenced by a mapping exists.
+func (mr *mappingsRegistry) loadResources(state *registryState) error {
+	err := mr.readResourceDirectory(ilmDirectory, func(name, extension string, data []byte) error {
+		policy := &VersionedPolicy{}
+		state.policies[name] = policy
+
+		return json.Unmarshal(data, policy)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = mr.readResourceDirectory(pipelinesDirectory, func(name, extension string, data []byte) error {
+		pipeline := &VersionedPipeline{}
+		state.pipelines[name] = pipeline
+
+		return json.Unmarshal(data, pipeline)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = mr.readResourceDirectory(scriptsDirectory, func(name, extension string, data []byte) error {
+		if extension == ".painless" {
+			state.scripts[name] = &StoredScript{Lang: "painless", Source: string(data)}
+			return nil
+		}
+
+		script := &StoredScript{}
+		state.scripts[name] = script
+
+		return json.Unmarshal(data, script)
+	})
 	if err != nil {
-		return fmt.Errorf(`error finding mappings in directory: %s`, err)
+		return err
+	}
+
+	err = mr.readResourceDirectory(seedsDirectory, func(name, extension string, data []byte) error {
+		state.seeds[name] = data
+
+		return validateSeeds(data)
+	})
+	if err != nil {
+		return err
+	}
+
+	for documentKind := range state.seeds {
+		if _, ok := state.mappings[documentKind]; !ok {
+			return fmt.Errorf(`found seed documents for unknown document kind "%s"`, documentKind)
+		}
+	}
+
+	for _, documentKind := range sortedKeys(state.mappings) {
+		mapping := state.mappings[documentKind]
+		if _, ok := state.policies[mapping.ILMPolicy]; mapping.ILMPolicy != "" && !ok {
+			return fmt.Errorf(`document kind "%s" uses unknown ILM policy "%s"`, documentKind, mapping.ILMPolicy)
+		}
+
+		if _, ok := state.pipelines[mapping.DefaultPipeline]; mapping.DefaultPipeline != "" && !ok {
+			return fmt.Errorf(`document kind "%s" uses unknown pipeline "%s"`, documentKind, mapping.DefaultPipeline)
+		}
+	}
+
+	return nil
+}
+
+// readResourceDirectory calls load with the name, extension, and contents of each file in the directory, if it exists.
+func (mr *mappingsRegistry) readResourceDirectory(directory string, load func(name, extension string, data []byte) error) error {
+	if mr.config.MappingsPath == "" {
+		return nil
+	}
+
+	resourceDir := filepath.Join(mr.config.MappingsPath, directory)
+	files, err := fs.ReadDir(mr.filesystem, resourceDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf(`error finding resources in directory: %s`, err)
 	}
 
 	for _, file := range files {
@@ -65,70 +394,172 @@ func (mr *mappingsRegistry) LoadMappings() error {
 			continue
 		}
 
-		documentKind := strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
-		versionedMappingJson, err := fs.ReadFile(mr.filesystem, filepath.Join(mappingsDir, file.Name()))
-
+		data, err := fs.ReadFile(mr.filesystem, filepath.Join(resourceDir, file.Name()))
 		if err != nil {
 			return fmt.Errorf(`error reading file: %s`, err)
 		}
 
-		mapping := &VersionedMapping{}
-		if err := json.Unmarshal(versionedMappingJson, mapping); err != nil {
-			return fmt.Errorf(`invalid json in file "%s": %s`, file.Name(), err)
+		extension := filepath.Ext(file.Name())
+		if err := load(strings.TrimSuffix(file.Name(), extension), extension, data); err != nil {
+			return fmt.Errorf(`invalid json in file "%s": %s`, filepath.Join(directory, file.Name()), err)
 		}
+	}
+
+	return nil
+}
+
+// validateDependencies checks that every dependency is a known document kind, and that there are no cycles.
+func (state *registryState) validateDependencies() error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	visits := map[string]int{}
+
+	var visit func(documentKind string, path []string) error
+	visit = func(documentKind string, path []string) error {
+		switch visits[documentKind] {
+		case visiting:
+			return fmt.Errorf("dependency cycle between document kinds: %s", strings.Join(append(path, documentKind), " -> "))
+		case visited:
+			return nil
+		}
+
+		visits[documentKind] = visiting
+		for _, dependency := range state.mappings[documentKind].DependsOn {
+			if _, ok := state.mappings[dependency]; !ok {
+				return fmt.Errorf(`document kind "%s" depends on unknown document kind "%s"`, documentKind, dependency)
+			}
 
-		mr.mappings[documentKind] = mapping
+			if err := visit(dependency, append(path, documentKind)); err != nil {
+				return err
+			}
+		}
+		visits[documentKind] = visited
+
+		return nil
+	}
+
+	for _, documentKind := range sortedKeys(state.mappings) {
+		if err := visit(documentKind, nil); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
 func (mr *mappingsRegistry) ParseIndexName(indexName string) *IndexName {
-	// the index name is assumed to match one of the following types
-	// the documentKind may contain the delimiter
-	// indexPrefix-version-documentKind
-	// indexPrefix-version-innerName-documentKind
-	indexName = strings.TrimPrefix(indexName, mr.config.IndexPrefix+indexNamePartsDelimiter)
-	documentKind := ""
+	state := mr.current()
+	documentKinds := sortedKeys(state.mappings)
+	previousKinds := make([]string, 0, len(state.previousKinds))
+	for k := range state.previousKinds {
+		previousKinds = append(previousKinds, k)
+	}
+	sort.Strings(previousKinds)
+	documentKinds = append(documentKinds, previousKinds...)
 
-	for k := range mr.mappings {
-		if strings.HasSuffix(indexName, indexNamePartsDelimiter+k) {
-			documentKind = k
-			indexName = strings.TrimSuffix(indexName, indexNamePartsDelimiter+documentKind)
-			break
-		}
+	parsed := mr.naming.ParseIndexName(mr.config.IndexPrefix, indexName, documentKinds)
+	if parsed != nil && state.timeSeries(parsed.DocumentKind) == nil {
+		return parsed
 	}
 
-	if documentKind == "" {
-		return nil
+	if series := mr.parseTimeSeriesIndexName(state, indexName, documentKinds); series != nil {
+		return series
+	}
+
+	// a time series index without a generation, e.g. one created before the document kind became a time series
+	return parsed
+}
+
+// parseTimeSeriesIndexName removes the generation number (and date, if the series is dated) from the end of the index
+// name before parsing the rest of it.
+func (mr *mappingsRegistry) parseTimeSeriesIndexName(state *registryState, indexName string, documentKinds []string) *IndexName {
+	for _, pattern := range []*regexp.Regexp{mr.datedTimeSeriesPattern, mr.timeSeriesPattern} {
+		match := pattern.FindStringSubmatch(indexName)
+		if match == nil {
+			continue
+		}
+
+		parsed := mr.naming.ParseIndexName(mr.config.IndexPrefix, match[1], documentKinds)
+		if parsed == nil {
+			continue
+		}
+
+		series := state.timeSeries(parsed.DocumentKind)
+		dated := pattern == mr.datedTimeSeriesPattern
+		if series == nil || dated != (series.DateFormat != "") {
+			continue
+		}
+
+		parsed.Generation, _ = strconv.Atoi(match[len(match)-1])
+		if dated {
+			parsed.Date = match[2]
+		}
+
+		return parsed
 	}
 
-	parts := strings.Split(indexName, indexNamePartsDelimiter)
-	version := parts[0]
-	inner := strings.Join(parts[1:], indexNamePartsDelimiter)
+	return nil
+}
 
-	return &IndexName{
-		DocumentKind: documentKind,
-		Version:      version,
-		Inner:        inner,
+func (state *registryState) timeSeries(documentKind string) *TimeSeriesConfig {
+	if mapping, ok := state.mappings[documentKind]; ok {
+		return mapping.TimeSeries
 	}
+
+	return nil
 }
 
 func (mr *mappingsRegistry) IndexName(documentKind, inner string) string {
-	return nonEmptyJoin([]string{
-		mr.config.IndexPrefix,
-		mr.Version(documentKind),
-		inner,
-		documentKind,
-	}, indexNamePartsDelimiter)
+	return mr.versionedIndexName(mr.Version(documentKind), documentKind, inner)
+}
+
+// versionedIndexName names the index for a version of the document kind that was read earlier, which may no longer
+// be the current one.
+func (mr *mappingsRegistry) versionedIndexName(version, documentKind, inner string) string {
+	return mr.naming.IndexName(mr.config.IndexPrefix, version, inner, documentKind)
+}
+
+func (mr *mappingsRegistry) TimeSeriesIndexName(indexName string, generation int, date string) string {
+	if date != "" {
+		return fmt.Sprintf("%s%s%s%s%06d", indexName, mr.delimiter, date, mr.delimiter, generation)
+	}
+
+	return fmt.Sprintf("%s%s%06d", indexName, mr.delimiter, generation)
 }
 
 func (mr *mappingsRegistry) AliasName(documentKind, inner string) string {
-	return nonEmptyJoin([]string{
-		mr.config.IndexPrefix,
-		inner,
-		documentKind,
-	}, indexNamePartsDelimiter)
+	return mr.naming.AliasName(mr.config.IndexPrefix, inner, documentKind)
+}
+
+func (mr *mappingsRegistry) ILMPolicies() map[string]*VersionedPolicy {
+	return mr.current().policies
+}
+
+func (mr *mappingsRegistry) Pipelines() map[string]*VersionedPipeline {
+	return mr.current().pipelines
+}
+
+func (mr *mappingsRegistry) StoredScripts() map[string]*StoredScript {
+	return mr.current().scripts
+}
+
+func (mr *mappingsRegistry) Seeds(documentKind string) []byte {
+	return mr.current().seeds[documentKind]
+}
+
+func (mr *mappingsRegistry) ResourceName(name string) string {
+	return nonEmptyJoin([]string{mr.config.IndexPrefix, name}, indexNamePartsDelimiter)
+}
+
+func (mr *mappingsRegistry) IndexPrefix() string {
+	return mr.config.IndexPrefix
+}
+
+func (mr *mappingsRegistry) DocumentKinds() []string {
+	return sortedKeys(mr.current().mappings)
 }
 
 func (mr *mappingsRegistry) Version(documentKind string) string {
@@ -141,7 +572,7 @@ func (mr *mappingsRegistry) Version(documentKind string) string {
 }
 
 func (mr *mappingsRegistry) Mapping(documentKind string) *VersionedMapping {
-	mapping, ok := mr.mappings[documentKind]
+	mapping, ok := mr.current().mappings[documentKind]
 	if !ok {
 		return nil
 	}
@@ -149,13 +580,21 @@ func (mr *mappingsRegistry) Mapping(documentKind string) *VersionedMapping {
 	return mapping
 }
 
-func nonEmptyJoin(parts []string, delimiter string) string {
-	var nonEmpty []string
-	for _, str := range parts {
-		if str != "" {
-			nonEmpty = append(nonEmpty, str)
-		}
+func (mr *mappingsRegistry) CurrentDocumentKind(documentKind string) string {
+	state := mr.current()
+	if _, ok := state.mappings[documentKind]; ok {
+		return documentKind
+	}
+
+	return state.previousKinds[documentKind]
+}
+
+func sortedKeys(mappings map[string]*VersionedMapping) []string {
+	var keys []string
+	for key := range mappings {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
-	return strings.Join(nonEmpty, delimiter)
+	return keys
 }
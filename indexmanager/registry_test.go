@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"testing/fstest"
 
 	. "github.com/onsi/ginkgo"
@@ -93,6 +94,63 @@ var _ = Describe("MappingsRegistry", func() {
 			})
 		})
 
+		When("document kinds are defined by structs", func() {
+			BeforeEach(func() {
+				config.DocumentStructs = map[string]*DocumentStruct{
+					"build-struct": {
+						Version:  "v2",
+						Document: &struct{ Name string }{},
+						Settings: map[string]interface{}{"index.number_of_shards": 1},
+					},
+				}
+			})
+
+			It("should add them to the mappings from files", func() {
+				Expect(actualLoadMappingsError).NotTo(HaveOccurred())
+				Expect(registry.DocumentKinds()).To(ContainElements(append(uniqueSorted(expectedDocumentKinds), "build-struct")))
+				Expect(registry.Mapping("build-struct")).To(Equal(&VersionedMapping{
+					Version: "v2",
+					Mappings: map[string]interface{}{
+						"properties": map[string]interface{}{
+							"Name": map[string]interface{}{"type": "keyword"},
+						},
+					},
+					Settings: map[string]interface{}{"index.number_of_shards": 1},
+				}))
+			})
+
+			When("the mappings directory does not exist", func() {
+				BeforeEach(func() {
+					testFs = fstest.MapFS{}
+				})
+
+				It("should only load the structs", func() {
+					Expect(actualLoadMappingsError).NotTo(HaveOccurred())
+					Expect(registry.DocumentKinds()).To(Equal([]string{"build-struct"}))
+				})
+			})
+
+			When("a struct defines the same document kind as a file", func() {
+				BeforeEach(func() {
+					config.DocumentStructs[randomDocumentKind] = config.DocumentStructs["build-struct"]
+				})
+
+				It("should return an error", func() {
+					Expect(actualLoadMappingsError).To(MatchError(ContainSubstring(fmt.Sprintf(`"%s" is defined by both mapping files in %s and document structs`, randomDocumentKind, expectedMappingDir))))
+				})
+			})
+
+			When("a struct can't be mapped", func() {
+				BeforeEach(func() {
+					config.DocumentStructs["build-struct"].Document = "build"
+				})
+
+				It("should return an error", func() {
+					Expect(actualLoadMappingsError).To(MatchError(ContainSubstring(`error building mapping for document kind "build-struct"`)))
+				})
+			})
+		})
+
 		When("the file contents are invalid", func() {
 			BeforeEach(func() {
 				testFs[filepath.Join(expectedMappingDir, randomDocumentKind+".json")].Data = []byte("{")
@@ -104,6 +162,285 @@ var _ = Describe("MappingsRegistry", func() {
 			})
 		})
 
+		When("a document kind depends on an unknown document kind", func() {
+			BeforeEach(func() {
+				expectedMapping.DependsOn = []string{fake.UUID()}
+				testFs[filepath.Join(expectedMappingDir, randomDocumentKind+".json")] = mappingsFile(expectedMapping)
+			})
+
+			It("should return an error", func() {
+				Expect(actualLoadMappingsError).To(HaveOccurred())
+				Expect(actualLoadMappingsError.Error()).To(ContainSubstring("depends on unknown document kind"))
+			})
+		})
+
+		When("the document kind dependencies form a cycle", func() {
+			BeforeEach(func() {
+				testFs[filepath.Join(expectedMappingDir, "policy.json")] = mappingsFile(&VersionedMapping{
+					Version:   randomVersion(),
+					DependsOn: []string{"evaluation"},
+				})
+				testFs[filepath.Join(expectedMappingDir, "evaluation.json")] = mappingsFile(&VersionedMapping{
+					Version:   randomVersion(),
+					DependsOn: []string{"policy"},
+				})
+			})
+
+			It("should return an error", func() {
+				Expect(actualLoadMappingsError).To(HaveOccurred())
+				Expect(actualLoadMappingsError.Error()).To(ContainSubstring("dependency cycle"))
+			})
+		})
+
+		When("the document kinds have valid dependencies", func() {
+			BeforeEach(func() {
+				testFs[filepath.Join(expectedMappingDir, "policy.json")] = mappingsFile(&VersionedMapping{
+					Version: randomVersion(),
+				})
+				testFs[filepath.Join(expectedMappingDir, "evaluation.json")] = mappingsFile(&VersionedMapping{
+					Version:   randomVersion(),
+					DependsOn: []string{"policy"},
+				})
+			})
+
+			It("should not return an error", func() {
+				Expect(actualLoadMappingsError).NotTo(HaveOccurred())
+			})
+		})
+
+		When("the naming order repeats a part", func() {
+			BeforeEach(func() {
+				config.NamingStrategy = &DefaultNamingStrategy{
+					Order: []IndexNamePart{IndexNamePartPrefix, IndexNamePartVersion, IndexNamePartVersion, IndexNamePartDocumentKind},
+				}
+			})
+
+			It("should return an error", func() {
+				Expect(actualLoadMappingsError).To(HaveOccurred())
+				Expect(actualLoadMappingsError.Error()).To(ContainSubstring(`index name part "version" appears more than once`))
+			})
+		})
+
+		When("the naming order is missing a part", func() {
+			BeforeEach(func() {
+				config.NamingStrategy = &DefaultNamingStrategy{
+					Order: []IndexNamePart{IndexNamePartPrefix, IndexNamePartVersion, IndexNamePartDocumentKind},
+				}
+			})
+
+			It("should return an error", func() {
+				Expect(actualLoadMappingsError).To(HaveOccurred())
+				Expect(actualLoadMappingsError.Error()).To(ContainSubstring(`index name part "inner" is missing`))
+			})
+		})
+
+		When("a version can't be parsed", func() {
+			BeforeEach(func() {
+				testFs[filepath.Join(expectedMappingDir, "finding.json")] = mappingsFile(&VersionedMapping{Version: "latest"})
+			})
+
+			It("should return an error", func() {
+				Expect(actualLoadMappingsError).To(HaveOccurred())
+				Expect(actualLoadMappingsError.Error()).To(ContainSubstring(`invalid version for document kind "finding"`))
+			})
+		})
+
+		When("a version contains the naming delimiter", func() {
+			BeforeEach(func() {
+				testFs[filepath.Join(expectedMappingDir, "finding.json")] = mappingsFile(&VersionedMapping{Version: "1.0.0-rc.1"})
+			})
+
+			It("should return an error", func() {
+				Expect(actualLoadMappingsError).To(HaveOccurred())
+				Expect(actualLoadMappingsError.Error()).To(ContainSubstring("contains the naming delimiter"))
+			})
+
+			When("the naming strategy uses a different delimiter", func() {
+				BeforeEach(func() {
+					config.NamingStrategy = &DefaultNamingStrategy{Delimiter: "_"}
+				})
+
+				It("should load the mappings", func() {
+					Expect(actualLoadMappingsError).NotTo(HaveOccurred())
+					Expect(registry.Version("finding")).To(Equal("1.0.0-rc.1"))
+				})
+			})
+		})
+
+		When("a previous kind still has a mapping", func() {
+			BeforeEach(func() {
+				testFs[filepath.Join(expectedMappingDir, "finding.json")] = mappingsFile(&VersionedMapping{
+					Version:       randomVersion(),
+					PreviousKinds: []string{randomDocumentKind},
+				})
+			})
+
+			It("should return an error", func() {
+				Expect(actualLoadMappingsError).To(HaveOccurred())
+				Expect(actualLoadMappingsError.Error()).To(ContainSubstring("still has a mapping"))
+			})
+		})
+
+		When("two document kinds list the same previous kind", func() {
+			BeforeEach(func() {
+				testFs[filepath.Join(expectedMappingDir, "finding.json")] = mappingsFile(&VersionedMapping{
+					Version:       randomVersion(),
+					PreviousKinds: []string{"occurrence"},
+				})
+				testFs[filepath.Join(expectedMappingDir, "result.json")] = mappingsFile(&VersionedMapping{
+					Version:       randomVersion(),
+					PreviousKinds: []string{"occurrence"},
+				})
+			})
+
+			It("should return an error", func() {
+				Expect(actualLoadMappingsError).To(HaveOccurred())
+				Expect(actualLoadMappingsError.Error()).To(ContainSubstring("is claimed by both"))
+			})
+		})
+
+		When("a document kind is both a time series and a data stream", func() {
+			BeforeEach(func() {
+				testFs[filepath.Join(expectedMappingDir, "audit.json")] = mappingsFile(&VersionedMapping{
+					Version:    randomVersion(),
+					TimeSeries: &TimeSeriesConfig{},
+					DataStream: &DataStreamConfig{},
+				})
+			})
+
+			It("should return an error", func() {
+				Expect(actualLoadMappingsError).To(MatchError(ContainSubstring("both a time series and a data stream")))
+			})
+		})
+
+		When("there are ILM policies", func() {
+			var policy *VersionedPolicy
+
+			BeforeEach(func() {
+				policy = &VersionedPolicy{
+					Version: "v1",
+					Policy: map[string]interface{}{
+						"phases": map[string]interface{}{},
+					},
+				}
+				testFs[filepath.Join(expectedMappingDir, "ilm", "retention.json")] = mappingsFile(policy)
+				testFs[filepath.Join(expectedMappingDir, "audit.json")] = mappingsFile(&VersionedMapping{
+					Version:   randomVersion(),
+					ILMPolicy: "retention",
+				})
+			})
+
+			It("should load the policies", func() {
+				Expect(actualLoadMappingsError).NotTo(HaveOccurred())
+				Expect(registry.ILMPolicies()).To(Equal(map[string]*VersionedPolicy{
+					"retention": policy,
+				}))
+			})
+
+			It("should include the prefix in the policy name", func() {
+				Expect(registry.ResourceName("retention")).To(Equal(expectedIndexPrefix + "-retention"))
+			})
+
+			When("a mapping uses a policy that doesn't exist", func() {
+				BeforeEach(func() {
+					testFs[filepath.Join(expectedMappingDir, "event.json")] = mappingsFile(&VersionedMapping{
+						Version:   randomVersion(),
+						ILMPolicy: "missing",
+					})
+				})
+
+				It("should return an error", func() {
+					Expect(actualLoadMappingsError).To(MatchError(ContainSubstring(`unknown ILM policy "missing"`)))
+				})
+			})
+		})
+
+		When("there are pipelines and stored scripts", func() {
+			BeforeEach(func() {
+				testFs[filepath.Join(expectedMappingDir, "pipelines", "normalize.json")] = mappingsFile(&VersionedPipeline{
+					Version:  "v1",
+					Pipeline: map[string]interface{}{"processors": []interface{}{}},
+				})
+				testFs[filepath.Join(expectedMappingDir, "scripts", "score.painless")] = &fstest.MapFile{
+					Data: []byte("return 1;"),
+				}
+				testFs[filepath.Join(expectedMappingDir, "scripts", "template.json")] = mappingsFile(&StoredScript{
+					Lang:   "mustache",
+					Source: "{}",
+				})
+				testFs[filepath.Join(expectedMappingDir, "audit.json")] = mappingsFile(&VersionedMapping{
+					Version:         randomVersion(),
+					DefaultPipeline: "normalize",
+				})
+			})
+
+			It("should load them", func() {
+				Expect(actualLoadMappingsError).NotTo(HaveOccurred())
+				Expect(registry.Pipelines()).To(HaveKey("normalize"))
+				Expect(registry.StoredScripts()).To(Equal(map[string]*StoredScript{
+					"score":    {Lang: "painless", Source: "return 1;"},
+					"template": {Lang: "mustache", Source: "{}"},
+				}))
+			})
+
+			When("a mapping uses a pipeline that doesn't exist", func() {
+				BeforeEach(func() {
+					testFs[filepath.Join(expectedMappingDir, "event.json")] = mappingsFile(&VersionedMapping{
+						Version:         randomVersion(),
+						DefaultPipeline: "missing",
+					})
+				})
+
+				It("should return an error", func() {
+					Expect(actualLoadMappingsError).To(MatchError(ContainSubstring(`unknown pipeline "missing"`)))
+				})
+			})
+		})
+
+		When("there are seed documents", func() {
+			var seeds string
+
+			BeforeEach(func() {
+				seeds = `{"_id":"a","_source":{"name":"a"}}` + "\n" + `{"_id":"b","_source":{"name":"b"}}` + "\n"
+				testFs[filepath.Join(expectedMappingDir, "seeds", randomDocumentKind+".ndjson")] = &fstest.MapFile{
+					Data: []byte(seeds),
+				}
+			})
+
+			It("should load them", func() {
+				Expect(actualLoadMappingsError).NotTo(HaveOccurred())
+				Expect(registry.Seeds(randomDocumentKind)).To(Equal([]byte(seeds)))
+			})
+
+			It("should return nil for document kinds without seeds", func() {
+				Expect(registry.Seeds(fake.Word())).To(BeNil())
+			})
+
+			When("a seed document doesn't have an id", func() {
+				BeforeEach(func() {
+					testFs[filepath.Join(expectedMappingDir, "seeds", randomDocumentKind+".ndjson")] = &fstest.MapFile{
+						Data: []byte(`{"_source":{"name":"a"}}`),
+					}
+				})
+
+				It("should return an error", func() {
+					Expect(actualLoadMappingsError).To(MatchError(ContainSubstring("document 1 doesn't have an _id")))
+				})
+			})
+		})
+
+		When("there are seed documents for an unknown document kind", func() {
+			BeforeEach(func() {
+				testFs[filepath.Join(expectedMappingDir, "seeds", "unknown.ndjson")] = &fstest.MapFile{
+					Data: []byte(`{"_id":"a","_source":{}}`),
+				}
+			})
+
+			It("should return an error", func() {
+				Expect(actualLoadMappingsError).To(MatchError(ContainSubstring(`unknown document kind "unknown"`)))
+			})
+		})
+
 		When("there is a subdirectory", func() {
 			BeforeEach(func() {
 				testFs[filepath.Join(expectedMappingDir, fake.Word())] = &fstest.MapFile{
@@ -162,6 +499,27 @@ var _ = Describe("MappingsRegistry", func() {
 				Expect(actualIndexName).To(Equal(expectedIndexName))
 			})
 		})
+
+		When("a naming strategy is configured", func() {
+			BeforeEach(func() {
+				innerName = fake.Word()
+				config.NamingStrategy = &DefaultNamingStrategy{
+					Delimiter: "_",
+					Order:     []IndexNamePart{IndexNamePartPrefix, IndexNamePartDocumentKind, IndexNamePartInner, IndexNamePartVersion},
+				}
+			})
+
+			It("should use the strategy to build the index name", func() {
+				expectedIndexName := fmt.Sprintf("%s_%s_%s_%s", expectedIndexPrefix, randomDocumentKind, innerName, expectedMapping.Version)
+
+				Expect(actualIndexName).To(Equal(expectedIndexName))
+				Expect(registry.ParseIndexName(actualIndexName)).To(Equal(&IndexName{
+					DocumentKind: randomDocumentKind,
+					Version:      expectedMapping.Version,
+					Inner:        innerName,
+				}))
+			})
+		})
 	})
 
 	Context("AliasName", func() {
@@ -261,6 +619,35 @@ var _ = Describe("MappingsRegistry", func() {
 		})
 	})
 
+	Context("DocumentKinds", func() {
+		It("should return the sorted document kinds", func() {
+			Expect(registry.DocumentKinds()).To(Equal(uniqueSorted(expectedDocumentKinds)))
+		})
+	})
+
+	Context("CurrentDocumentKind", func() {
+		BeforeEach(func() {
+			testFs[filepath.Join(expectedMappingDir, "finding.json")] = mappingsFile(&VersionedMapping{
+				Version:       randomVersion(),
+				PreviousKinds: []string{"occurrence", "attestation"},
+			})
+		})
+
+		It("should return the new document kind for a previous kind", func() {
+			Expect(registry.CurrentDocumentKind("occurrence")).To(Equal("finding"))
+			Expect(registry.CurrentDocumentKind("attestation")).To(Equal("finding"))
+		})
+
+		It("should return registered document kinds unchanged", func() {
+			Expect(registry.CurrentDocumentKind("finding")).To(Equal("finding"))
+			Expect(registry.CurrentDocumentKind(randomDocumentKind)).To(Equal(randomDocumentKind))
+		})
+
+		It("should return the empty string for unknown document kinds", func() {
+			Expect(registry.CurrentDocumentKind(fake.UUID())).To(BeEmpty())
+		})
+	})
+
 	Context("ParseIndexName", func() {
 		var (
 			indexName       string
@@ -280,7 +667,7 @@ var _ = Describe("MappingsRegistry", func() {
 				indexName = "rode-v1alpha1-policies"
 
 				testFs[filepath.Join(config.MappingsPath, "policies.json")] = mappingsFile(&VersionedMapping{
-					Version:  fake.Word(),
+					Version:  randomVersion(),
 					Mappings: map[string]interface{}{},
 				})
 			})
@@ -298,7 +685,7 @@ var _ = Describe("MappingsRegistry", func() {
 				indexName = "rode-v1alpha1-test-policies"
 
 				testFs[filepath.Join(config.MappingsPath, "policies.json")] = mappingsFile(&VersionedMapping{
-					Version:  fake.Word(),
+					Version:  randomVersion(),
 					Mappings: map[string]interface{}{},
 				})
 			})
@@ -316,7 +703,7 @@ var _ = Describe("MappingsRegistry", func() {
 				indexName = "rode-v1alpha1-generic-resource"
 
 				testFs[filepath.Join(config.MappingsPath, "generic-resource.json")] = mappingsFile(&VersionedMapping{
-					Version:  fake.Word(),
+					Version:  randomVersion(),
 					Mappings: map[string]interface{}{},
 				})
 			})
@@ -334,7 +721,7 @@ var _ = Describe("MappingsRegistry", func() {
 				indexName = "rode-v1alpha1-long-inner-name-generic-resource"
 
 				testFs[filepath.Join(config.MappingsPath, "generic-resource.json")] = mappingsFile(&VersionedMapping{
-					Version:  fake.Word(),
+					Version:  randomVersion(),
 					Mappings: map[string]interface{}{},
 				})
 			})
@@ -347,6 +734,24 @@ var _ = Describe("MappingsRegistry", func() {
 			})
 		})
 
+		When("the index is for a previous document kind", func() {
+			BeforeEach(func() {
+				indexName = "rode-v1alpha1-test-occurrence"
+
+				testFs[filepath.Join(config.MappingsPath, "finding.json")] = mappingsFile(&VersionedMapping{
+					Version:       randomVersion(),
+					PreviousKinds: []string{"occurrence"},
+				})
+			})
+
+			It("should be parsed using the previous document kind", func() {
+				Expect(actualIndexName).NotTo(BeNil())
+				Expect(actualIndexName.Version).To(Equal("v1alpha1"))
+				Expect(actualIndexName.DocumentKind).To(Equal("occurrence"))
+				Expect(actualIndexName.Inner).To(Equal("test"))
+			})
+		})
+
 		When("the document kind is unknown", func() {
 			BeforeEach(func() {
 				indexName = "rode-v1alpha1-foo"
@@ -378,3 +783,17 @@ func createDocumentKinds() []string {
 
 	return kinds
 }
+
+func uniqueSorted(documentKinds []string) []string {
+	unique := map[string]bool{}
+	var keys []string
+	for _, documentKind := range documentKinds {
+		if !unique[documentKind] {
+			unique[documentKind] = true
+			keys = append(keys, documentKind)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
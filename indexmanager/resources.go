@@ -0,0 +1,435 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	"go.uber.org/zap"
+)
+
+//counterfeiter:generate -o ../mocks . ResourceManager
+type ResourceManager interface {
+	// InstallResources creates or updates the ILM policies, ingest pipelines, and stored scripts in the registry, so
+	// that they're in place before indices are created or migrated. Only resources that have drifted are changed, and
+	// newer versions in the cluster aren't downgraded unless MigrationConfig.AllowDowngrade is set.
+	InstallResources(ctx context.Context) error
+	// ResourceDrift compares the resources in the registry with the ones in the cluster, without changing anything.
+	// Resources that match aren't included.
+	ResourceDrift(ctx context.Context) ([]*ResourceDrift, error)
+}
+
+type resourceManager struct {
+	client   *elasticsearch.Client
+	config   *Config
+	logger   *zap.Logger
+	registry MappingsRegistry
+}
+
+// desiredResource is a resource from the registry, along with the request body used to install it.
+type desiredResource struct {
+	resourceType ResourceType
+	name         string
+	version      string
+	body         map[string]interface{}
+	// versioned is set when the version is stored in the resource's _meta, which depends on the cluster version
+	versioned   bool
+	compareBody bool
+	// ILM policies are returned with defaults filled in, so only the fields in the registry are compared
+	partialBody bool
+}
+
+// clusterFeatures records which resources accept _meta in the connected cluster.
+type clusterFeatures struct {
+	policyMeta   bool
+	pipelineMeta bool
+}
+
+// installedResource is the version and contents of a resource in the cluster, in the same shape as desiredResource.body
+type installedResource struct {
+	version string
+	body    map[string]interface{}
+}
+
+func NewResourceManager(logger *zap.Logger, client *elasticsearch.Client, registry MappingsRegistry, config *Config) ResourceManager {
+	return &resourceManager{
+		client,
+		config,
+		logger,
+		registry,
+	}
+}
+
+func (r *resourceManager) InstallResources(ctx context.Context) error {
+	log := r.logger.Named("InstallResources")
+
+	resources, err := r.desiredResources(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, resource := range resources {
+		drift, err := r.compare(ctx, resource)
+		if err != nil {
+			return err
+		}
+
+		if drift == nil {
+			continue
+		}
+
+		resourceLog := log.With(
+			zap.String("type", string(resource.resourceType)),
+			zap.String("name", resource.name),
+			zap.String("status", string(drift.Status)),
+			zap.String("version", drift.Version),
+			zap.String("installedVersion", drift.InstalledVersion),
+		)
+		if drift.Status == ResourceDriftNewer && !r.config.Migration.AllowDowngrade {
+			resourceLog.Warn("Resource is newer than the one in the registry, refusing to downgrade")
+			continue
+		}
+
+		if err := r.put(ctx, resource); err != nil {
+			return err
+		}
+		resourceLog.Info("Resource installed")
+	}
+
+	return nil
+}
+
+func (r *resourceManager) ResourceDrift(ctx context.Context) ([]*ResourceDrift, error) {
+	resources, err := r.desiredResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var report []*ResourceDrift
+	for _, resource := range resources {
+		drift, err := r.compare(ctx, resource)
+		if err != nil {
+			return nil, err
+		}
+
+		if drift != nil {
+			report = append(report, drift)
+		}
+	}
+
+	return report, nil
+}
+
+// desiredResources returns the resources in the registry in the order they're installed: policies and scripts first,
+// since pipelines can refer to either.
+func (r *resourceManager) desiredResources(ctx context.Context) ([]*desiredResource, error) {
+	var resources []*desiredResource
+
+	policies := r.registry.ILMPolicies()
+	pipelines := r.registry.Pipelines()
+	features := &clusterFeatures{}
+	if len(policies) != 0 || len(pipelines) != 0 {
+		var err error
+		if features, err = r.clusterFeatures(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var policyNames []string
+	for name := range policies {
+		policyNames = append(policyNames, name)
+	}
+	sort.Strings(policyNames)
+	for _, name := range policyNames {
+		policy := policies[name]
+		resource := &desiredResource{
+			resourceType: ResourceTypeILMPolicy,
+			name:         r.registry.ResourceName(name),
+			version:      policy.Version,
+			body:         map[string]interface{}{"policy": policy.Policy},
+			compareBody:  true,
+			partialBody:  true,
+		}
+		if features.policyMeta {
+			resource.body = map[string]interface{}{"policy": r.withMeta(policy.Policy, policy.Version)}
+			resource.versioned = true
+			resource.compareBody = false
+		}
+		resources = append(resources, resource)
+	}
+
+	scripts := r.registry.StoredScripts()
+	var scriptNames []string
+	for name := range scripts {
+		scriptNames = append(scriptNames, name)
+	}
+	sort.Strings(scriptNames)
+	for _, name := range scriptNames {
+		script := scripts[name]
+		resources = append(resources, &desiredResource{
+			resourceType: ResourceTypeStoredScript,
+			name:         r.registry.ResourceName(name),
+			body: map[string]interface{}{
+				"script": map[string]interface{}{
+					"lang":   script.Lang,
+					"source": script.Source,
+				},
+			},
+			compareBody: true,
+		})
+	}
+
+	var pipelineNames []string
+	for name := range pipelines {
+		pipelineNames = append(pipelineNames, name)
+	}
+	sort.Strings(pipelineNames)
+	for _, name := range pipelineNames {
+		pipeline := pipelines[name]
+		resource := &desiredResource{
+			resourceType: ResourceTypePipeline,
+			name:         r.registry.ResourceName(name),
+			version:      pipeline.Version,
+			body:         pipeline.Pipeline,
+			compareBody:  true,
+		}
+		if features.pipelineMeta {
+			resource.body = r.withMeta(pipeline.Pipeline, pipeline.Version)
+			resource.versioned = true
+		}
+		resources = append(resources, resource)
+	}
+
+	return resources, nil
+}
+
+// clusterFeatures checks the cluster version, since ILM policies only accept _meta from 7.14 and ingest pipelines from
+// 7.15. Older clusters reject it, so their resources are compared by contents instead.
+func (r *resourceManager) clusterFeatures(ctx context.Context) (*clusterFeatures, error) {
+	res, err := r.client.Info(r.client.Info.WithContext(ctx))
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return nil, fmt.Errorf("error getting cluster info: %s", err)
+	}
+
+	info := map[string]interface{}{}
+	if err := decodeResponse(res.Body, &info); err != nil {
+		return nil, fmt.Errorf("error decoding cluster info response: %s", err)
+	}
+
+	version, _ := info["version"].(map[string]interface{})
+	number, _ := version["number"].(string)
+	var major, minor int
+	if _, err := fmt.Sscanf(number, "%d.%d", &major, &minor); err != nil {
+		return nil, fmt.Errorf("unable to parse cluster version %q: %s", number, err)
+	}
+
+	return &clusterFeatures{
+		policyMeta:   major > 7 || (major == 7 && minor >= 14),
+		pipelineMeta: major > 7 || (major == 7 && minor >= 15),
+	}, nil
+}
+
+// withMeta copies the body, adding the prefix and version to its _meta.
+func (r *resourceManager) withMeta(body map[string]interface{}, version string) map[string]interface{} {
+	withMeta := map[string]interface{}{}
+	for k, v := range body {
+		withMeta[k] = v
+	}
+	withMeta["_meta"] = map[string]interface{}{
+		"type":    r.config.IndexPrefix,
+		"version": version,
+	}
+
+	return withMeta
+}
+
+// compare returns how the resource in the cluster differs from the one in the registry, or nil if they match.
+func (r *resourceManager) compare(ctx context.Context, resource *desiredResource) (*ResourceDrift, error) {
+	installed, err := r.get(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	drift := &ResourceDrift{
+		Type:    resource.resourceType,
+		Name:    resource.name,
+		Version: resource.version,
+	}
+
+	if installed == nil {
+		drift.Status = ResourceDriftMissing
+		return drift, nil
+	}
+	drift.InstalledVersion = installed.version
+
+	if resource.versioned && installed.version != resource.version {
+		drift.Status = ResourceDriftOutdated
+
+		// resources without a version were installed by something else, so they're treated as outdated
+		if installed.version != "" {
+			comparison, err := CompareVersions(installed.version, resource.version)
+			if err != nil {
+				return nil, fmt.Errorf("unable to compare versions of %s %s: %s", resource.resourceType, resource.name, err)
+			}
+
+			if comparison > 0 {
+				drift.Status = ResourceDriftNewer
+			}
+		}
+
+		return drift, nil
+	}
+
+	if resource.compareBody && !jsonEqual(resource.body, installed.body, resource.partialBody) {
+		drift.Status = ResourceDriftModified
+		return drift, nil
+	}
+
+	return nil, nil
+}
+
+// get returns the resource from the cluster, or nil if it doesn't exist.
+func (r *resourceManager) get(ctx context.Context, resource *desiredResource) (*installedResource, error) {
+	var (
+		res *esapi.Response
+		err error
+	)
+	switch resource.resourceType {
+	case ResourceTypeILMPolicy:
+		res, err = r.client.ILM.GetLifecycle(r.client.ILM.GetLifecycle.WithContext(ctx), r.client.ILM.GetLifecycle.WithPolicy(resource.name))
+	case ResourceTypePipeline:
+		res, err = r.client.Ingest.GetPipeline(r.client.Ingest.GetPipeline.WithContext(ctx), r.client.Ingest.GetPipeline.WithPipelineID(resource.name))
+	case ResourceTypeStoredScript:
+		res, err = r.client.GetScript(resource.name, r.client.GetScript.WithContext(ctx))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting %s %s: %s", resource.resourceType, resource.name, err)
+	}
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if res.IsError() {
+		return nil, fmt.Errorf("error getting %s %s, status: %d", resource.resourceType, resource.name, res.StatusCode)
+	}
+
+	response := map[string]interface{}{}
+	if err := decodeResponse(res.Body, &response); err != nil {
+		return nil, fmt.Errorf("error decoding %s response: %s", resource.resourceType, err)
+	}
+
+	var body map[string]interface{}
+	switch resource.resourceType {
+	case ResourceTypeILMPolicy:
+		// {"name": {"version": 1, "policy": {...}}}
+		installed, _ := response[resource.name].(map[string]interface{})
+		policy, _ := installed["policy"].(map[string]interface{})
+
+		return &installedResource{version: metaVersion(policy), body: map[string]interface{}{"policy": policy}}, nil
+	case ResourceTypePipeline:
+		// {"name": {...}}
+		body, _ = response[resource.name].(map[string]interface{})
+	case ResourceTypeStoredScript:
+		// {"_id": "name", "found": true, "script": {"lang": "painless", "source": "..."}}
+		script, _ := response["script"].(map[string]interface{})
+		body = map[string]interface{}{
+			"script": map[string]interface{}{
+				"lang":   script["lang"],
+				"source": script["source"],
+			},
+		}
+	}
+
+	return &installedResource{version: metaVersion(body), body: body}, nil
+}
+
+func (r *resourceManager) put(ctx context.Context, resource *desiredResource) error {
+	var (
+		res *esapi.Response
+		err error
+	)
+	body, _ := encodeRequest(resource.body)
+	switch resource.resourceType {
+	case ResourceTypeILMPolicy:
+		res, err = r.client.ILM.PutLifecycle(resource.name, r.client.ILM.PutLifecycle.WithContext(ctx), r.client.ILM.PutLifecycle.WithBody(body))
+	case ResourceTypePipeline:
+		res, err = r.client.Ingest.PutPipeline(resource.name, body, r.client.Ingest.PutPipeline.WithContext(ctx))
+	case ResourceTypeStoredScript:
+		res, err = r.client.PutScript(resource.name, body, r.client.PutScript.WithContext(ctx))
+	}
+
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return fmt.Errorf("error installing %s %s: %s", resource.resourceType, resource.name, err)
+	}
+
+	return nil
+}
+
+func metaVersion(body map[string]interface{}) string {
+	meta, _ := body["_meta"].(map[string]interface{})
+	version, _ := meta["version"].(string)
+
+	return version
+}
+
+// jsonEqual compares the desired body with one decoded from a response, after round-tripping it through JSON so that
+// both use the same types. When partial is set, fields that are only in the installed body are ignored.
+func jsonEqual(desired, installed map[string]interface{}, partial bool) bool {
+	data, err := json.Marshal(desired)
+	if err != nil {
+		return false
+	}
+
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return false
+	}
+
+	if partial {
+		return jsonContains(installed, normalized)
+	}
+
+	return reflect.DeepEqual(normalized, installed)
+}
+
+// jsonContains reports whether every field in desired has the same value in installed, recursing into objects.
+func jsonContains(installed, desired interface{}) bool {
+	desiredObject, ok := desired.(map[string]interface{})
+	if !ok {
+		return reflect.DeepEqual(desired, installed)
+	}
+
+	installedObject, ok := installed.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	for k, v := range desiredObject {
+		if !jsonContains(installedObject[k], v) {
+			return false
+		}
+	}
+
+	return true
+}
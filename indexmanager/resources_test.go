@@ -0,0 +1,478 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager_test
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/rode/es-index-manager/indexmanager"
+	"github.com/rode/es-index-manager/mocks"
+)
+
+var _ = Describe("ResourceManager", func() {
+	var (
+		ctx           = context.Background()
+		config        *Config
+		mockTransport *mockEsTransport
+		mockRegistry  *mocks.FakeMappingsRegistry
+		manager       ResourceManager
+
+		policyName string
+		policy     *VersionedPolicy
+	)
+
+	BeforeEach(func() {
+		config = &Config{
+			IndexPrefix: fake.Word(),
+			Migration:   &MigrationConfig{},
+		}
+		mockTransport = &mockEsTransport{}
+		mockEsClient := &elasticsearch.Client{Transport: mockTransport, API: esapi.New(mockTransport)}
+		mockRegistry = &mocks.FakeMappingsRegistry{}
+
+		policyName = fake.Word()
+		policy = &VersionedPolicy{
+			Version: "v2",
+			Policy: map[string]interface{}{
+				"phases": map[string]interface{}{
+					"delete": map[string]interface{}{"min_age": "30d"},
+				},
+			},
+		}
+		mockRegistry.ResourceNameReturns(policyName)
+
+		manager = NewResourceManager(logger, mockEsClient, mockRegistry, config)
+	})
+
+	Context("InstallResources", func() {
+		var actualError error
+
+		JustBeforeEach(func() {
+			actualError = manager.InstallResources(ctx)
+		})
+
+		When("there are no resources", func() {
+			It("should not make any requests", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(mockTransport.receivedHttpRequests).To(BeEmpty())
+			})
+		})
+
+		When("there is an ILM policy", func() {
+			var installedVersion string
+
+			BeforeEach(func() {
+				mockRegistry.ILMPoliciesReturns(map[string]*VersionedPolicy{"retention": policy})
+				installedVersion = ""
+			})
+
+			When("the policy doesn't exist", func() {
+				BeforeEach(func() {
+					mockTransport.preparedHttpResponses = []*http.Response{
+						clusterInfoResponse("7.14.0"),
+						{StatusCode: http.StatusNotFound},
+						{StatusCode: http.StatusOK},
+					}
+				})
+
+				It("should create the policy with the version in the metadata", func() {
+					Expect(actualError).NotTo(HaveOccurred())
+					Expect(mockRegistry.ResourceNameArgsForCall(0)).To(Equal("retention"))
+					Expect(mockTransport.receivedHttpRequests).To(HaveLen(3))
+					Expect(mockTransport.receivedHttpRequests[0].URL.Path).To(Equal("/"))
+					Expect(mockTransport.receivedHttpRequests[1].URL.Path).To(Equal("/_ilm/policy/" + policyName))
+					Expect(mockTransport.receivedHttpRequests[2].Method).To(Equal(http.MethodPut))
+					Expect(mockTransport.receivedHttpRequests[2].URL.Path).To(Equal("/_ilm/policy/" + policyName))
+
+					actualPayload := map[string]interface{}{}
+					readRequestBody(mockTransport.receivedHttpRequests[2], &actualPayload)
+					Expect(actualPayload).To(Equal(map[string]interface{}{
+						"policy": map[string]interface{}{
+							"phases": policy.Policy["phases"],
+							"_meta": map[string]interface{}{
+								"type":    config.IndexPrefix,
+								"version": "v2",
+							},
+						},
+					}))
+				})
+			})
+
+			When("the policy exists", func() {
+				BeforeEach(func() {
+					// the response is built lazily, so that nested contexts can set the installed version
+					mockTransport.actions = []transportAction{
+						func(req *http.Request) (*http.Response, error) {
+							return clusterInfoResponse("7.14.0"), nil
+						},
+						func(req *http.Request) (*http.Response, error) {
+							return &http.Response{
+								StatusCode: http.StatusOK,
+								Body: createESBody(map[string]interface{}{
+									policyName: map[string]interface{}{
+										"policy": map[string]interface{}{
+											"_meta": map[string]interface{}{"version": installedVersion},
+										},
+									},
+								}),
+							}, nil
+						},
+					}
+					mockTransport.preparedHttpResponses = []*http.Response{
+						{StatusCode: http.StatusOK},
+					}
+				})
+
+				When("it has the same version", func() {
+					BeforeEach(func() {
+						installedVersion = "v2"
+					})
+
+					It("should not update the policy", func() {
+						Expect(actualError).NotTo(HaveOccurred())
+						Expect(mockTransport.receivedHttpRequests).To(HaveLen(2))
+					})
+				})
+
+				When("it has an older version", func() {
+					BeforeEach(func() {
+						installedVersion = "v1"
+					})
+
+					It("should update the policy", func() {
+						Expect(actualError).NotTo(HaveOccurred())
+						Expect(mockTransport.receivedHttpRequests).To(HaveLen(3))
+						Expect(mockTransport.receivedHttpRequests[2].Method).To(Equal(http.MethodPut))
+					})
+				})
+
+				When("it has a newer version", func() {
+					BeforeEach(func() {
+						installedVersion = "v3"
+					})
+
+					It("should not downgrade the policy", func() {
+						Expect(actualError).NotTo(HaveOccurred())
+						Expect(mockTransport.receivedHttpRequests).To(HaveLen(2))
+					})
+
+					When("downgrades are allowed", func() {
+						BeforeEach(func() {
+							config.Migration.AllowDowngrade = true
+						})
+
+						It("should update the policy", func() {
+							Expect(mockTransport.receivedHttpRequests).To(HaveLen(3))
+						})
+					})
+				})
+			})
+
+			When("the cluster doesn't support metadata on policies", func() {
+				var installedPolicy map[string]interface{}
+
+				BeforeEach(func() {
+					// the cluster fills in defaults, like the delete action's options
+					installedPolicy = map[string]interface{}{
+						"phases": map[string]interface{}{
+							"delete": map[string]interface{}{
+								"min_age": "30d",
+								"actions": map[string]interface{}{
+									"delete": map[string]interface{}{"delete_searchable_snapshot": true},
+								},
+							},
+						},
+					}
+					mockTransport.actions = []transportAction{
+						func(req *http.Request) (*http.Response, error) {
+							return clusterInfoResponse("7.12.0"), nil
+						},
+						func(req *http.Request) (*http.Response, error) {
+							return &http.Response{
+								StatusCode: http.StatusOK,
+								Body: createESBody(map[string]interface{}{
+									policyName: map[string]interface{}{
+										"version": 1,
+										"policy":  installedPolicy,
+									},
+								}),
+							}, nil
+						},
+					}
+					mockTransport.preparedHttpResponses = []*http.Response{
+						{StatusCode: http.StatusOK},
+					}
+				})
+
+				When("the installed policy contains the one in the registry", func() {
+					It("should not update the policy", func() {
+						Expect(actualError).NotTo(HaveOccurred())
+						Expect(mockTransport.receivedHttpRequests).To(HaveLen(2))
+					})
+				})
+
+				When("the installed policy is different", func() {
+					BeforeEach(func() {
+						installedPolicy["phases"].(map[string]interface{})["delete"].(map[string]interface{})["min_age"] = "7d"
+					})
+
+					It("should update the policy without metadata", func() {
+						Expect(actualError).NotTo(HaveOccurred())
+						Expect(mockTransport.receivedHttpRequests).To(HaveLen(3))
+						Expect(mockTransport.receivedHttpRequests[2].Method).To(Equal(http.MethodPut))
+
+						actualPayload := map[string]interface{}{}
+						readRequestBody(mockTransport.receivedHttpRequests[2], &actualPayload)
+						Expect(actualPayload).To(Equal(map[string]interface{}{
+							"policy": map[string]interface{}{
+								"phases": policy.Policy["phases"],
+							},
+						}))
+					})
+				})
+			})
+
+			When("getting the cluster info fails", func() {
+				BeforeEach(func() {
+					mockTransport.preparedHttpResponses = []*http.Response{
+						{StatusCode: http.StatusInternalServerError},
+					}
+				})
+
+				It("should return an error", func() {
+					Expect(actualError).To(MatchError(ContainSubstring("error getting cluster info")))
+					Expect(mockTransport.receivedHttpRequests).To(HaveLen(1))
+				})
+			})
+		})
+
+		When("there is an ingest pipeline", func() {
+			var pipeline *VersionedPipeline
+
+			BeforeEach(func() {
+				pipeline = &VersionedPipeline{
+					Version: "v1",
+					Pipeline: map[string]interface{}{
+						"processors": []interface{}{
+							map[string]interface{}{"lowercase": map[string]interface{}{"field": "name"}},
+						},
+					},
+				}
+				mockRegistry.PipelinesReturns(map[string]*VersionedPipeline{"normalize": pipeline})
+			})
+
+			When("the pipeline matches", func() {
+				BeforeEach(func() {
+					mockTransport.preparedHttpResponses = []*http.Response{
+						clusterInfoResponse("7.15.0"),
+						{
+							StatusCode: http.StatusOK,
+							Body: createESBody(map[string]interface{}{
+								policyName: map[string]interface{}{
+									"processors": pipeline.Pipeline["processors"],
+									"_meta":      map[string]interface{}{"type": config.IndexPrefix, "version": "v1"},
+								},
+							}),
+						},
+					}
+				})
+
+				It("should not update the pipeline", func() {
+					Expect(actualError).NotTo(HaveOccurred())
+					Expect(mockTransport.receivedHttpRequests).To(HaveLen(2))
+					Expect(mockTransport.receivedHttpRequests[1].URL.Path).To(Equal("/_ingest/pipeline/" + policyName))
+				})
+			})
+
+			When("the pipeline was modified in the cluster", func() {
+				BeforeEach(func() {
+					mockTransport.preparedHttpResponses = []*http.Response{
+						clusterInfoResponse("7.15.0"),
+						{
+							StatusCode: http.StatusOK,
+							Body: createESBody(map[string]interface{}{
+								policyName: map[string]interface{}{
+									"processors": []interface{}{},
+									"_meta":      map[string]interface{}{"type": config.IndexPrefix, "version": "v1"},
+								},
+							}),
+						},
+						{StatusCode: http.StatusOK},
+					}
+				})
+
+				It("should restore the pipeline from the registry", func() {
+					Expect(actualError).NotTo(HaveOccurred())
+					Expect(mockTransport.receivedHttpRequests).To(HaveLen(3))
+					Expect(mockTransport.receivedHttpRequests[2].Method).To(Equal(http.MethodPut))
+					Expect(mockTransport.receivedHttpRequests[2].URL.Path).To(Equal("/_ingest/pipeline/" + policyName))
+
+					actualPayload := map[string]interface{}{}
+					readRequestBody(mockTransport.receivedHttpRequests[2], &actualPayload)
+					Expect(actualPayload).To(HaveKeyWithValue("processors", pipeline.Pipeline["processors"]))
+					Expect(actualPayload).To(HaveKey("_meta"))
+				})
+			})
+
+			When("the cluster doesn't support metadata on pipelines", func() {
+				var installedProcessors []interface{}
+
+				BeforeEach(func() {
+					installedProcessors = pipeline.Pipeline["processors"].([]interface{})
+					mockTransport.actions = []transportAction{
+						func(req *http.Request) (*http.Response, error) {
+							return clusterInfoResponse("7.14.2"), nil
+						},
+						func(req *http.Request) (*http.Response, error) {
+							return &http.Response{
+								StatusCode: http.StatusOK,
+								Body: createESBody(map[string]interface{}{
+									policyName: map[string]interface{}{
+										"processors": installedProcessors,
+									},
+								}),
+							}, nil
+						},
+					}
+					mockTransport.preparedHttpResponses = []*http.Response{
+						{StatusCode: http.StatusOK},
+					}
+				})
+
+				When("the pipeline matches", func() {
+					It("should not update the pipeline", func() {
+						Expect(actualError).NotTo(HaveOccurred())
+						Expect(mockTransport.receivedHttpRequests).To(HaveLen(2))
+					})
+				})
+
+				When("the pipeline was modified in the cluster", func() {
+					BeforeEach(func() {
+						installedProcessors = []interface{}{}
+					})
+
+					It("should restore the pipeline without metadata", func() {
+						Expect(actualError).NotTo(HaveOccurred())
+						Expect(mockTransport.receivedHttpRequests).To(HaveLen(3))
+						Expect(mockTransport.receivedHttpRequests[2].Method).To(Equal(http.MethodPut))
+
+						actualPayload := map[string]interface{}{}
+						readRequestBody(mockTransport.receivedHttpRequests[2], &actualPayload)
+						Expect(actualPayload).To(Equal(map[string]interface{}{
+							"processors": pipeline.Pipeline["processors"],
+						}))
+					})
+				})
+			})
+		})
+
+		When("there is a stored script", func() {
+			BeforeEach(func() {
+				mockRegistry.StoredScriptsReturns(map[string]*StoredScript{
+					"score": {Lang: "painless", Source: "return 1;"},
+				})
+				mockTransport.preparedHttpResponses = []*http.Response{
+					{StatusCode: http.StatusNotFound},
+					{StatusCode: http.StatusOK},
+				}
+			})
+
+			It("should install the script", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(mockTransport.receivedHttpRequests).To(HaveLen(2))
+				Expect(mockTransport.receivedHttpRequests[1].Method).To(Equal(http.MethodPut))
+				Expect(mockTransport.receivedHttpRequests[1].URL.Path).To(Equal("/_scripts/" + policyName))
+
+				actualPayload := map[string]interface{}{}
+				readRequestBody(mockTransport.receivedHttpRequests[1], &actualPayload)
+				Expect(actualPayload).To(Equal(map[string]interface{}{
+					"script": map[string]interface{}{"lang": "painless", "source": "return 1;"},
+				}))
+			})
+		})
+	})
+
+	Context("ResourceDrift", func() {
+		var (
+			actualDrift []*ResourceDrift
+			actualError error
+		)
+
+		BeforeEach(func() {
+			mockRegistry.ILMPoliciesReturns(map[string]*VersionedPolicy{"retention": policy})
+			mockRegistry.StoredScriptsReturns(map[string]*StoredScript{
+				"score": {Lang: "painless", Source: "return 1;"},
+			})
+			mockTransport.preparedHttpResponses = []*http.Response{
+				clusterInfoResponse("7.14.0"),
+				{
+					StatusCode: http.StatusOK,
+					Body: createESBody(map[string]interface{}{
+						policyName: map[string]interface{}{
+							"policy": map[string]interface{}{
+								"_meta": map[string]interface{}{"version": "v1"},
+							},
+						},
+					}),
+				},
+				{
+					StatusCode: http.StatusOK,
+					Body: createESBody(map[string]interface{}{
+						"_id":    policyName,
+						"found":  true,
+						"script": map[string]interface{}{"lang": "painless", "source": "return 2;"},
+					}),
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			actualDrift, actualError = manager.ResourceDrift(ctx)
+		})
+
+		It("should report the differences without changing anything", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+			Expect(mockTransport.receivedHttpRequests).To(HaveLen(3))
+			Expect(actualDrift).To(Equal([]*ResourceDrift{
+				{
+					Type:             ResourceTypeILMPolicy,
+					Name:             policyName,
+					Status:           ResourceDriftOutdated,
+					Version:          "v2",
+					InstalledVersion: "v1",
+				},
+				{
+					Type:   ResourceTypeStoredScript,
+					Name:   policyName,
+					Status: ResourceDriftModified,
+				},
+			}))
+		})
+	})
+})
+
+func clusterInfoResponse(version string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body: createESBody(map[string]interface{}{
+			"version": map[string]interface{}{"number": version},
+		}),
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"go.uber.org/zap"
+)
+
+// staticSettings can only be set when an index is created (or, for analysis, while it's closed), so changing them
+// requires a migration. Entries ending in "." cover every setting under them.
+var staticSettings = []string{
+	"index.number_of_shards",
+	"index.number_of_routing_shards",
+	"index.routing_partition_size",
+	"index.codec",
+	"index.soft_deletes.enabled",
+	"index.load_fixed_bitset_filters_eagerly",
+	"index.shard.check_on_startup",
+	"index.analysis.",
+	"index.similarity.",
+	"index.sort.",
+	"index.store.",
+}
+
+//counterfeiter:generate -o ../mocks . SettingsManager
+type SettingsManager interface {
+	// GetSettingsChanges compares the settings in the registry with the settings of the indices at the current
+	// version, and returns the ones that differ. Each change is marked as dynamic or static.
+	GetSettingsChanges(ctx context.Context) ([]*SettingsChange, error)
+	// UpdateSettings applies the dynamic settings changes to the live indices, without a migration. Static changes
+	// are only logged, since they need a version bump. All of the changes are returned.
+	UpdateSettings(ctx context.Context) ([]*SettingsChange, error)
+}
+
+type settingsManager struct {
+	client   *elasticsearch.Client
+	config   *Config
+	logger   *zap.Logger
+	registry MappingsRegistry
+}
+
+func NewSettingsManager(logger *zap.Logger, client *elasticsearch.Client, registry MappingsRegistry, config *Config) SettingsManager {
+	return &settingsManager{
+		client,
+		config,
+		logger,
+		registry,
+	}
+}
+
+// IsDynamicSetting returns true if the index setting can be changed on a live index. Settings can be given with or
+// without the "index." prefix.
+func IsDynamicSetting(setting string) bool {
+	setting = normalizeSettingName(setting)
+	for _, static := range staticSettings {
+		if setting == static || (strings.HasSuffix(static, ".") && strings.HasPrefix(setting, static)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *settingsManager) GetSettingsChanges(ctx context.Context) ([]*SettingsChange, error) {
+	desiredSettings, err := s.desiredSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(desiredSettings) == 0 {
+		return nil, nil
+	}
+
+	var indexNames []string
+	for indexName := range desiredSettings {
+		indexNames = append(indexNames, indexName)
+	}
+	sort.Strings(indexNames)
+
+	res, err := s.client.Indices.GetSettings(
+		s.client.Indices.GetSettings.WithContext(ctx),
+		s.client.Indices.GetSettings.WithIndex(indexNames...),
+		s.client.Indices.GetSettings.WithFlatSettings(true),
+	)
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return nil, fmt.Errorf("error getting index settings: %s", err)
+	}
+
+	liveSettings := map[string]struct {
+		Settings map[string]interface{} `json:"settings"`
+	}{}
+	if err := decodeResponse(res.Body, &liveSettings); err != nil {
+		return nil, fmt.Errorf("error decoding index settings: %s", err)
+	}
+
+	var changes []*SettingsChange
+	for _, indexName := range indexNames {
+		desired := desiredSettings[indexName]
+		var settingNames []string
+		for setting := range desired {
+			settingNames = append(settingNames, setting)
+		}
+		sort.Strings(settingNames)
+
+		current := liveSettings[indexName].Settings
+		for _, setting := range settingNames {
+			if reflect.DeepEqual(current[setting], desired[setting]) {
+				continue
+			}
+
+			changes = append(changes, &SettingsChange{
+				Index:   indexName,
+				Setting: setting,
+				Current: current[setting],
+				Desired: desired[setting],
+				Dynamic: IsDynamicSetting(setting),
+			})
+		}
+	}
+
+	return changes, nil
+}
+
+func (s *settingsManager) UpdateSettings(ctx context.Context) ([]*SettingsChange, error) {
+	log := s.logger.Named("UpdateSettings")
+	changes, err := s.GetSettingsChanges(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]map[string]interface{}{}
+	var indexNames []string
+	for _, change := range changes {
+		changeLog := log.With(
+			zap.String("index", change.Index),
+			zap.String("setting", change.Setting),
+			zap.Any("current", change.Current),
+			zap.Any("desired", change.Desired),
+		)
+		if !change.Dynamic {
+			changeLog.Warn("Static setting differs from the registry, bump the mapping version to migrate the index")
+			continue
+		}
+
+		if _, ok := updates[change.Index]; !ok {
+			updates[change.Index] = map[string]interface{}{}
+			indexNames = append(indexNames, change.Index)
+		}
+		updates[change.Index][change.Setting] = change.Desired
+		changeLog.Info("Updating dynamic setting")
+	}
+
+	for _, indexName := range indexNames {
+		body, _ := encodeRequest(updates[indexName])
+		res, err := s.client.Indices.PutSettings(
+			body,
+			s.client.Indices.PutSettings.WithContext(ctx),
+			s.client.Indices.PutSettings.WithIndex(indexName),
+		)
+		if err := getErrorFromESResponse(res, err); err != nil {
+			return changes, fmt.Errorf("error updating settings for index %s: %s", indexName, err)
+		}
+	}
+
+	return changes, nil
+}
+
+// desiredSettings returns the flattened settings from the registry for each index at the current version, keyed by
+// the index name. Older indices are left to the migration.
+func (s *settingsManager) desiredSettings(ctx context.Context) (map[string]map[string]interface{}, error) {
+	indices, err := currentIndices(ctx, s.client, s.registry, s.config.IndexPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	desiredSettings := map[string]map[string]interface{}{}
+	for indexName, indexParts := range indices {
+		mapping := s.registry.Mapping(indexParts.DocumentKind)
+		rolloverAlias := ""
+		if mapping.TimeSeries != nil {
+			rolloverAlias = s.registry.AliasName(indexParts.DocumentKind, indexParts.Inner)
+		}
+
+		settings := map[string]interface{}{}
+		flattenSettings("", indexSettings(s.registry, mapping, rolloverAlias), settings)
+		if len(settings) > 0 {
+			desiredSettings[indexName] = settings
+		}
+	}
+
+	return desiredSettings, nil
+}
+
+// currentIndices returns the application's indices that are at the version in the registry, along with their parsed names.
+func currentIndices(ctx context.Context, client *elasticsearch.Client, registry MappingsRegistry, indexPrefix string) (map[string]*IndexName, error) {
+	applicationIndices, err := getApplicationIndices(ctx, client, indexPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("error finding indices: %s", err)
+	}
+
+	indices := map[string]*IndexName{}
+	for indexName := range applicationIndices {
+		indexParts := registry.ParseIndexName(indexName)
+		if indexParts == nil {
+			continue
+		}
+
+		mapping := registry.Mapping(indexParts.DocumentKind)
+		if mapping == nil || mapping.Version != indexParts.Version {
+			continue
+		}
+
+		indices[indexName] = indexParts
+	}
+
+	return indices, nil
+}
+
+// flattenSettings converts settings to the form returned by Elasticsearch with flat_settings, so that they can be
+// compared: every key starts with "index.", and every value is a string or a list of strings.
+func flattenSettings(prefix string, settings map[string]interface{}, flattened map[string]interface{}) {
+	for key, value := range settings {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenSettings(name, nested, flattened)
+			continue
+		}
+
+		flattened[normalizeSettingName(name)] = settingValue(value)
+	}
+}
+
+func normalizeSettingName(setting string) string {
+	if strings.HasPrefix(setting, "index.") {
+		return setting
+	}
+
+	return "index." + setting
+}
+
+func settingValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		// numbers decoded from the mapping file would otherwise be formatted with an exponent
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i := range v {
+			values[i] = settingValue(v[i])
+		}
+		return values
+	case []string:
+		values := make([]interface{}, len(v))
+		for i := range v {
+			values[i] = v[i]
+		}
+		return values
+	default:
+		return fmt.Sprint(v)
+	}
+}
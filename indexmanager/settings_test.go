@@ -0,0 +1,273 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	. "github.com/rode/es-index-manager/indexmanager"
+	"github.com/rode/es-index-manager/mocks"
+)
+
+var _ = Describe("SettingsManager", func() {
+	var (
+		ctx           = context.Background()
+		config        *Config
+		mockTransport *mockEsTransport
+		mockRegistry  *mocks.FakeMappingsRegistry
+		manager       SettingsManager
+
+		indexPrefix  string
+		documentKind string
+		currentIndex string
+		olderIndex   string
+		mapping      *VersionedMapping
+		liveSettings map[string]interface{}
+
+		settingsResponse *http.Response
+	)
+
+	BeforeEach(func() {
+		indexPrefix = fake.Word()
+		documentKind = fake.Word()
+		config = &Config{IndexPrefix: indexPrefix}
+		mockTransport = &mockEsTransport{}
+		mockEsClient := &elasticsearch.Client{Transport: mockTransport, API: esapi.New(mockTransport)}
+		mockRegistry = &mocks.FakeMappingsRegistry{}
+
+		currentIndex = createIndexOrAliasName(indexPrefix, "v2", documentKind)
+		olderIndex = createIndexOrAliasName(indexPrefix, "v1", documentKind)
+		mapping = &VersionedMapping{
+			Version: "v2",
+			Settings: map[string]interface{}{
+				"number_of_replicas": 2,
+				"index": map[string]interface{}{
+					"refresh_interval": "30s",
+					"number_of_shards": 3,
+				},
+			},
+		}
+		liveSettings = map[string]interface{}{
+			"index.number_of_replicas": "1",
+			"index.number_of_shards":   "1",
+			"index.refresh_interval":   "30s",
+			"index.uuid":               fake.UUID(),
+		}
+
+		mockRegistry.MappingReturns(mapping)
+		mockRegistry.ParseIndexNameStub = func(indexName string) *IndexName {
+			version := "v2"
+			if indexName == olderIndex {
+				version = "v1"
+			}
+
+			return &IndexName{DocumentKind: documentKind, Version: version}
+		}
+
+		settingsResponse = &http.Response{StatusCode: http.StatusOK}
+		mockTransport.preparedHttpResponses = []*http.Response{
+			// get all indices
+			{
+				StatusCode: http.StatusOK,
+				Body: createESBody(map[string]interface{}{
+					currentIndex: managedIndexResponse(indexPrefix, nil, false),
+					olderIndex:   managedIndexResponse(indexPrefix, nil, false),
+				}),
+			},
+			settingsResponse,
+			// put settings
+			{StatusCode: http.StatusOK},
+		}
+
+		manager = NewSettingsManager(logger, mockEsClient, mockRegistry, config)
+	})
+
+	JustBeforeEach(func() {
+		// the body is built last, so that tests can change the live settings
+		settingsResponse.Body = createESBody(map[string]interface{}{
+			currentIndex: map[string]interface{}{"settings": liveSettings},
+		})
+	})
+
+	Context("GetSettingsChanges", func() {
+		var (
+			actualChanges []*SettingsChange
+			actualError   error
+		)
+
+		JustBeforeEach(func() {
+			actualChanges, actualError = manager.GetSettingsChanges(ctx)
+		})
+
+		It("should only request the settings of indices at the current version", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+			Expect(mockTransport.receivedHttpRequests[1].URL.Path).To(Equal(fmt.Sprintf("/%s/_settings", currentIndex)))
+			Expect(mockTransport.receivedHttpRequests[1].URL.Query().Get("flat_settings")).To(Equal("true"))
+		})
+
+		It("should classify the settings that differ", func() {
+			Expect(actualChanges).To(Equal([]*SettingsChange{
+				{
+					Index:   currentIndex,
+					Setting: "index.number_of_replicas",
+					Current: "1",
+					Desired: "2",
+					Dynamic: true,
+				},
+				{
+					Index:   currentIndex,
+					Setting: "index.number_of_shards",
+					Current: "1",
+					Desired: "3",
+					Dynamic: false,
+				},
+			}))
+		})
+
+		When("a setting isn't set on the index", func() {
+			BeforeEach(func() {
+				delete(liveSettings, "index.refresh_interval")
+			})
+
+			It("should be reported without a current value", func() {
+				Expect(actualChanges).To(ContainElement(&SettingsChange{
+					Index:   currentIndex,
+					Setting: "index.refresh_interval",
+					Desired: "30s",
+					Dynamic: true,
+				}))
+			})
+		})
+
+		When("the document kind uses an ILM policy", func() {
+			BeforeEach(func() {
+				mapping.Settings = nil
+				mapping.ILMPolicy = "retention"
+				mockRegistry.ResourceNameReturns(indexPrefix + "-retention")
+			})
+
+			It("should include the policy setting", func() {
+				Expect(actualChanges).To(ConsistOf(&SettingsChange{
+					Index:   currentIndex,
+					Setting: "index.lifecycle.name",
+					Desired: indexPrefix + "-retention",
+					Dynamic: true,
+				}))
+			})
+		})
+
+		When("the mapping has no settings", func() {
+			BeforeEach(func() {
+				mapping.Settings = nil
+			})
+
+			It("should not request the index settings", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(actualChanges).To(BeEmpty())
+				Expect(mockTransport.receivedHttpRequests).To(HaveLen(1))
+			})
+		})
+
+		When("getting the settings fails", func() {
+			BeforeEach(func() {
+				settingsResponse.StatusCode = http.StatusInternalServerError
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(actualError.Error()).To(ContainSubstring("error getting index settings"))
+			})
+		})
+
+		When("fetching the indices fails", func() {
+			BeforeEach(func() {
+				mockTransport.preparedHttpResponses[0] = &http.Response{StatusCode: http.StatusInternalServerError}
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(actualError.Error()).To(ContainSubstring("error finding indices"))
+			})
+		})
+	})
+
+	Context("UpdateSettings", func() {
+		var (
+			actualChanges []*SettingsChange
+			actualError   error
+		)
+
+		JustBeforeEach(func() {
+			actualChanges, actualError = manager.UpdateSettings(ctx)
+		})
+
+		It("should only apply the dynamic settings", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+			Expect(actualChanges).To(HaveLen(2))
+			Expect(mockTransport.receivedHttpRequests).To(HaveLen(3))
+			Expect(mockTransport.receivedHttpRequests[2].Method).To(Equal(http.MethodPut))
+			Expect(mockTransport.receivedHttpRequests[2].URL.Path).To(Equal(fmt.Sprintf("/%s/_settings", currentIndex)))
+
+			actualPayload := map[string]interface{}{}
+			readRequestBody(mockTransport.receivedHttpRequests[2], &actualPayload)
+			Expect(actualPayload).To(Equal(map[string]interface{}{
+				"index.number_of_replicas": "2",
+			}))
+		})
+
+		When("only static settings differ", func() {
+			BeforeEach(func() {
+				liveSettings["index.number_of_replicas"] = "2"
+			})
+
+			It("should not update the index", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(actualChanges).To(HaveLen(1))
+				Expect(mockTransport.receivedHttpRequests).To(HaveLen(2))
+			})
+		})
+
+		When("updating the settings fails", func() {
+			BeforeEach(func() {
+				mockTransport.preparedHttpResponses[2].StatusCode = http.StatusBadRequest
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(actualError.Error()).To(ContainSubstring("error updating settings for index"))
+			})
+		})
+	})
+
+	table.DescribeTable("IsDynamicSetting",
+		func(setting string, expected bool) {
+			Expect(IsDynamicSetting(setting)).To(Equal(expected))
+		},
+		table.Entry("replicas", "number_of_replicas", true),
+		table.Entry("refresh interval", "index.refresh_interval", true),
+		table.Entry("ILM policy", "index.lifecycle.name", true),
+		table.Entry("shards", "index.number_of_shards", false),
+		table.Entry("analyzer", "analysis.analyzer.folding.tokenizer", false),
+		table.Entry("codec", "index.codec", false),
+		table.Entry("setting sharing a static prefix", "index.number_of_shards_extra", true),
+	)
+})
@@ -0,0 +1,186 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/rode/es-index-manager/indexmanager/internal"
+	"go.uber.org/zap"
+)
+
+// snapshotTimeFormat is used in snapshot names, which must be lowercase.
+const snapshotTimeFormat = "20060102t150405z"
+
+// snapshot backs up the data for a migration to the repository in MigrationConfig.Snapshot, and records the name
+// of the snapshot in the migration. It waits for the snapshot to finish.
+func (m *migrator) snapshot(ctx context.Context, log *zap.Logger, migration *Migration, mapping *VersionedMapping) error {
+	// the source of a time series or data stream migration is only the write index, so back up the entire series
+	indices := migration.SourceIndex
+	if mapping != nil && (mapping.TimeSeries != nil || mapping.DataStream != nil) {
+		indices = migration.Alias
+	}
+
+	// merged document kinds are migrated through the same alias, but each of their migrations has its own source index
+	snapshotName := fmt.Sprintf("%s-%s", migration.SourceIndex, time.Now().UTC().Format(snapshotTimeFormat))
+	log = log.With(zap.String("snapshot", snapshotName), zap.String("repository", m.config.Migration.Snapshot.Repository))
+
+	log.Info("Taking snapshot before migrating")
+	body, _ := encodeRequest(&EsSnapshotRequest{Indices: indices})
+	res, err := m.client.Snapshot.Create(
+		m.config.Migration.Snapshot.Repository,
+		snapshotName,
+		m.client.Snapshot.Create.WithContext(ctx),
+		m.client.Snapshot.Create.WithBody(body),
+		m.client.Snapshot.Create.WithWaitForCompletion(true),
+	)
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return fmt.Errorf("error taking snapshot %s: %s", snapshotName, err)
+	}
+
+	snapshotResponse := &EsSnapshotResponse{}
+	if err := decodeResponse(res.Body, snapshotResponse); err != nil {
+		return fmt.Errorf("error decoding snapshot response: %s", err)
+	}
+
+	if snapshotResponse.Snapshot == nil || snapshotResponse.Snapshot.State != ElasticsearchSnapshotSuccess {
+		state := ""
+		if snapshotResponse.Snapshot != nil {
+			state = snapshotResponse.Snapshot.State
+		}
+
+		return fmt.Errorf("snapshot %s didn't succeed, state: %s", snapshotName, state)
+	}
+
+	migration.Snapshot = snapshotName
+	log.Info("Snapshot complete")
+
+	return nil
+}
+
+func (m *migrator) RestoreSnapshot(ctx context.Context, migration *Migration, options *RestoreOptions) error {
+	if options == nil {
+		options = &RestoreOptions{}
+	}
+
+	if migration.Snapshot == "" {
+		return errors.New("the migration doesn't have a snapshot")
+	}
+
+	if m.config.Migration.Snapshot == nil {
+		return errors.New("no snapshot repository is configured")
+	}
+
+	mapping := m.registry.Mapping(migration.DocumentKind)
+	if mapping != nil && (mapping.TimeSeries != nil || mapping.DataStream != nil) {
+		return fmt.Errorf("restoring document kind %s isn't supported, since it's a time series or data stream", migration.DocumentKind)
+	}
+
+	repository := m.config.Migration.Snapshot.Repository
+	log := m.logger.Named("RestoreSnapshot").With(
+		zap.String("snapshot", migration.Snapshot),
+		zap.String("repository", repository),
+		zap.String("index", migration.SourceIndex),
+	)
+
+	// the source index is normally deleted by the migration, but may still be around if the migration failed or set
+	// max_docs. It may hold writes made since the snapshot, so it's only replaced when asked to.
+	res, err := m.client.Indices.Exists([]string{migration.SourceIndex}, m.client.Indices.Exists.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("error checking if index %s exists: %s", migration.SourceIndex, err)
+	}
+
+	if res.StatusCode == http.StatusOK {
+		if !options.Overwrite {
+			return fmt.Errorf("index %s still exists, set RestoreOptions.Overwrite to replace it with the snapshot", migration.SourceIndex)
+		}
+
+		log.Warn("Deleting existing index before restoring")
+		res, err = m.client.Indices.Delete([]string{migration.SourceIndex}, m.client.Indices.Delete.WithContext(ctx))
+		if err := getErrorFromESResponse(res, err); err != nil {
+			return fmt.Errorf("error deleting index %s: %s", migration.SourceIndex, err)
+		}
+	} else if res.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("error checking if index %s exists, status: %d", migration.SourceIndex, res.StatusCode)
+	}
+
+	log.Info("Restoring index")
+	body, _ := encodeRequest(&EsRestoreRequest{Indices: migration.SourceIndex})
+	res, err = m.client.Snapshot.Restore(
+		repository,
+		migration.Snapshot,
+		m.client.Snapshot.Restore.WithContext(ctx),
+		m.client.Snapshot.Restore.WithBody(body),
+		m.client.Snapshot.Restore.WithWaitForCompletion(true),
+	)
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return fmt.Errorf("error restoring snapshot %s: %s", migration.Snapshot, err)
+	}
+
+	// the snapshot may have been taken after an interrupted migration placed a write block
+	settings, _ := encodeRequest(map[string]interface{}{"index.blocks.write": false})
+	res, err = m.client.Indices.PutSettings(
+		settings,
+		m.client.Indices.PutSettings.WithContext(ctx),
+		m.client.Indices.PutSettings.WithIndex(migration.SourceIndex),
+	)
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return fmt.Errorf("error removing write block from index %s: %s", migration.SourceIndex, err)
+	}
+
+	// a failed migration may not have moved the alias to the target index yet
+	aliasActions := []EsActions{
+		{
+			Add: &EsIndexAlias{
+				Index: migration.SourceIndex,
+				Alias: migration.Alias,
+			},
+		},
+	}
+	res, err = m.client.Indices.ExistsAlias(
+		[]string{migration.Alias},
+		m.client.Indices.ExistsAlias.WithContext(ctx),
+		m.client.Indices.ExistsAlias.WithIndex(migration.TargetIndex),
+	)
+	if err != nil {
+		return fmt.Errorf("error checking if alias %s exists: %s", migration.Alias, err)
+	}
+
+	if res.StatusCode == http.StatusOK {
+		aliasActions = append([]EsActions{
+			{
+				Remove: &EsIndexAlias{
+					Index: migration.TargetIndex,
+					Alias: migration.Alias,
+				},
+			},
+		}, aliasActions...)
+	}
+
+	log.Info("Moving alias back to restored index", zap.String("alias", migration.Alias))
+	aliasReq, _ := encodeRequest(&EsIndexAliasRequest{Actions: aliasActions})
+	res, err = m.client.Indices.UpdateAliases(aliasReq, m.client.Indices.UpdateAliases.WithContext(ctx))
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return fmt.Errorf("error moving alias %s to the restored index: %s", migration.Alias, err)
+	}
+
+	log.Info("Restore complete")
+
+	return nil
+}
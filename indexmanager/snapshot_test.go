@@ -0,0 +1,340 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/rode/es-index-manager/indexmanager"
+	"github.com/rode/es-index-manager/mocks"
+)
+
+var _ = Describe("Snapshots", func() {
+	var (
+		ctx           = context.Background()
+		config        *Config
+		mockTransport *mockEsTransport
+		mockRegistry  *mocks.FakeMappingsRegistry
+		mockRepo      *mocks.FakeIndexRepository
+		migrator      Migrator
+
+		repository string
+		migration  *Migration
+	)
+
+	BeforeEach(func() {
+		repository = fake.Word()
+		indexPrefix := fake.Word()
+		documentKind := fake.Word()
+		config = &Config{
+			IndexPrefix: indexPrefix,
+			Migration: &MigrationConfig{
+				PollAttempts: 1,
+				PollInterval: time.Second,
+				Snapshot:     &SnapshotConfig{Repository: repository},
+			},
+		}
+		mockTransport = &mockEsTransport{}
+		mockEsClient := &elasticsearch.Client{Transport: mockTransport, API: esapi.New(mockTransport)}
+		mockRegistry = &mocks.FakeMappingsRegistry{}
+		mockRepo = &mocks.FakeIndexRepository{}
+
+		migration = &Migration{
+			Alias:        createIndexOrAliasName(indexPrefix, documentKind),
+			SourceIndex:  createIndexOrAliasName(indexPrefix, "v1", documentKind),
+			TargetIndex:  createIndexOrAliasName(indexPrefix, "v2", documentKind),
+			DocumentKind: documentKind,
+		}
+		mockRegistry.MappingReturns(&VersionedMapping{Version: "v2"})
+
+		migrator = NewMigrator(logger, mockEsClient, mockRegistry, mockRepo, func(time.Duration) {}, config)
+	})
+
+	Context("Migrate", func() {
+		var (
+			snapshotState  string
+			snapshotAction transportAction
+			actualError    error
+		)
+
+		BeforeEach(func() {
+			snapshotState = "SUCCESS"
+			snapshotAction = func(req *http.Request) (*http.Response, error) {
+				name := strings.TrimPrefix(req.URL.Path, fmt.Sprintf("/_snapshot/%s/", repository))
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body: createESBody(map[string]interface{}{
+						"snapshot": map[string]interface{}{
+							"snapshot": name,
+							"state":    snapshotState,
+						},
+					}),
+				}, nil
+			}
+			mockTransport.actions = []transportAction{snapshotAction}
+			mockTransport.preparedHttpResponses = []*http.Response{
+				// stop the migration at the write block, since only the snapshot is under test
+				{StatusCode: http.StatusInternalServerError},
+			}
+		})
+
+		JustBeforeEach(func() {
+			actualError = migrator.Migrate(ctx, migration)
+		})
+
+		It("should take a snapshot of the source index before changing anything", func() {
+			Expect(mockTransport.receivedHttpRequests).To(HaveLen(2))
+			request := mockTransport.receivedHttpRequests[0]
+			Expect(request.Method).To(Equal(http.MethodPut))
+			Expect(request.URL.Path).To(HavePrefix(fmt.Sprintf("/_snapshot/%s/%s-", repository, migration.SourceIndex)))
+			Expect(request.URL.Query().Get("wait_for_completion")).To(Equal("true"))
+
+			actualPayload := map[string]interface{}{}
+			readRequestBody(request, &actualPayload)
+			Expect(actualPayload).To(Equal(map[string]interface{}{
+				"indices":              migration.SourceIndex,
+				"include_global_state": false,
+			}))
+		})
+
+		It("should record the snapshot in the migration", func() {
+			Expect(migration.Snapshot).To(HavePrefix(migration.SourceIndex + "-"))
+			Expect(mockTransport.receivedHttpRequests[0].URL.Path).To(HaveSuffix(migration.Snapshot))
+		})
+
+		When("another document kind is merged into the same alias", func() {
+			It("should take a snapshot with a different name", func() {
+				merged := &Migration{
+					Alias:        migration.Alias,
+					SourceIndex:  fake.Word(),
+					TargetIndex:  migration.TargetIndex,
+					DocumentKind: migration.DocumentKind,
+				}
+				mockTransport.actions = []transportAction{snapshotAction}
+				mockTransport.preparedHttpResponses = []*http.Response{{StatusCode: http.StatusInternalServerError}}
+				Expect(migrator.Migrate(ctx, merged)).NotTo(Succeed())
+
+				Expect(merged.Snapshot).To(HavePrefix(merged.SourceIndex + "-"))
+				Expect(merged.Snapshot).NotTo(Equal(migration.Snapshot))
+			})
+		})
+
+		When("the document kind is a time series", func() {
+			BeforeEach(func() {
+				mockRegistry.MappingReturns(&VersionedMapping{Version: "v2", TimeSeries: &TimeSeriesConfig{}})
+			})
+
+			It("should take a snapshot of every index in the series", func() {
+				actualPayload := map[string]interface{}{}
+				readRequestBody(mockTransport.receivedHttpRequests[0], &actualPayload)
+				Expect(actualPayload["indices"]).To(Equal(migration.Alias))
+			})
+		})
+
+		When("the snapshot doesn't succeed", func() {
+			BeforeEach(func() {
+				snapshotState = "PARTIAL"
+			})
+
+			It("should not migrate", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(actualError.Error()).To(ContainSubstring("state: PARTIAL"))
+				Expect(mockTransport.receivedHttpRequests).To(HaveLen(1))
+				Expect(migration.Snapshot).To(BeEmpty())
+			})
+		})
+
+		When("the snapshot request fails", func() {
+			BeforeEach(func() {
+				mockTransport.actions = nil
+			})
+
+			It("should not migrate", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(actualError.Error()).To(ContainSubstring("error taking snapshot"))
+				Expect(mockTransport.receivedHttpRequests).To(HaveLen(1))
+			})
+		})
+
+		When("snapshots aren't configured", func() {
+			BeforeEach(func() {
+				config.Migration.Snapshot = nil
+				mockTransport.actions = nil
+			})
+
+			It("should not take a snapshot", func() {
+				Expect(mockTransport.receivedHttpRequests[0].URL.Path).NotTo(HavePrefix("/_snapshot"))
+				Expect(migration.Snapshot).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("RestoreSnapshot", func() {
+		var (
+			actualError error
+			options     *RestoreOptions
+		)
+
+		BeforeEach(func() {
+			migration.Snapshot = fake.Word()
+			options = nil
+			mockTransport.preparedHttpResponses = []*http.Response{
+				// check if the source index exists
+				{StatusCode: http.StatusNotFound},
+				// restore
+				{StatusCode: http.StatusOK},
+				// remove the write block
+				{StatusCode: http.StatusOK},
+				// check the target's alias
+				{StatusCode: http.StatusOK},
+				// move the alias
+				{StatusCode: http.StatusOK},
+			}
+		})
+
+		JustBeforeEach(func() {
+			actualError = migrator.RestoreSnapshot(ctx, migration, options)
+		})
+
+		It("should restore the source index without its aliases", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+			Expect(mockTransport.receivedHttpRequests).To(HaveLen(5))
+			Expect(mockTransport.receivedHttpRequests[0].Method).To(Equal(http.MethodHead))
+			Expect(mockTransport.receivedHttpRequests[0].URL.Path).To(Equal("/" + migration.SourceIndex))
+
+			request := mockTransport.receivedHttpRequests[1]
+			Expect(request.Method).To(Equal(http.MethodPost))
+			Expect(request.URL.Path).To(Equal(fmt.Sprintf("/_snapshot/%s/%s/_restore", repository, migration.Snapshot)))
+			Expect(request.URL.Query().Get("wait_for_completion")).To(Equal("true"))
+
+			actualPayload := map[string]interface{}{}
+			readRequestBody(request, &actualPayload)
+			Expect(actualPayload).To(Equal(map[string]interface{}{
+				"indices":              migration.SourceIndex,
+				"include_global_state": false,
+				"include_aliases":      false,
+			}))
+		})
+
+		It("should remove any write block", func() {
+			Expect(mockTransport.receivedHttpRequests[2].URL.Path).To(Equal(fmt.Sprintf("/%s/_settings", migration.SourceIndex)))
+
+			actualPayload := map[string]interface{}{}
+			readRequestBody(mockTransport.receivedHttpRequests[2], &actualPayload)
+			Expect(actualPayload).To(Equal(map[string]interface{}{"index.blocks.write": false}))
+		})
+
+		It("should move the alias from the target index to the restored index", func() {
+			Expect(mockTransport.receivedHttpRequests[3].URL.Path).To(Equal(fmt.Sprintf("/%s/_alias/%s", migration.TargetIndex, migration.Alias)))
+
+			actualPayload := map[string]interface{}{}
+			readRequestBody(mockTransport.receivedHttpRequests[4], &actualPayload)
+			Expect(actualPayload).To(Equal(map[string]interface{}{
+				"actions": []interface{}{
+					map[string]interface{}{
+						"remove": map[string]interface{}{"index": migration.TargetIndex, "alias": migration.Alias},
+					},
+					map[string]interface{}{
+						"add": map[string]interface{}{"index": migration.SourceIndex, "alias": migration.Alias},
+					},
+				},
+			}))
+		})
+
+		When("the target index doesn't have the alias", func() {
+			BeforeEach(func() {
+				mockTransport.preparedHttpResponses[3].StatusCode = http.StatusNotFound
+			})
+
+			It("should only add the alias to the restored index", func() {
+				actualPayload := map[string]interface{}{}
+				readRequestBody(mockTransport.receivedHttpRequests[4], &actualPayload)
+				Expect(actualPayload["actions"]).To(HaveLen(1))
+			})
+		})
+
+		When("the source index still exists", func() {
+			BeforeEach(func() {
+				mockTransport.preparedHttpResponses[0].StatusCode = http.StatusOK
+			})
+
+			It("should return an error without changing anything", func() {
+				Expect(actualError).To(MatchError(ContainSubstring("RestoreOptions.Overwrite")))
+				Expect(mockTransport.receivedHttpRequests).To(HaveLen(1))
+			})
+
+			When("overwriting is allowed", func() {
+				BeforeEach(func() {
+					options = &RestoreOptions{Overwrite: true}
+					mockTransport.preparedHttpResponses = append([]*http.Response{
+						mockTransport.preparedHttpResponses[0],
+						// delete the source index
+						{StatusCode: http.StatusOK},
+					}, mockTransport.preparedHttpResponses[1:]...)
+				})
+
+				It("should delete the source index before restoring it", func() {
+					Expect(actualError).NotTo(HaveOccurred())
+					Expect(mockTransport.receivedHttpRequests).To(HaveLen(6))
+					Expect(mockTransport.receivedHttpRequests[1].Method).To(Equal(http.MethodDelete))
+					Expect(mockTransport.receivedHttpRequests[1].URL.Path).To(Equal("/" + migration.SourceIndex))
+					Expect(mockTransport.receivedHttpRequests[2].URL.Path).To(Equal(fmt.Sprintf("/_snapshot/%s/%s/_restore", repository, migration.Snapshot)))
+				})
+			})
+		})
+
+		When("the restore fails", func() {
+			BeforeEach(func() {
+				mockTransport.preparedHttpResponses[1].StatusCode = http.StatusInternalServerError
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(actualError.Error()).To(ContainSubstring("error restoring snapshot"))
+				Expect(mockTransport.receivedHttpRequests).To(HaveLen(2))
+			})
+		})
+
+		When("the migration doesn't have a snapshot", func() {
+			BeforeEach(func() {
+				migration.Snapshot = ""
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(mockTransport.receivedHttpRequests).To(BeEmpty())
+			})
+		})
+
+		When("the document kind is a data stream", func() {
+			BeforeEach(func() {
+				mockRegistry.MappingReturns(&VersionedMapping{Version: "v2", DataStream: &DataStreamConfig{}})
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(mockTransport.receivedHttpRequests).To(BeEmpty())
+			})
+		})
+	})
+})
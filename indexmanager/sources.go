@@ -0,0 +1,256 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultRemoteSourceTimeout = 30 * time.Second
+
+// MappingSource provides document kind mappings to the MappingsRegistry. The registry combines the mappings from every
+// source when LoadMappings is called.
+type MappingSource interface {
+	// Name describes the source in error messages, like "mapping files in mappings".
+	Name() string
+	// Mappings returns the mappings from the source, keyed by document kind.
+	Mappings() (map[string]*VersionedMapping, error)
+}
+
+type fileSystemSource struct {
+	name       string
+	filesystem fs.FS
+	path       string
+	optional   bool
+}
+
+// NewFileSystemSource reads a mapping from each JSON file in the directory, using the file name without the extension
+// as the document kind. This is how the registry reads Config.MappingsPath.
+func NewFileSystemSource(filesystem fs.FS, path string) MappingSource {
+	return &fileSystemSource{
+		name:       fmt.Sprintf("mapping files in %s", path),
+		filesystem: filesystem,
+		path:       path,
+	}
+}
+
+// NewEmbeddedSource reads mapping files from a directory in an embed.FS, so they're compiled into the application.
+func NewEmbeddedSource(files fs.FS, path string) MappingSource {
+	return &fileSystemSource{
+		name:       fmt.Sprintf("embedded mapping files in %s", path),
+		filesystem: files,
+		path:       path,
+	}
+}
+
+func (s *fileSystemSource) Name() string {
+	return s.name
+}
+
+func (s *fileSystemSource) Mappings() (map[string]*VersionedMapping, error) {
+	files, err := fs.ReadDir(s.filesystem, s.path)
+	if err != nil {
+		if s.optional && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf(`error finding mappings in directory: %s`, err)
+	}
+
+	mappings := map[string]*VersionedMapping{}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		documentKind := strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
+		versionedMappingJson, err := fs.ReadFile(s.filesystem, filepath.Join(s.path, file.Name()))
+
+		if err != nil {
+			return nil, fmt.Errorf(`error reading file: %s`, err)
+		}
+
+		mapping := &VersionedMapping{}
+		if err := json.Unmarshal(versionedMappingJson, mapping); err != nil {
+			return nil, fmt.Errorf(`invalid json in file "%s": %s`, file.Name(), err)
+		}
+
+		mappings[documentKind] = mapping
+	}
+
+	return mappings, nil
+}
+
+type structSource struct {
+	structs map[string]*DocumentStruct
+}
+
+// NewStructSource builds mappings from annotated Go structs, keyed by document kind. See MappingFromStruct for how
+// fields are mapped. The registry uses this for Config.DocumentStructs.
+func NewStructSource(structs map[string]*DocumentStruct) MappingSource {
+	return &structSource{structs}
+}
+
+func (s *structSource) Name() string {
+	return "document structs"
+}
+
+func (s *structSource) Mappings() (map[string]*VersionedMapping, error) {
+	mappings := map[string]*VersionedMapping{}
+	for documentKind, documentStruct := range s.structs {
+		mapping, err := MappingFromStruct(documentStruct.Version, documentStruct.Document)
+		if err != nil {
+			return nil, fmt.Errorf(`error building mapping for document kind "%s": %s`, documentKind, err)
+		}
+		mapping.Settings = documentStruct.Settings
+
+		mappings[documentKind] = mapping
+	}
+
+	return mappings, nil
+}
+
+// RegisteredSource holds mappings registered from Go code, typically in init functions.
+type RegisteredSource struct {
+	mu         sync.Mutex
+	mappings   map[string]*VersionedMapping
+	duplicates []string
+}
+
+func NewRegisteredSource() *RegisteredSource {
+	return &RegisteredSource{
+		mappings: map[string]*VersionedMapping{},
+	}
+}
+
+// Register adds the mapping for a document kind. Registering the same document kind twice causes LoadMappings to fail.
+func (s *RegisteredSource) Register(documentKind string, mapping *VersionedMapping) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.mappings[documentKind]; ok {
+		s.duplicates = append(s.duplicates, documentKind)
+	}
+
+	s.mappings[documentKind] = mapping
+}
+
+func (s *RegisteredSource) Name() string {
+	return "registered mappings"
+}
+
+func (s *RegisteredSource) Mappings() (map[string]*VersionedMapping, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.duplicates) != 0 {
+		return nil, fmt.Errorf(`document kind "%s" was registered more than once`, s.duplicates[0])
+	}
+
+	mappings := map[string]*VersionedMapping{}
+	for documentKind, mapping := range s.mappings {
+		mappings[documentKind] = mapping
+	}
+
+	return mappings, nil
+}
+
+//counterfeiter:generate -o ../mocks . RemoteMappingsClient
+type RemoteMappingsClient interface {
+	// FetchMappings retrieves the mappings from a configuration service, keyed by document kind.
+	FetchMappings(ctx context.Context) (map[string]*VersionedMapping, error)
+}
+
+type remoteSource struct {
+	name    string
+	client  RemoteMappingsClient
+	timeout time.Duration
+}
+
+// NewRemoteSource reads mappings from a configuration service using the client. LoadMappings fails if the service
+// doesn't respond within the timeout, which defaults to 30 seconds.
+func NewRemoteSource(name string, client RemoteMappingsClient, timeout time.Duration) MappingSource {
+	if timeout <= 0 {
+		timeout = defaultRemoteSourceTimeout
+	}
+
+	return &remoteSource{name, client, timeout}
+}
+
+func (s *remoteSource) Name() string {
+	return s.name
+}
+
+func (s *remoteSource) Mappings() (map[string]*VersionedMapping, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	mappings, err := s.client.FetchMappings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching mappings from %s: %s", s.name, err)
+	}
+
+	return mappings, nil
+}
+
+// StubMappingsClient is a RemoteMappingsClient that returns fixed mappings, to stand in for the configuration service
+// in local development and tests.
+type StubMappingsClient struct {
+	Mappings map[string]*VersionedMapping
+	Err      error
+}
+
+func (c *StubMappingsClient) FetchMappings(_ context.Context) (map[string]*VersionedMapping, error) {
+	return c.Mappings, c.Err
+}
+
+// combineMappingSources reads the mappings from every source, and returns an error naming both sources if a document
+// kind is defined more than once.
+func combineMappingSources(sources []MappingSource) (map[string]*VersionedMapping, error) {
+	mappings := map[string]*VersionedMapping{}
+	definedBy := map[string]string{}
+	for _, source := range sources {
+		sourceMappings, err := source.Mappings()
+		if err != nil {
+			return nil, err
+		}
+
+		documentKinds := make([]string, 0, len(sourceMappings))
+		for documentKind := range sourceMappings {
+			documentKinds = append(documentKinds, documentKind)
+		}
+		sort.Strings(documentKinds)
+
+		for _, documentKind := range documentKinds {
+			if existing, ok := definedBy[documentKind]; ok {
+				return nil, fmt.Errorf(`document kind "%s" is defined by both %s and %s`, documentKind, existing, source.Name())
+			}
+
+			mappings[documentKind] = sourceMappings[documentKind]
+			definedBy[documentKind] = source.Name()
+		}
+	}
+
+	return mappings, nil
+}
@@ -0,0 +1,209 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing/fstest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/rode/es-index-manager/indexmanager"
+	"github.com/rode/es-index-manager/mocks"
+)
+
+var _ = Describe("MappingSource", func() {
+	Context("NewEmbeddedSource", func() {
+		var (
+			files           fstest.MapFS
+			expectedMapping *VersionedMapping
+		)
+
+		BeforeEach(func() {
+			expectedMapping = createRandomMapping()
+			files = fstest.MapFS{
+				filepath.Join("mappings", "build.json"): mappingsFile(expectedMapping),
+			}
+		})
+
+		It("should read the mapping files", func() {
+			source := NewEmbeddedSource(files, "mappings")
+			actualMappings, err := source.Mappings()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(source.Name()).To(Equal("embedded mapping files in mappings"))
+			Expect(actualMappings).To(HaveLen(1))
+			Expect(actualMappings["build"].Version).To(Equal(expectedMapping.Version))
+		})
+
+		It("should return an error if the directory doesn't exist", func() {
+			_, err := NewEmbeddedSource(files, fake.Word()).Mappings()
+
+			Expect(err).To(MatchError(ContainSubstring("error finding mappings in directory")))
+		})
+	})
+
+	Context("RegisteredSource", func() {
+		var source *RegisteredSource
+
+		BeforeEach(func() {
+			source = NewRegisteredSource()
+		})
+
+		It("should return the registered mappings", func() {
+			mapping := createRandomMapping()
+			source.Register("build", mapping)
+
+			actualMappings, err := source.Mappings()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(actualMappings).To(Equal(map[string]*VersionedMapping{"build": mapping}))
+		})
+
+		It("should return an error if a document kind is registered twice", func() {
+			source.Register("build", createRandomMapping())
+			source.Register("build", createRandomMapping())
+
+			_, err := source.Mappings()
+
+			Expect(err).To(MatchError(`document kind "build" was registered more than once`))
+		})
+	})
+
+	Context("NewRemoteSource", func() {
+		var (
+			mockClient *mocks.FakeRemoteMappingsClient
+			source     MappingSource
+		)
+
+		BeforeEach(func() {
+			mockClient = &mocks.FakeRemoteMappingsClient{}
+			source = NewRemoteSource("config service", mockClient, time.Minute)
+		})
+
+		It("should fetch the mappings with a deadline", func() {
+			mapping := createRandomMapping()
+			mockClient.FetchMappingsReturns(map[string]*VersionedMapping{"build": mapping}, nil)
+
+			actualMappings, err := source.Mappings()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(actualMappings).To(Equal(map[string]*VersionedMapping{"build": mapping}))
+			_, hasDeadline := mockClient.FetchMappingsArgsForCall(0).Deadline()
+			Expect(hasDeadline).To(BeTrue())
+		})
+
+		It("should return an error naming the source when the fetch fails", func() {
+			mockClient.FetchMappingsReturns(nil, errors.New("unavailable"))
+
+			_, err := source.Mappings()
+
+			Expect(err).To(MatchError("error fetching mappings from config service: unavailable"))
+		})
+
+		It("should work with the stub client", func() {
+			mapping := createRandomMapping()
+			source = NewRemoteSource("stub", &StubMappingsClient{Mappings: map[string]*VersionedMapping{"build": mapping}}, 0)
+
+			actualMappings, err := source.Mappings()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(actualMappings["build"]).To(Equal(mapping))
+		})
+	})
+
+	Context("MappingsRegistry", func() {
+		var (
+			config     *Config
+			testFs     fstest.MapFS
+			registered *RegisteredSource
+
+			actualError error
+			registry    MappingsRegistry
+		)
+
+		BeforeEach(func() {
+			registered = NewRegisteredSource()
+			registered.Register("build", createRandomMapping())
+			testFs = fstest.MapFS{
+				filepath.Join("mappings", "policy.json"): mappingsFile(createRandomMapping()),
+			}
+			config = &Config{
+				IndexPrefix:  fake.Word(),
+				MappingsPath: "mappings",
+				MappingSources: []MappingSource{
+					registered,
+					NewRemoteSource("config service", &StubMappingsClient{
+						Mappings: map[string]*VersionedMapping{"event": createRandomMapping()},
+					}, 0),
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			registry = NewMappingsRegistry(config, testFs)
+			actualError = registry.LoadMappings()
+		})
+
+		It("should combine the mappings from every source", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+			Expect(registry.DocumentKinds()).To(Equal([]string{"build", "event", "policy"}))
+		})
+
+		When("two sources define the same document kind", func() {
+			BeforeEach(func() {
+				registered.Register("policy", createRandomMapping())
+			})
+
+			It("should return an error naming both sources", func() {
+				Expect(actualError).To(MatchError(`document kind "policy" is defined by both mapping files in mappings and registered mappings`))
+			})
+		})
+
+		When("the mappings directory doesn't exist", func() {
+			BeforeEach(func() {
+				testFs = fstest.MapFS{}
+			})
+
+			It("should use the other sources", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(registry.DocumentKinds()).To(Equal([]string{"build", "event"}))
+			})
+		})
+
+		When("the mappings path is empty", func() {
+			BeforeEach(func() {
+				config.MappingsPath = ""
+			})
+
+			It("should only use the other sources", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(registry.DocumentKinds()).To(Equal([]string{"build", "event"}))
+			})
+		})
+
+		When("a source fails", func() {
+			BeforeEach(func() {
+				config.MappingSources = append(config.MappingSources, NewRemoteSource("broken", &StubMappingsClient{Err: errors.New(fake.Word())}, 0))
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(MatchError(ContainSubstring("error fetching mappings from broken")))
+			})
+		})
+	})
+})
@@ -0,0 +1,214 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const esTagName = "es"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// MappingFromStruct builds a versioned mapping from a struct, or a pointer to one, using the es tag on each field to set
+// its mapping. The tag is a comma-separated list of options that are copied into the field mapping, like
+// `es:"type=text,analyzer=english"`; values that are JSON numbers or booleans are converted, so `es:"index=false"`
+// disables indexing. A tag of "-" skips the field.
+//
+// Fields are named using their json tag, and fields without a type are given one based on their Go type: strings are
+// keywords, integers are longs, floats are doubles, []byte is binary, time.Time is a date, and structs are objects with
+// their own properties. Slices have the mapping of their elements, except that a slice of structs can be tagged "type=nested".
+func MappingFromStruct(version string, document interface{}) (*VersionedMapping, error) {
+	documentType := reflect.TypeOf(document)
+	for documentType != nil && documentType.Kind() == reflect.Ptr {
+		documentType = documentType.Elem()
+	}
+
+	if documentType == nil || documentType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a struct, got %v", reflect.TypeOf(document))
+	}
+
+	properties, err := structProperties(documentType, map[reflect.Type]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &VersionedMapping{
+		Version: version,
+		Mappings: map[string]interface{}{
+			"properties": properties,
+		},
+	}, nil
+}
+
+// structProperties returns the mapping properties for the exported fields of the struct type. Fields of embedded
+// structs are promoted, following encoding/json.
+func structProperties(structType reflect.Type, visiting map[reflect.Type]bool) (map[string]interface{}, error) {
+	if visiting[structType] {
+		return nil, fmt.Errorf("type %s refers to itself", structType)
+	}
+	visiting[structType] = true
+	defer delete(visiting, structType)
+
+	properties := map[string]interface{}{}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag := field.Tag.Get(esTagName)
+		jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == "-" || jsonName == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if field.Anonymous && jsonName == "" && fieldType.Kind() == reflect.Struct {
+			embedded, err := structProperties(fieldType, visiting)
+			if err != nil {
+				return nil, err
+			}
+
+			for name, property := range embedded {
+				if _, ok := properties[name]; !ok {
+					properties[name] = property
+				}
+			}
+			continue
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := jsonName
+		if name == "" {
+			name = field.Name
+		}
+
+		property, err := fieldMapping(field.Type, tag, visiting)
+		if err != nil {
+			return nil, fmt.Errorf("error mapping field %s.%s: %s", structType.Name(), field.Name, err)
+		}
+
+		properties[name] = property
+	}
+
+	return properties, nil
+}
+
+// fieldMapping builds the mapping for a single field from its tag and Go type.
+func fieldMapping(fieldType reflect.Type, tag string, visiting map[reflect.Type]bool) (map[string]interface{}, error) {
+	property, err := parseESTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	esType, hasType := property["type"]
+	for fieldType.Kind() == reflect.Ptr || fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Array {
+		// encoding/json writes byte slices as base64 strings, rather than arrays of numbers
+		if fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() == reflect.Uint8 {
+			if !hasType {
+				property["type"] = "binary"
+			}
+
+			return property, nil
+		}
+
+		fieldType = fieldType.Elem()
+	}
+	if fieldType.Kind() == reflect.Struct && fieldType != timeType && (!hasType || esType == "object" || esType == "nested") {
+		properties, err := structProperties(fieldType, visiting)
+		if err != nil {
+			return nil, err
+		}
+
+		property["properties"] = properties
+		// objects are the default when a field has properties
+		if esType == "object" {
+			delete(property, "type")
+		}
+
+		return property, nil
+	}
+
+	if hasType {
+		return property, nil
+	}
+
+	switch fieldType.Kind() {
+	case reflect.String:
+		property["type"] = "keyword"
+	case reflect.Bool:
+		property["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		property["type"] = "long"
+	case reflect.Uint64:
+		property["type"] = "unsigned_long"
+	case reflect.Float32, reflect.Float64:
+		property["type"] = "double"
+	case reflect.Map, reflect.Interface:
+		property["type"] = "object"
+	case reflect.Struct:
+		property["type"] = "date"
+	default:
+		return nil, fmt.Errorf("unable to determine a type for %s, add an es tag with the type", fieldType)
+	}
+
+	return property, nil
+}
+
+// parseESTag reads the key=value options in an es tag.
+func parseESTag(tag string) (map[string]interface{}, error) {
+	options := map[string]interface{}{}
+	if tag == "" {
+		return options, nil
+	}
+
+	for _, option := range strings.Split(tag, ",") {
+		parts := strings.SplitN(option, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || key == "" {
+			return nil, fmt.Errorf(`invalid option "%s" in es tag, expected key=value`, option)
+		}
+
+		value := strings.TrimSpace(parts[1])
+		var converted interface{}
+		if err := json.Unmarshal([]byte(value), &converted); err == nil {
+			switch converted.(type) {
+			case bool, float64:
+				options[key] = converted
+				continue
+			}
+		}
+
+		options[key] = value
+	}
+
+	if esType, ok := options["type"]; ok {
+		if _, ok := esType.(string); !ok {
+			return nil, errors.New("the type in an es tag must be a string")
+		}
+	}
+
+	return options, nil
+}
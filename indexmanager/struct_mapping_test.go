@@ -0,0 +1,136 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/rode/es-index-manager/indexmanager"
+)
+
+type testAudit struct {
+	CreatedBy string `json:"createdBy"`
+}
+
+type testStep struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+}
+
+type testBuild struct {
+	testAudit
+	ID        string                 `json:"id" es:"type=keyword"`
+	Message   string                 `json:"message" es:"type=text,analyzer=english"`
+	Artifact  string                 `json:"artifact" es:"type=keyword,index=false,ignore_above=256"`
+	Timestamp time.Time              `json:"@timestamp"`
+	Duration  *int64                 `json:"duration,omitempty"`
+	Score     float64                `json:"score"`
+	Tags      []string               `json:"tags"`
+	Checksum  []byte                 `json:"checksum"`
+	Labels    map[string]interface{} `json:"labels" es:"type=flattened"`
+	Steps     []testStep             `json:"steps" es:"type=nested"`
+	Source    *struct {
+		URL string `json:"url"`
+	} `json:"source"`
+	Ignored  string `json:"-"`
+	Skipped  string `es:"-"`
+	Untagged string
+	internal string
+}
+
+var _ = Describe("MappingFromStruct", func() {
+	var (
+		document interface{}
+
+		actualMapping *VersionedMapping
+		actualError   error
+	)
+
+	BeforeEach(func() {
+		document = &testBuild{}
+	})
+
+	JustBeforeEach(func() {
+		actualMapping, actualError = MappingFromStruct("v3", document)
+	})
+
+	It("should build the mapping from the tags and field types", func() {
+		Expect(actualError).NotTo(HaveOccurred())
+		Expect(actualMapping.Version).To(Equal("v3"))
+		Expect(actualMapping.Mappings).To(Equal(map[string]interface{}{
+			"properties": map[string]interface{}{
+				"createdBy":  map[string]interface{}{"type": "keyword"},
+				"id":         map[string]interface{}{"type": "keyword"},
+				"message":    map[string]interface{}{"type": "text", "analyzer": "english"},
+				"artifact":   map[string]interface{}{"type": "keyword", "index": false, "ignore_above": float64(256)},
+				"@timestamp": map[string]interface{}{"type": "date"},
+				"duration":   map[string]interface{}{"type": "long"},
+				"score":      map[string]interface{}{"type": "double"},
+				"tags":       map[string]interface{}{"type": "keyword"},
+				"checksum":   map[string]interface{}{"type": "binary"},
+				"labels":     map[string]interface{}{"type": "flattened"},
+				"steps": map[string]interface{}{
+					"type": "nested",
+					"properties": map[string]interface{}{
+						"name":   map[string]interface{}{"type": "keyword"},
+						"passed": map[string]interface{}{"type": "boolean"},
+					},
+				},
+				"source": map[string]interface{}{
+					"properties": map[string]interface{}{
+						"url": map[string]interface{}{"type": "keyword"},
+					},
+				},
+				"Untagged": map[string]interface{}{"type": "keyword"},
+			},
+		}))
+	})
+
+	When("the document isn't a struct", func() {
+		BeforeEach(func() {
+			document = map[string]interface{}{}
+		})
+
+		It("should return an error", func() {
+			Expect(actualError).To(MatchError(ContainSubstring("expected a struct")))
+		})
+	})
+
+	When("a tag is invalid", func() {
+		BeforeEach(func() {
+			document = &struct {
+				Name string `es:"keyword"`
+			}{}
+		})
+
+		It("should return an error", func() {
+			Expect(actualError).To(MatchError(ContainSubstring(`invalid option "keyword"`)))
+		})
+	})
+
+	When("the type of a field can't be determined", func() {
+		BeforeEach(func() {
+			document = &struct {
+				Callback func()
+			}{}
+		})
+
+		It("should return an error", func() {
+			Expect(actualError).To(MatchError(ContainSubstring("add an es tag with the type")))
+		})
+	})
+})
@@ -17,14 +17,18 @@ package indexmanager_test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	"github.com/onsi/gomega/types"
 	. "github.com/rode/es-index-manager/indexmanager"
 
 	"github.com/brianvoe/gofakeit/v6"
@@ -72,13 +76,48 @@ func (m *mockEsTransport) Perform(req *http.Request) (*http.Response, error) {
 
 func createRandomMapping() *VersionedMapping {
 	return &VersionedMapping{
-		Version: fake.Word(),
+		Version: randomVersion(),
 		Mappings: map[string]interface{}{
 			fake.Word(): fake.Word(),
 		},
 	}
 }
 
+// randomVersion returns a Kubernetes-style version, like v12.
+func randomVersion() string {
+	return fmt.Sprintf("v%d", fake.Number(1, 100))
+}
+
+// matchIndexMappings matches the mappings sent when creating an index, which have the version, checksum, and document
+// kind added to the _meta.
+func matchIndexMappings(mapping *VersionedMapping, documentKind string) types.GomegaMatcher {
+	keys := Keys{}
+	for k, v := range mapping.Mappings {
+		keys[k] = Equal(v)
+	}
+
+	meta := Keys{
+		"version":      Equal(mapping.Version),
+		"checksum":     Equal(MappingChecksum(mapping)),
+		"documentKind": Equal(documentKind),
+	}
+	if existing, ok := mapping.Mappings["_meta"].(map[string]interface{}); ok {
+		for k, v := range existing {
+			meta[k] = Equal(v)
+		}
+	}
+	keys["_meta"] = MatchKeys(IgnoreExtras, meta)
+
+	return MatchAllKeys(keys)
+}
+
+func parseTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	Expect(err).NotTo(HaveOccurred())
+
+	return t
+}
+
 func createIndexOrAliasName(parts ...string) string {
 	return strings.Join(parts, "-")
 }
@@ -110,5 +149,9 @@ func createInvalidBody() io.ReadCloser {
 }
 
 func insertResponseAt(allResponses []*http.Response, response *http.Response, index int) []*http.Response {
-	return append(append(allResponses[:index], response), allResponses[index:]...)
+	responses := make([]*http.Response, 0, len(allResponses)+1)
+	responses = append(responses, allResponses[:index]...)
+	responses = append(responses, response)
+
+	return append(responses, allResponses[index:]...)
 }
@@ -0,0 +1,292 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	. "github.com/rode/es-index-manager/indexmanager/internal"
+	"go.uber.org/zap"
+)
+
+func timeSeriesDate(series *TimeSeriesConfig, now time.Time) string {
+	if series.DateFormat == "" {
+		return ""
+	}
+
+	return now.Format(series.DateFormat)
+}
+
+// timeSeriesWriteIndex returns the index that receives writes through the alias. An alias that only points to one
+// index doesn't need to be flagged as the write index.
+func timeSeriesWriteIndex(indices []*managedIndex, alias string) *managedIndex {
+	var aliased []*managedIndex
+	for _, index := range indices {
+		if index.isWriteIndex(alias) {
+			return index
+		}
+
+		if index.hasAlias(alias) {
+			aliased = append(aliased, index)
+		}
+	}
+
+	if len(aliased) == 1 {
+		return aliased[0]
+	}
+
+	return nil
+}
+
+// getAliasIndices returns the indices that the alias points to.
+func getAliasIndices(ctx context.Context, client *elasticsearch.Client, alias string) (map[string]EsIndex, error) {
+	res, err := client.Indices.GetAlias(
+		client.Indices.GetAlias.WithContext(ctx),
+		client.Indices.GetAlias.WithName(alias),
+	)
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return nil, fmt.Errorf("error getting indices for alias %s: %s", alias, err)
+	}
+
+	aliasResponse := map[string]EsIndex{}
+	if err := decodeResponse(res.Body, &aliasResponse); err != nil {
+		return nil, fmt.Errorf("error decoding alias response: %s", err)
+	}
+
+	return aliasResponse, nil
+}
+
+// rollover creates newIndex with the mappings and settings, and makes it the write index for the alias, if any of the
+// conditions are met.
+func rollover(ctx context.Context, client *elasticsearch.Client, alias, newIndex string, conditions *RolloverConditions, mappings, settings map[string]interface{}) (*RolloverResult, error) {
+	rolloverReq := &EsRolloverRequest{
+		Mappings: mappings,
+		Settings: settings,
+	}
+	if conditions != nil {
+		rolloverReq.Conditions = &EsRolloverConditions{
+			MaxAge:  conditions.MaxAge,
+			MaxSize: conditions.MaxSize,
+			MaxDocs: conditions.MaxDocs,
+		}
+	}
+
+	rolloverBody, _ := encodeRequest(rolloverReq)
+	res, err := client.Indices.Rollover(
+		alias,
+		client.Indices.Rollover.WithContext(ctx),
+		client.Indices.Rollover.WithNewIndex(newIndex),
+		client.Indices.Rollover.WithBody(rolloverBody),
+	)
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return nil, fmt.Errorf("error rolling over alias %s: %s", alias, err)
+	}
+
+	rolloverResponse := &EsRolloverResponse{}
+	if err := decodeResponse(res.Body, rolloverResponse); err != nil {
+		return nil, fmt.Errorf("error decoding rollover response: %s", err)
+	}
+
+	return &RolloverResult{
+		RolledOver: rolloverResponse.RolledOver,
+		OldIndex:   rolloverResponse.OldIndex,
+		NewIndex:   rolloverResponse.NewIndex,
+	}, nil
+}
+
+// planTimeSeriesMigration rolls the series over to a new index with the current mapping when the write index is
+// outdated. If older indices should be reindexed, a migration is also planned when any of them are outdated, which
+// lets an interrupted migration finish.
+func (m *migrator) planTimeSeriesMigration(log *zap.Logger, group []*managedIndex, mapping *VersionedMapping) (*Migration, error) {
+	documentKind := group[0].parts.DocumentKind
+	inner := group[0].parts.Inner
+	alias := m.registry.AliasName(documentKind, inner)
+	series := mapping.TimeSeries
+	currentVersion := mapping.Version
+
+	writeIndex := timeSeriesWriteIndex(group, alias)
+	if writeIndex == nil {
+		log.Warn("Unable to find the write index for time series, skipping", zap.String("alias", alias))
+		return nil, nil
+	}
+
+	comparison, err := CompareVersions(writeIndex.parts.Version, currentVersion)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine if index %s needs to be migrated: %s", writeIndex.name, err)
+	}
+
+	if comparison > 0 && !m.config.Migration.AllowDowngrade {
+		log.Warn("Index is newer than the current mapping version, refusing to downgrade",
+			zap.String("index", writeIndex.name),
+			zap.String("indexVersion", writeIndex.parts.Version),
+			zap.String("currentVersion", currentVersion))
+		return nil, nil
+	}
+
+	migration := &Migration{
+		Alias:        alias,
+		SourceIndex:  writeIndex.name,
+		TargetIndex:  writeIndex.name,
+		DocumentKind: documentKind,
+		DependsOn:    m.dependencies(documentKind),
+		Mapping:      mapping,
+	}
+
+	if comparison != 0 {
+		generation := 0
+		for _, index := range group {
+			if index.parts.Generation > generation {
+				generation = index.parts.Generation
+			}
+		}
+
+		migration.TargetIndex = m.registry.TimeSeriesIndexName(m.indexName(mapping, documentKind, inner), generation+1, timeSeriesDate(series, time.Now()))
+
+		return migration, nil
+	}
+
+	if !series.ReindexOnMigration {
+		return nil, nil
+	}
+
+	for _, index := range group {
+		if m.needsMigration(index.parts.Version, currentVersion) {
+			return migration, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// needsMigration checks if an index at the version should be migrated to the current version, which is only the case
+// for older versions unless downgrades are allowed.
+func (m *migrator) needsMigration(version, currentVersion string) bool {
+	comparison, err := CompareVersions(version, currentVersion)
+	if err != nil {
+		return false
+	}
+
+	return comparison < 0 || (comparison > 0 && m.config.Migration.AllowDowngrade)
+}
+
+// migrateTimeSeries rolls the series over to an index with the current mapping, then optionally reindexes the older
+// indices in the series. Writes continue to go to the write index throughout, so no write block is needed.
+func (m *migrator) migrateTimeSeries(ctx context.Context, log *zap.Logger, migration *Migration, mapping *VersionedMapping) error {
+	if migration.SourceIndex != migration.TargetIndex {
+		// rolling over an alias without a write index moves the alias, so make sure the source is flagged first
+		isWriteIndex := true
+		aliasReqBody, _ := encodeRequest(&EsIndexAliasRequest{
+			Actions: []EsActions{
+				{
+					Add: &EsIndexAlias{
+						Index:        migration.SourceIndex,
+						Alias:        migration.Alias,
+						IsWriteIndex: &isWriteIndex,
+					},
+				},
+			},
+		})
+		res, err := m.client.Indices.UpdateAliases(aliasReqBody, m.client.Indices.UpdateAliases.WithContext(ctx))
+		if err := getErrorFromESResponse(res, err); err != nil {
+			return fmt.Errorf("error setting the write index for the alias: %s", err)
+		}
+
+		log.Info("Rolling over time series")
+		if _, err := rollover(ctx, m.client, migration.Alias, migration.TargetIndex, nil, indexMappings(m.registry, migration.DocumentKind, mapping, time.Now()), indexSettings(m.registry, mapping, migration.Alias)); err != nil {
+			return err
+		}
+	}
+
+	if mapping.TimeSeries.ReindexOnMigration {
+		if err := m.reindexTimeSeries(ctx, log, migration, mapping); err != nil {
+			return err
+		}
+	}
+
+	log.Info("Migration complete")
+	return nil
+}
+
+// reindexTimeSeries copies each outdated index in the series into an index with the same generation and date, but the
+// current version, and then replaces the outdated index in the alias.
+func (m *migrator) reindexTimeSeries(ctx context.Context, log *zap.Logger, migration *Migration, mapping *VersionedMapping) error {
+	indices, err := getAliasIndices(ctx, m.client, migration.Alias)
+	if err != nil {
+		return err
+	}
+
+	var indexNames []string
+	for indexName := range indices {
+		indexNames = append(indexNames, indexName)
+	}
+	sort.Strings(indexNames)
+
+	for _, indexName := range indexNames {
+		parts := m.registry.ParseIndexName(indexName)
+		if parts == nil || !m.needsMigration(parts.Version, mapping.Version) {
+			continue
+		}
+
+		date := parts.Date
+		if date == "" {
+			date = timeSeriesDate(mapping.TimeSeries, time.Now())
+		}
+		targetIndex := m.registry.TimeSeriesIndexName(m.indexName(mapping, parts.DocumentKind, parts.Inner), parts.Generation, date)
+		indexMigration := &Migration{
+			Alias:        migration.Alias,
+			SourceIndex:  indexName,
+			TargetIndex:  targetIndex,
+			DocumentKind: migration.DocumentKind,
+			Mapping:      mapping,
+		}
+		indexLog := log.With(zap.String("source", indexName), zap.String("target", targetIndex))
+
+		if err := m.createTarget(ctx, indexMigration, targetIndex, ""); err != nil {
+			return fmt.Errorf("error creating target index: %s", err)
+		}
+
+		if err := m.reindex(ctx, indexLog, indexMigration, nil); err != nil {
+			return err
+		}
+
+		isNotWriteIndex := false
+		aliasReqBody, _ := encodeRequest(&EsIndexAliasRequest{
+			Actions: []EsActions{
+				{
+					RemoveIndex: &EsIndexRef{Index: indexName},
+				},
+				{
+					Add: &EsIndexAlias{
+						Index:        targetIndex,
+						Alias:        migration.Alias,
+						IsWriteIndex: &isNotWriteIndex,
+					},
+				},
+			},
+		})
+
+		indexLog.Info("Replacing outdated index in time series")
+		res, err := m.client.Indices.UpdateAliases(aliasReqBody, m.client.Indices.UpdateAliases.WithContext(ctx))
+		if err := getErrorFromESResponse(res, err); err != nil {
+			return fmt.Errorf("error replacing index %s in the alias: %s", indexName, err)
+		}
+	}
+
+	return nil
+}
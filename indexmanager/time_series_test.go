@@ -0,0 +1,440 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager_test
+
+import (
+	"context"
+	"net/http"
+	"testing/fstest"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	. "github.com/rode/es-index-manager/indexmanager"
+	"github.com/rode/es-index-manager/mocks"
+)
+
+var _ = Describe("Time series", func() {
+	var (
+		ctx           = context.Background()
+		config        *Config
+		mockTransport *mockEsTransport
+		mockEsClient  *elasticsearch.Client
+		mockRepo      *mocks.FakeIndexRepository
+
+		auditMapping *VersionedMapping
+
+		registry   MappingsRegistry
+		repository IndexRepository
+		migrator   Migrator
+	)
+
+	BeforeEach(func() {
+		config = &Config{
+			IndexPrefix:  "myapp",
+			MappingsPath: "mappings",
+			Migration: &MigrationConfig{
+				PollAttempts: 1,
+				PollInterval: time.Second,
+			},
+		}
+		auditMapping = &VersionedMapping{
+			Version:  "v2",
+			Mappings: map[string]interface{}{"_meta": map[string]interface{}{"type": "myapp"}},
+			TimeSeries: &TimeSeriesConfig{
+				Rollover: &RolloverConditions{MaxAge: "1d", MaxDocs: 100},
+			},
+		}
+
+		mockTransport = &mockEsTransport{}
+		mockEsClient = &elasticsearch.Client{Transport: mockTransport, API: esapi.New(mockTransport)}
+		mockRepo = &mocks.FakeIndexRepository{}
+	})
+
+	JustBeforeEach(func() {
+		registry = NewMappingsRegistry(config, fstest.MapFS{
+			"mappings/audit.json": mappingsFile(auditMapping),
+			"mappings/dated-audit.json": mappingsFile(&VersionedMapping{
+				Version:    "v1",
+				TimeSeries: &TimeSeriesConfig{DateFormat: "2006.01.02"},
+			}),
+			"mappings/policy.json": mappingsFile(&VersionedMapping{Version: "v1"}),
+		})
+		Expect(registry.LoadMappings()).To(Succeed())
+
+		repository = NewIndexRepository(logger, mockEsClient, registry)
+		migrator = NewMigrator(logger, mockEsClient, registry, mockRepo, func(time.Duration) {}, config)
+	})
+
+	DescribeTable("parsing index names",
+		func(indexName string, expected *IndexName) {
+			Expect(registry.ParseIndexName(indexName)).To(Equal(expected))
+		},
+		Entry("numbered index", "myapp-v2-audit-000003", &IndexName{DocumentKind: "audit", Version: "v2", Generation: 3}),
+		Entry("numbered index with an inner name", "myapp-v2-tenant-audit-000001", &IndexName{DocumentKind: "audit", Version: "v2", Inner: "tenant", Generation: 1}),
+		Entry("dated index", "myapp-v1-dated-audit-2021.06.01-000002", &IndexName{DocumentKind: "dated-audit", Version: "v1", Generation: 2, Date: "2021.06.01"}),
+		Entry("index created before the kind was a time series", "myapp-v1-audit", &IndexName{DocumentKind: "audit", Version: "v1"}),
+		Entry("generation on a kind that isn't a time series", "myapp-v1-policy-000001", nil),
+	)
+
+	Context("with a different naming delimiter", func() {
+		BeforeEach(func() {
+			config.NamingStrategy = &DefaultNamingStrategy{Delimiter: "_"}
+		})
+
+		It("should use the delimiter before the date and generation", func() {
+			Expect(registry.TimeSeriesIndexName("myapp_v2_audit", 3, "")).To(Equal("myapp_v2_audit_000003"))
+			Expect(registry.TimeSeriesIndexName("myapp_v1_dated-audit", 2, "2021.06.01")).To(Equal("myapp_v1_dated-audit_2021.06.01_000002"))
+		})
+
+		DescribeTable("parsing index names",
+			func(indexName string, expected *IndexName) {
+				Expect(registry.ParseIndexName(indexName)).To(Equal(expected))
+			},
+			Entry("numbered index", "myapp_v2_audit_000003", &IndexName{DocumentKind: "audit", Version: "v2", Generation: 3}),
+			Entry("dated index", "myapp_v1_dated-audit_2021.06.01_000002", &IndexName{DocumentKind: "dated-audit", Version: "v1", Generation: 2, Date: "2021.06.01"}),
+		)
+	})
+
+	It("should refuse a date format that contains the naming delimiter", func() {
+		registry := NewMappingsRegistry(config, fstest.MapFS{
+			"mappings/audit.json": mappingsFile(&VersionedMapping{
+				Version:    "v1",
+				TimeSeries: &TimeSeriesConfig{DateFormat: "2006-01-02"},
+			}),
+		})
+
+		err := registry.LoadMappings()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(`the date format for document kind "audit" can't contain the naming delimiter "-"`))
+	})
+
+	Context("CreateIndex", func() {
+		var (
+			aliasName   string
+			actualError error
+		)
+
+		BeforeEach(func() {
+			aliasName = "myapp-audit"
+		})
+
+		JustBeforeEach(func() {
+			actualError = repository.CreateIndex(ctx, registry.IndexName("audit", ""), aliasName, "audit")
+		})
+
+		When("the series doesn't exist", func() {
+			BeforeEach(func() {
+				mockTransport.preparedHttpResponses = []*http.Response{
+					{StatusCode: http.StatusNotFound},
+					{StatusCode: http.StatusOK},
+				}
+			})
+
+			It("should create the first index in the series as the write index", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(mockTransport.receivedHttpRequests).To(HaveLen(2))
+				Expect(mockTransport.receivedHttpRequests[0].URL.Path).To(Equal("/_alias/myapp-audit"))
+				Expect(mockTransport.receivedHttpRequests[1].Method).To(Equal(http.MethodPut))
+				Expect(mockTransport.receivedHttpRequests[1].URL.Path).To(Equal("/myapp-v2-audit-000001"))
+
+				actualPayload := map[string]interface{}{}
+				readRequestBody(mockTransport.receivedHttpRequests[1], &actualPayload)
+				Expect(actualPayload).To(MatchKeys(IgnoreExtras, Keys{
+					"aliases": Equal(map[string]interface{}{
+						"myapp-audit": map[string]interface{}{"is_write_index": true},
+					}),
+				}))
+			})
+		})
+
+		When("the series already exists", func() {
+			BeforeEach(func() {
+				mockTransport.preparedHttpResponses = []*http.Response{
+					{StatusCode: http.StatusOK},
+				}
+			})
+
+			It("should not create an index", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(mockTransport.receivedHttpRequests).To(HaveLen(1))
+			})
+		})
+
+		When("no alias is given", func() {
+			BeforeEach(func() {
+				aliasName = ""
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(MatchError(ContainSubstring("requires an alias")))
+				Expect(mockTransport.receivedHttpRequests).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("Rollover", func() {
+		var (
+			documentKind string
+			actualResult *RolloverResult
+			actualError  error
+		)
+
+		BeforeEach(func() {
+			documentKind = "audit"
+			mockTransport.preparedHttpResponses = []*http.Response{
+				{
+					StatusCode: http.StatusOK,
+					Body: createESBody(map[string]interface{}{
+						"myapp-v1-audit-000001": map[string]interface{}{},
+						"myapp-v2-audit-000002": map[string]interface{}{},
+					}),
+				},
+				{
+					StatusCode: http.StatusOK,
+					Body: createESBody(map[string]interface{}{
+						"old_index":   "myapp-v2-audit-000002",
+						"new_index":   "myapp-v2-audit-000003",
+						"rolled_over": true,
+					}),
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			actualResult, actualError = repository.Rollover(ctx, documentKind, "")
+		})
+
+		It("should roll over to the next generation using the conditions from the mapping", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+			Expect(mockTransport.receivedHttpRequests[0].URL.Path).To(Equal("/_alias/myapp-audit"))
+			Expect(mockTransport.receivedHttpRequests[1].Method).To(Equal(http.MethodPost))
+			Expect(mockTransport.receivedHttpRequests[1].URL.Path).To(Equal("/myapp-audit/_rollover/myapp-v2-audit-000003"))
+
+			actualPayload := map[string]interface{}{}
+			readRequestBody(mockTransport.receivedHttpRequests[1], &actualPayload)
+			Expect(actualPayload).To(MatchAllKeys(Keys{
+				"conditions": Equal(map[string]interface{}{"max_age": "1d", "max_docs": float64(100)}),
+				"mappings":   matchIndexMappings(auditMapping, "audit"),
+			}))
+		})
+
+		It("should return the result", func() {
+			Expect(actualResult).To(Equal(&RolloverResult{
+				RolledOver: true,
+				OldIndex:   "myapp-v2-audit-000002",
+				NewIndex:   "myapp-v2-audit-000003",
+			}))
+		})
+
+		When("the document kind isn't a time series", func() {
+			BeforeEach(func() {
+				documentKind = "policy"
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(MatchError(ContainSubstring("not a time series")))
+				Expect(mockTransport.receivedHttpRequests).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("GetMigrations", func() {
+		var (
+			firstIndexVersion string
+			writeIndexVersion string
+			actualMigrations  []*Migration
+			actualError       error
+		)
+
+		BeforeEach(func() {
+			firstIndexVersion = "v1"
+			writeIndexVersion = "v1"
+		})
+
+		JustBeforeEach(func() {
+			mockTransport.preparedHttpResponses = []*http.Response{
+				{
+					StatusCode: http.StatusOK,
+					Body: createESBody(map[string]interface{}{
+						"myapp-" + firstIndexVersion + "-audit-000001": managedIndexResponse("myapp", map[string]interface{}{
+							"myapp-audit": map[string]interface{}{"is_write_index": false},
+						}, false),
+						"myapp-" + writeIndexVersion + "-audit-000002": managedIndexResponse("myapp", map[string]interface{}{
+							"myapp-audit": map[string]interface{}{"is_write_index": true},
+						}, false),
+					}),
+				},
+			}
+
+			actualMigrations, actualError = migrator.GetMigrations(ctx)
+		})
+
+		It("should roll the write index over to the next generation", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+			Expect(actualMigrations).To(HaveLen(1))
+			Expect(actualMigrations[0]).To(PointTo(MatchFields(IgnoreExtras, Fields{
+				"Alias":        Equal("myapp-audit"),
+				"SourceIndex":  Equal("myapp-v1-audit-000002"),
+				"TargetIndex":  Equal("myapp-v2-audit-000003"),
+				"DocumentKind": Equal("audit"),
+			})))
+		})
+
+		When("the write index is already at the current version", func() {
+			BeforeEach(func() {
+				writeIndexVersion = "v2"
+			})
+
+			It("should not plan a migration", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(actualMigrations).To(BeEmpty())
+			})
+
+			When("older indices should be reindexed", func() {
+				BeforeEach(func() {
+					auditMapping.TimeSeries.ReindexOnMigration = true
+				})
+
+				It("should plan a migration without a rollover", func() {
+					Expect(actualMigrations).To(HaveLen(1))
+					Expect(actualMigrations[0].SourceIndex).To(Equal("myapp-v2-audit-000002"))
+					Expect(actualMigrations[0].TargetIndex).To(Equal("myapp-v2-audit-000002"))
+				})
+
+				When("the older indices are at a newer version", func() {
+					BeforeEach(func() {
+						firstIndexVersion = "v3"
+					})
+
+					It("should not plan a migration", func() {
+						Expect(actualError).NotTo(HaveOccurred())
+						Expect(actualMigrations).To(BeEmpty())
+					})
+				})
+			})
+		})
+	})
+
+	Context("Migrate", func() {
+		var (
+			migration   *Migration
+			actualError error
+		)
+
+		BeforeEach(func() {
+			migration = &Migration{
+				Alias:        "myapp-audit",
+				SourceIndex:  "myapp-v1-audit-000002",
+				TargetIndex:  "myapp-v2-audit-000003",
+				DocumentKind: "audit",
+			}
+			mockTransport.preparedHttpResponses = []*http.Response{
+				{StatusCode: http.StatusOK},
+				{
+					StatusCode: http.StatusOK,
+					Body: createESBody(map[string]interface{}{
+						"old_index":   migration.SourceIndex,
+						"new_index":   migration.TargetIndex,
+						"rolled_over": true,
+					}),
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			actualError = migrator.Migrate(ctx, migration)
+		})
+
+		It("should make sure the source is the write index, then roll over without conditions", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+			Expect(mockTransport.receivedHttpRequests).To(HaveLen(2))
+
+			aliasPayload := map[string]interface{}{}
+			readRequestBody(mockTransport.receivedHttpRequests[0], &aliasPayload)
+			Expect(aliasPayload["actions"]).To(ConsistOf(map[string]interface{}{
+				"add": map[string]interface{}{
+					"index":          migration.SourceIndex,
+					"alias":          migration.Alias,
+					"is_write_index": true,
+				},
+			}))
+
+			Expect(mockTransport.receivedHttpRequests[1].URL.Path).To(Equal("/myapp-audit/_rollover/myapp-v2-audit-000003"))
+			rolloverPayload := map[string]interface{}{}
+			readRequestBody(mockTransport.receivedHttpRequests[1], &rolloverPayload)
+			Expect(rolloverPayload).NotTo(HaveKey("conditions"))
+		})
+
+		It("should not block writes or delete any indices", func() {
+			Expect(mockRepo.CreateIndexCallCount()).To(Equal(0))
+			Expect(mockRepo.DeleteIndexCallCount()).To(Equal(0))
+		})
+
+		When("older indices should be reindexed", func() {
+			BeforeEach(func() {
+				auditMapping.TimeSeries.ReindexOnMigration = true
+				mockTransport.preparedHttpResponses = append(mockTransport.preparedHttpResponses,
+					&http.Response{
+						StatusCode: http.StatusOK,
+						Body: createESBody(map[string]interface{}{
+							"myapp-v1-audit-000002": map[string]interface{}{},
+							"myapp-v2-audit-000003": map[string]interface{}{},
+						}),
+					},
+					&http.Response{StatusCode: http.StatusOK, Body: createESBody(map[string]interface{}{"task": "task-id"})},
+					&http.Response{StatusCode: http.StatusOK, Body: createESBody(map[string]interface{}{"completed": true})},
+					&http.Response{StatusCode: http.StatusOK},
+					&http.Response{StatusCode: http.StatusOK},
+				)
+			})
+
+			It("should reindex the outdated index into the same generation at the current version", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(mockRepo.CreateIndexCallCount()).To(Equal(1))
+				_, actualIndexName, actualAlias, actualDocumentKind := mockRepo.CreateIndexArgsForCall(0)
+				Expect(actualIndexName).To(Equal("myapp-v2-audit-000002"))
+				Expect(actualAlias).To(BeEmpty())
+				Expect(actualDocumentKind).To(Equal("audit"))
+
+				reindexPayload := map[string]interface{}{}
+				readRequestBody(mockTransport.receivedHttpRequests[3], &reindexPayload)
+				Expect(reindexPayload["source"]).To(HaveKeyWithValue("index", "myapp-v1-audit-000002"))
+				Expect(reindexPayload["dest"]).To(HaveKeyWithValue("index", "myapp-v2-audit-000002"))
+			})
+
+			It("should replace the outdated index in the alias", func() {
+				aliasPayload := map[string]interface{}{}
+				readRequestBody(mockTransport.receivedHttpRequests[6], &aliasPayload)
+				Expect(aliasPayload["actions"]).To(ConsistOf(
+					map[string]interface{}{
+						"remove_index": map[string]interface{}{"index": "myapp-v1-audit-000002"},
+					},
+					map[string]interface{}{
+						"add": map[string]interface{}{
+							"index":          "myapp-v2-audit-000002",
+							"alias":          "myapp-audit",
+							"is_write_index": false,
+						},
+					},
+				))
+			})
+		})
+	})
+})
@@ -0,0 +1,385 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	. "github.com/rode/es-index-manager/indexmanager/internal"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultTransferBatchSize = 1000
+	pointInTimeKeepAlive     = "5m"
+
+	bulkActionCreate = "create"
+	bulkActionIndex  = "index"
+)
+
+//counterfeiter:generate -o ../mocks . DocumentTransfer
+type DocumentTransfer interface {
+	// Export writes every document for the document kind and inner name to the writer as newline-delimited JSON, with
+	// one {"_id": ..., "_source": ...} object per line. Documents are read through the alias using a point in time, so
+	// writes that happen during the export aren't included.
+	Export(ctx context.Context, documentKind, inner string, w io.Writer) (*TransferReport, error)
+	// Import reads documents in the format written by Export, and bulk loads them into the index for the document kind
+	// and inner name, creating it with the current mapping if it doesn't exist. Documents are written to the index for
+	// the current version rather than through the alias, which may still point to other indices; time series and data
+	// streams are written through the alias, which points to their write index. Documents that Elasticsearch rejects
+	// are listed in the report, rather than stopping the import.
+	Import(ctx context.Context, documentKind, inner string, r io.Reader) (*TransferReport, error)
+	// Seed loads the seed documents from the registry into the index for the document kind and inner name, creating
+	// it if it doesn't exist. Seed documents are only created if a document with the same id isn't already in the
+	// index, so seeding an index more than once is safe; those documents are counted as skipped in the report.
+	Seed(ctx context.Context, documentKind, inner string) (*TransferReport, error)
+}
+
+type documentTransfer struct {
+	client   *elasticsearch.Client
+	config   *Config
+	logger   *zap.Logger
+	registry MappingsRegistry
+	repo     IndexRepository
+}
+
+// transferDocument is a single line of an export.
+type transferDocument struct {
+	ID     string          `json:"_id,omitempty"`
+	Source json.RawMessage `json:"_source"`
+}
+
+func NewDocumentTransfer(
+	logger *zap.Logger,
+	client *elasticsearch.Client,
+	registry MappingsRegistry,
+	repo IndexRepository,
+	config *Config,
+) DocumentTransfer {
+	return &documentTransfer{
+		client,
+		config,
+		logger,
+		registry,
+		repo,
+	}
+}
+
+func (t *documentTransfer) Export(ctx context.Context, documentKind, inner string, w io.Writer) (*TransferReport, error) {
+	alias := t.registry.AliasName(documentKind, inner)
+	log := t.logger.Named("Export").With(zap.String("alias", alias))
+	report := &TransferReport{}
+
+	res, err := t.client.OpenPointInTime(
+		t.client.OpenPointInTime.WithContext(ctx),
+		t.client.OpenPointInTime.WithIndex(alias),
+		t.client.OpenPointInTime.WithKeepAlive(pointInTimeKeepAlive),
+	)
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return nil, fmt.Errorf("error opening point in time for %s: %s", alias, err)
+	}
+
+	pit := &EsPointInTime{}
+	if err := decodeResponse(res.Body, pit); err != nil {
+		return nil, fmt.Errorf("error decoding point in time response: %s", err)
+	}
+	defer t.closePointInTime(ctx, log, pit)
+
+	encoder := json.NewEncoder(w)
+	var searchAfter []interface{}
+	for {
+		query := map[string]interface{}{
+			"size": t.batchSize(),
+			"pit": map[string]interface{}{
+				"id":         pit.ID,
+				"keep_alive": pointInTimeKeepAlive,
+			},
+			"sort": []interface{}{
+				map[string]interface{}{"_shard_doc": "asc"},
+			},
+		}
+		if searchAfter != nil {
+			query["search_after"] = searchAfter
+		}
+
+		body, _ := encodeRequest(query)
+		res, err := t.client.Search(
+			t.client.Search.WithContext(ctx),
+			t.client.Search.WithBody(body),
+		)
+		if err := getErrorFromESResponse(res, err); err != nil {
+			return report, fmt.Errorf("error searching %s: %s", alias, err)
+		}
+
+		searchResponse := &EsSearchResponse{}
+		if err := decodeResponse(res.Body, searchResponse); err != nil {
+			return report, fmt.Errorf("error decoding search response: %s", err)
+		}
+
+		if searchResponse.PitID != "" {
+			pit.ID = searchResponse.PitID
+		}
+
+		if searchResponse.Hits == nil || len(searchResponse.Hits.Hits) == 0 {
+			break
+		}
+
+		for _, hit := range searchResponse.Hits.Hits {
+			if err := encoder.Encode(&transferDocument{ID: hit.ID, Source: hit.Source}); err != nil {
+				return report, fmt.Errorf("error writing document %s: %s", hit.ID, err)
+			}
+		}
+
+		hits := searchResponse.Hits.Hits
+		searchAfter = hits[len(hits)-1].Sort
+		report.Documents += len(hits)
+		t.progress(documentKind, report.Documents)
+	}
+
+	log.Info("Export complete", zap.Int("documents", report.Documents))
+
+	return report, nil
+}
+
+func (t *documentTransfer) closePointInTime(ctx context.Context, log *zap.Logger, pit *EsPointInTime) {
+	body, _ := encodeRequest(pit)
+	res, err := t.client.ClosePointInTime(
+		t.client.ClosePointInTime.WithContext(ctx),
+		t.client.ClosePointInTime.WithBody(body),
+	)
+	// the point in time expires on its own, so there's no need to fail the export
+	if err := getErrorFromESResponse(res, err); err != nil {
+		log.Warn("Error closing point in time", zap.Error(err))
+	}
+}
+
+func (t *documentTransfer) Import(ctx context.Context, documentKind, inner string, r io.Reader) (*TransferReport, error) {
+	mapping := t.registry.Mapping(documentKind)
+	if mapping == nil {
+		return nil, fmt.Errorf("unable to find a mapping for document kind %s", documentKind)
+	}
+
+	indexName := t.registry.IndexName(documentKind, inner)
+	alias := t.registry.AliasName(documentKind, inner)
+	log := t.logger.Named("Import").With(zap.String("alias", alias))
+
+	if err := t.repo.CreateIndex(ctx, indexName, alias, documentKind); err != nil {
+		return nil, err
+	}
+
+	// data streams only accept new documents
+	action := bulkActionIndex
+	if mapping.DataStream != nil {
+		action = bulkActionCreate
+	}
+
+	report := &TransferReport{}
+	if err := t.load(ctx, documentKind, writeTarget(mapping, indexName, alias), action, r, report); err != nil {
+		return report, err
+	}
+
+	log.Info("Import complete", zap.Int("documents", report.Documents), zap.Int("failed", len(report.Failed)))
+
+	return report, nil
+}
+
+func (t *documentTransfer) Seed(ctx context.Context, documentKind, inner string) (*TransferReport, error) {
+	report := &TransferReport{}
+	seeds := t.registry.Seeds(documentKind)
+	if seeds == nil {
+		return report, nil
+	}
+
+	indexName := t.registry.IndexName(documentKind, inner)
+	alias := t.registry.AliasName(documentKind, inner)
+	log := t.logger.Named("Seed").With(zap.String("alias", alias))
+
+	if err := t.repo.CreateIndex(ctx, indexName, alias, documentKind); err != nil {
+		return nil, err
+	}
+
+	target := writeTarget(t.registry.Mapping(documentKind), indexName, alias)
+	if err := t.load(ctx, documentKind, target, bulkActionCreate, bytes.NewReader(seeds), report); err != nil {
+		return report, err
+	}
+
+	log.Info("Seeding complete",
+		zap.Int("documents", report.Documents),
+		zap.Int("skipped", report.Skipped),
+		zap.Int("failed", len(report.Failed)),
+	)
+
+	return report, nil
+}
+
+// writeTarget returns the index or alias that documents are loaded into. Time series and data streams are written
+// through the alias, since they don't have a single index; other document kinds are written to the index for the
+// current version, since the alias may also point to an older index, such as during a migration.
+func writeTarget(mapping *VersionedMapping, indexName, alias string) string {
+	if mapping != nil && (mapping.TimeSeries != nil || mapping.DataStream != nil) {
+		return alias
+	}
+
+	return indexName
+}
+
+// load reads documents in the export format and writes them to the target in batches.
+func (t *documentTransfer) load(ctx context.Context, documentKind, target, action string, r io.Reader, report *TransferReport) error {
+	decoder := json.NewDecoder(r)
+	var batch []*transferDocument
+	for position := 1; ; position++ {
+		document, err := readTransferDocument(decoder, position)
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		batch = append(batch, document)
+		if len(batch) == t.batchSize() {
+			if err := t.bulk(ctx, target, action, batch, report); err != nil {
+				return err
+			}
+			t.progress(documentKind, report.Documents)
+			batch = nil
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := t.bulk(ctx, target, action, batch, report); err != nil {
+			return err
+		}
+		t.progress(documentKind, report.Documents)
+	}
+
+	return nil
+}
+
+// readTransferDocument decodes the next document, returning io.EOF at the end of the input.
+func readTransferDocument(decoder *json.Decoder, position int) (*transferDocument, error) {
+	document := &transferDocument{}
+	err := decoder.Decode(document)
+	if err == io.EOF {
+		return nil, err
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error reading document %d: %s", position, err)
+	}
+
+	if len(document.Source) == 0 {
+		return nil, fmt.Errorf("document %d doesn't have a _source", position)
+	}
+
+	return document, nil
+}
+
+// validateSeeds checks that a seed file is in the export format, and that every document has an id, which is what
+// makes seeding idempotent.
+func validateSeeds(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for position := 1; ; position++ {
+		document, err := readTransferDocument(decoder, position)
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if document.ID == "" {
+			return fmt.Errorf("document %d doesn't have an _id", position)
+		}
+	}
+}
+
+// bulk writes a batch of documents to the target, recording any that failed in the report.
+func (t *documentTransfer) bulk(ctx context.Context, target, action string, batch []*transferDocument, report *TransferReport) error {
+	body := &bytes.Buffer{}
+	encoder := json.NewEncoder(body)
+	for _, document := range batch {
+		metadata := map[string]interface{}{}
+		if document.ID != "" {
+			metadata["_id"] = document.ID
+		}
+
+		_ = encoder.Encode(map[string]interface{}{action: metadata})
+		// the bulk API needs each document on a single line
+		_ = json.Compact(body, document.Source)
+		body.WriteByte('\n')
+	}
+
+	res, err := t.client.Bulk(
+		body,
+		t.client.Bulk.WithContext(ctx),
+		t.client.Bulk.WithIndex(target),
+	)
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return fmt.Errorf("error bulk loading documents into %s: %s", target, err)
+	}
+
+	bulkResponse := &EsBulkResponse{}
+	if err := decodeResponse(res.Body, bulkResponse); err != nil {
+		return fmt.Errorf("error decoding bulk response: %s", err)
+	}
+
+	for _, item := range bulkResponse.Items {
+		result := item[action]
+		if result == nil {
+			continue
+		}
+
+		// create fails with a conflict when the document already exists
+		if result.Error != nil && action == bulkActionCreate && result.Status == http.StatusConflict {
+			report.Skipped++
+			continue
+		}
+
+		if result.Error != nil {
+			report.Failed = append(report.Failed, &TransferError{
+				ID:     result.ID,
+				Reason: fmt.Sprintf("%s: %s", result.Error.Type, result.Error.Reason),
+			})
+			continue
+		}
+
+		report.Documents++
+	}
+
+	return nil
+}
+
+func (t *documentTransfer) batchSize() int {
+	if t.config.Transfer != nil && t.config.Transfer.BatchSize > 0 {
+		return t.config.Transfer.BatchSize
+	}
+
+	return defaultTransferBatchSize
+}
+
+func (t *documentTransfer) progress(documentKind string, documents int) {
+	if t.config.Transfer != nil && t.config.Transfer.Progress != nil {
+		t.config.Transfer.Progress(documentKind, documents)
+	}
+}
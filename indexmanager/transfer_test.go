@@ -0,0 +1,467 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/rode/es-index-manager/indexmanager"
+	"github.com/rode/es-index-manager/mocks"
+)
+
+var _ = Describe("DocumentTransfer", func() {
+	var (
+		ctx           = context.Background()
+		config        *Config
+		mockTransport *mockEsTransport
+		mockRegistry  *mocks.FakeMappingsRegistry
+		mockRepo      *mocks.FakeIndexRepository
+		transfer      DocumentTransfer
+
+		documentKind string
+		inner        string
+		alias        string
+		indexName    string
+		progress     []int
+	)
+
+	BeforeEach(func() {
+		documentKind = fake.Word()
+		inner = fake.Word()
+		alias = fake.Word()
+		indexName = fake.Word()
+		progress = nil
+		config = &Config{
+			IndexPrefix: fake.Word(),
+			Transfer: &TransferConfig{
+				BatchSize: 2,
+				Progress: func(actualKind string, documents int) {
+					Expect(actualKind).To(Equal(documentKind))
+					progress = append(progress, documents)
+				},
+			},
+		}
+		mockTransport = &mockEsTransport{}
+		mockEsClient := &elasticsearch.Client{Transport: mockTransport, API: esapi.New(mockTransport)}
+		mockRegistry = &mocks.FakeMappingsRegistry{}
+		mockRepo = &mocks.FakeIndexRepository{}
+
+		mockRegistry.AliasNameReturns(alias)
+		mockRegistry.IndexNameReturns(indexName)
+		mockRegistry.MappingReturns(&VersionedMapping{Version: "v1"})
+
+		transfer = NewDocumentTransfer(logger, mockEsClient, mockRegistry, mockRepo, config)
+	})
+
+	Context("Export", func() {
+		var (
+			output       *bytes.Buffer
+			actualReport *TransferReport
+			actualError  error
+		)
+
+		hit := func(id string, sort int) map[string]interface{} {
+			return map[string]interface{}{
+				"_id":     id,
+				"_source": map[string]interface{}{"name": id},
+				"sort":    []interface{}{sort},
+			}
+		}
+
+		BeforeEach(func() {
+			output = &bytes.Buffer{}
+			mockTransport.preparedHttpResponses = []*http.Response{
+				// open point in time
+				{
+					StatusCode: http.StatusOK,
+					Body:       createESBody(map[string]interface{}{"id": "pit-1"}),
+				},
+				{
+					StatusCode: http.StatusOK,
+					Body: createESBody(map[string]interface{}{
+						"pit_id": "pit-2",
+						"hits": map[string]interface{}{
+							"hits": []interface{}{hit("a", 1), hit("b", 2)},
+						},
+					}),
+				},
+				{
+					StatusCode: http.StatusOK,
+					Body: createESBody(map[string]interface{}{
+						"pit_id": "pit-2",
+						"hits": map[string]interface{}{
+							"hits": []interface{}{hit("c", 3)},
+						},
+					}),
+				},
+				{
+					StatusCode: http.StatusOK,
+					Body: createESBody(map[string]interface{}{
+						"pit_id": "pit-2",
+						"hits":   map[string]interface{}{"hits": []interface{}{}},
+					}),
+				},
+				// close point in time
+				{StatusCode: http.StatusOK},
+			}
+		})
+
+		JustBeforeEach(func() {
+			actualReport, actualError = transfer.Export(ctx, documentKind, inner, output)
+		})
+
+		It("should write each document as a line of JSON", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+			Expect(actualReport.Documents).To(Equal(3))
+			Expect(output.String()).To(Equal(strings.Join([]string{
+				`{"_id":"a","_source":{"name":"a"}}`,
+				`{"_id":"b","_source":{"name":"b"}}`,
+				`{"_id":"c","_source":{"name":"c"}}`,
+				"",
+			}, "\n")))
+		})
+
+		It("should open a point in time on the alias", func() {
+			actualDocumentKind, actualInner := mockRegistry.AliasNameArgsForCall(0)
+			Expect(actualDocumentKind).To(Equal(documentKind))
+			Expect(actualInner).To(Equal(inner))
+
+			Expect(mockTransport.receivedHttpRequests[0].Method).To(Equal(http.MethodPost))
+			Expect(mockTransport.receivedHttpRequests[0].URL.Path).To(Equal("/" + alias + "/_pit"))
+		})
+
+		It("should page through the documents with search_after", func() {
+			firstPage := map[string]interface{}{}
+			readRequestBody(mockTransport.receivedHttpRequests[1], &firstPage)
+			Expect(firstPage["pit"]).To(HaveKeyWithValue("id", "pit-1"))
+			Expect(firstPage["size"]).To(BeEquivalentTo(2))
+			Expect(firstPage).NotTo(HaveKey("search_after"))
+
+			secondPage := map[string]interface{}{}
+			readRequestBody(mockTransport.receivedHttpRequests[2], &secondPage)
+			Expect(secondPage["pit"]).To(HaveKeyWithValue("id", "pit-2"))
+			Expect(secondPage["search_after"]).To(Equal([]interface{}{float64(2)}))
+		})
+
+		It("should close the point in time", func() {
+			Expect(mockTransport.receivedHttpRequests).To(HaveLen(5))
+			Expect(mockTransport.receivedHttpRequests[4].Method).To(Equal(http.MethodDelete))
+			Expect(mockTransport.receivedHttpRequests[4].URL.Path).To(Equal("/_pit"))
+
+			actualPayload := map[string]interface{}{}
+			readRequestBody(mockTransport.receivedHttpRequests[4], &actualPayload)
+			Expect(actualPayload).To(Equal(map[string]interface{}{"id": "pit-2"}))
+		})
+
+		It("should report progress after each page", func() {
+			Expect(progress).To(Equal([]int{2, 3}))
+		})
+
+		When("searching fails", func() {
+			BeforeEach(func() {
+				mockTransport.preparedHttpResponses[2] = &http.Response{StatusCode: http.StatusInternalServerError}
+				mockTransport.preparedHttpResponses = append(mockTransport.preparedHttpResponses[:3], &http.Response{StatusCode: http.StatusOK})
+			})
+
+			It("should return an error and still close the point in time", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(actualError.Error()).To(ContainSubstring("error searching"))
+				Expect(actualReport.Documents).To(Equal(2))
+				Expect(mockTransport.receivedHttpRequests[3].URL.Path).To(Equal("/_pit"))
+			})
+		})
+
+		When("the point in time can't be opened", func() {
+			BeforeEach(func() {
+				mockTransport.preparedHttpResponses[0].StatusCode = http.StatusNotFound
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(actualError.Error()).To(ContainSubstring("error opening point in time"))
+				Expect(mockTransport.receivedHttpRequests).To(HaveLen(1))
+			})
+		})
+	})
+
+	Context("Import", func() {
+		var (
+			input        string
+			actualReport *TransferReport
+			actualError  error
+		)
+
+		BeforeEach(func() {
+			input = strings.Join([]string{
+				`{"_id":"a","_source":{"name":"a"}}`,
+				`{"_id":"b","_source":{"name":"b"}}`,
+				`{"_source":{"name":"c"}}`,
+			}, "\n")
+			mockTransport.preparedHttpResponses = []*http.Response{
+				{
+					StatusCode: http.StatusOK,
+					Body: createESBody(map[string]interface{}{
+						"errors": true,
+						"items": []interface{}{
+							map[string]interface{}{"index": map[string]interface{}{"_id": "a", "status": 201}},
+							map[string]interface{}{"index": map[string]interface{}{
+								"_id":    "b",
+								"status": 400,
+								"error": map[string]interface{}{
+									"type":   "mapper_parsing_exception",
+									"reason": "failed to parse",
+								},
+							}},
+						},
+					}),
+				},
+				{
+					StatusCode: http.StatusOK,
+					Body: createESBody(map[string]interface{}{
+						"items": []interface{}{
+							map[string]interface{}{"index": map[string]interface{}{"_id": fake.UUID(), "status": 201}},
+						},
+					}),
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			actualReport, actualError = transfer.Import(ctx, documentKind, inner, strings.NewReader(input))
+		})
+
+		It("should create the index for the current version", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+			Expect(mockRepo.CreateIndexCallCount()).To(Equal(1))
+			_, actualIndex, actualAlias, actualDocumentKind := mockRepo.CreateIndexArgsForCall(0)
+			Expect(actualIndex).To(Equal(indexName))
+			Expect(actualAlias).To(Equal(alias))
+			Expect(actualDocumentKind).To(Equal(documentKind))
+		})
+
+		It("should bulk load the documents in batches into the index", func() {
+			Expect(mockTransport.receivedHttpRequests).To(HaveLen(2))
+			Expect(mockTransport.receivedHttpRequests[0].URL.Path).To(Equal("/" + indexName + "/_bulk"))
+
+			body, err := ioutil.ReadAll(mockTransport.receivedHttpRequests[0].Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal(strings.Join([]string{
+				`{"index":{"_id":"a"}}`,
+				`{"name":"a"}`,
+				`{"index":{"_id":"b"}}`,
+				`{"name":"b"}`,
+				"",
+			}, "\n")))
+
+			body, err = ioutil.ReadAll(mockTransport.receivedHttpRequests[1].Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal("{\"index\":{}}\n{\"name\":\"c\"}\n"))
+		})
+
+		It("should report the documents that were rejected", func() {
+			Expect(actualReport.Documents).To(Equal(2))
+			Expect(actualReport.Failed).To(Equal([]*TransferError{
+				{ID: "b", Reason: "mapper_parsing_exception: failed to parse"},
+			}))
+			Expect(progress).To(Equal([]int{1, 2}))
+		})
+
+		When("the document kind is a data stream", func() {
+			BeforeEach(func() {
+				mockRegistry.MappingReturns(&VersionedMapping{Version: "v1", DataStream: &DataStreamConfig{}})
+				mockTransport.preparedHttpResponses = []*http.Response{
+					{StatusCode: http.StatusOK, Body: createESBody(map[string]interface{}{"items": []interface{}{}})},
+					{StatusCode: http.StatusOK, Body: createESBody(map[string]interface{}{"items": []interface{}{}})},
+				}
+			})
+
+			It("should create the documents through the data stream", func() {
+				Expect(mockTransport.receivedHttpRequests[0].URL.Path).To(Equal("/" + alias + "/_bulk"))
+
+				body, err := ioutil.ReadAll(mockTransport.receivedHttpRequests[0].Body)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(body)).To(HavePrefix(`{"create":{"_id":"a"}}`))
+			})
+		})
+
+		When("the document kind is a time series", func() {
+			BeforeEach(func() {
+				mockRegistry.MappingReturns(&VersionedMapping{Version: "v1", TimeSeries: &TimeSeriesConfig{}})
+			})
+
+			It("should write through the alias to the write index", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(mockTransport.receivedHttpRequests[0].URL.Path).To(Equal("/" + alias + "/_bulk"))
+			})
+		})
+
+		When("a document isn't valid JSON", func() {
+			BeforeEach(func() {
+				input = `{"_id":"a","_source":`
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(actualError.Error()).To(ContainSubstring("error reading document 1"))
+				Expect(mockTransport.receivedHttpRequests).To(BeEmpty())
+			})
+		})
+
+		When("a document doesn't have a source", func() {
+			BeforeEach(func() {
+				input = `{"_id":"a"}`
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(actualError.Error()).To(ContainSubstring("doesn't have a _source"))
+			})
+		})
+
+		When("the bulk request fails", func() {
+			BeforeEach(func() {
+				mockTransport.preparedHttpResponses[0] = &http.Response{StatusCode: http.StatusInternalServerError}
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(actualError.Error()).To(ContainSubstring("error bulk loading documents"))
+			})
+		})
+
+		When("creating the index fails", func() {
+			BeforeEach(func() {
+				mockRepo.CreateIndexReturns(errors.New(fake.Word()))
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(mockTransport.receivedHttpRequests).To(BeEmpty())
+			})
+		})
+
+		When("the document kind isn't registered", func() {
+			BeforeEach(func() {
+				mockRegistry.MappingReturns(nil)
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(mockRepo.CreateIndexCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Context("Seed", func() {
+		var (
+			actualReport *TransferReport
+			actualError  error
+		)
+
+		BeforeEach(func() {
+			mockRegistry.SeedsReturns([]byte(strings.Join([]string{
+				`{"_id":"a","_source":{"name":"a"}}`,
+				`{"_id":"b","_source":{"name":"b"}}`,
+			}, "\n")))
+			mockTransport.preparedHttpResponses = []*http.Response{
+				{
+					StatusCode: http.StatusOK,
+					Body: createESBody(map[string]interface{}{
+						"errors": true,
+						"items": []interface{}{
+							map[string]interface{}{"create": map[string]interface{}{"_id": "a", "status": 201}},
+							map[string]interface{}{"create": map[string]interface{}{
+								"_id":    "b",
+								"status": 409,
+								"error": map[string]interface{}{
+									"type":   "version_conflict_engine_exception",
+									"reason": "document already exists",
+								},
+							}},
+						},
+					}),
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			actualReport, actualError = transfer.Seed(ctx, documentKind, inner)
+		})
+
+		It("should create the index", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+			Expect(mockRegistry.SeedsArgsForCall(0)).To(Equal(documentKind))
+			Expect(mockRepo.CreateIndexCallCount()).To(Equal(1))
+			_, actualIndex, actualAlias, actualDocumentKind := mockRepo.CreateIndexArgsForCall(0)
+			Expect(actualIndex).To(Equal(indexName))
+			Expect(actualAlias).To(Equal(alias))
+			Expect(actualDocumentKind).To(Equal(documentKind))
+		})
+
+		It("should only create documents that don't exist", func() {
+			Expect(mockTransport.receivedHttpRequests).To(HaveLen(1))
+			Expect(mockTransport.receivedHttpRequests[0].URL.Path).To(Equal("/" + indexName + "/_bulk"))
+
+			body, err := ioutil.ReadAll(mockTransport.receivedHttpRequests[0].Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal(strings.Join([]string{
+				`{"create":{"_id":"a"}}`,
+				`{"name":"a"}`,
+				`{"create":{"_id":"b"}}`,
+				`{"name":"b"}`,
+				"",
+			}, "\n")))
+		})
+
+		It("should count existing documents as skipped", func() {
+			Expect(actualReport.Documents).To(Equal(1))
+			Expect(actualReport.Skipped).To(Equal(1))
+			Expect(actualReport.Failed).To(BeEmpty())
+		})
+
+		When("the document kind doesn't have seeds", func() {
+			BeforeEach(func() {
+				mockRegistry.SeedsReturns(nil)
+			})
+
+			It("should do nothing", func() {
+				Expect(actualError).NotTo(HaveOccurred())
+				Expect(actualReport.Documents).To(Equal(0))
+				Expect(mockRepo.CreateIndexCallCount()).To(Equal(0))
+				Expect(mockTransport.receivedHttpRequests).To(BeEmpty())
+			})
+		})
+
+		When("creating the index fails", func() {
+			BeforeEach(func() {
+				mockRepo.CreateIndexReturns(errors.New(fake.Word()))
+			})
+
+			It("should return an error", func() {
+				Expect(actualError).To(HaveOccurred())
+				Expect(mockTransport.receivedHttpRequests).To(BeEmpty())
+			})
+		})
+	})
+})
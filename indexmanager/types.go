@@ -14,7 +14,10 @@
 
 package indexmanager
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 const (
 	indexNamePartsDelimiter = "-"
@@ -25,16 +28,164 @@ type Migration struct {
 	SourceIndex  string
 	TargetIndex  string
 	DocumentKind string
+	// DependsOn lists the document kinds whose migrations must complete before this one starts.
+	DependsOn []string
+	// Recovery is set when the target index already exists, e.g. because an earlier migration was interrupted.
+	Recovery MigrationRecovery
+	// PreviousAlias is set when the source index holds a document kind that was renamed to DocumentKind.
+	// It's the alias for the previous document kind, which is removed from the source index.
+	PreviousAlias string
+	// Snapshot is the name of the snapshot taken before the migration ran, if MigrationConfig.Snapshot is set.
+	Snapshot string
+	// Mapping is the mapping for DocumentKind when the migration was planned. The target is created from it, so that
+	// the mappings being reloaded while the migration runs can't change the version partway through. If it's nil,
+	// the mapping is read from the registry when the migration starts.
+	Mapping *VersionedMapping
+}
+
+// MigrationRecovery describes how a migration treats a target index that was left behind by an earlier migration.
+type MigrationRecovery string
+
+const (
+	// MigrationRecoveryResume reuses the existing target index. The reindex skips documents that were already copied.
+	MigrationRecoveryResume MigrationRecovery = "resume"
+	// MigrationRecoveryRecreateTarget deletes the existing target index before migrating, because the source index
+	// may have changed since the target was populated.
+	MigrationRecoveryRecreateTarget MigrationRecovery = "recreateTarget"
+)
+
+type MigrationStatus string
+
+const (
+	MigrationStatusSucceeded MigrationStatus = "succeeded"
+	MigrationStatusFailed    MigrationStatus = "failed"
+	// MigrationStatusSkipped means that the migration was never started, e.g. because another migration failed first.
+	MigrationStatusSkipped MigrationStatus = "skipped"
+)
+
+type MigrationResult struct {
+	Migration *Migration
+	Status    MigrationStatus
+	Error     error
+}
+
+// MigrationReport describes the outcome of each migration found by RunMigrations.
+type MigrationReport struct {
+	Results []*MigrationResult
+}
+
+func (r *MigrationReport) Succeeded() []*MigrationResult {
+	return r.withStatus(MigrationStatusSucceeded)
+}
+
+func (r *MigrationReport) Failed() []*MigrationResult {
+	return r.withStatus(MigrationStatusFailed)
+}
+
+func (r *MigrationReport) Skipped() []*MigrationResult {
+	return r.withStatus(MigrationStatusSkipped)
+}
+
+func (r *MigrationReport) withStatus(status MigrationStatus) []*MigrationResult {
+	var results []*MigrationResult
+	for _, result := range r.Results {
+		if result.Status == status {
+			results = append(results, result)
+		}
+	}
+
+	return results
 }
 
+// MigrationErrorPolicy determines what happens to the remaining migrations when one fails.
+type MigrationErrorPolicy string
+
+const (
+	// MigrationErrorPolicyFailFast stops starting new migrations after the first failure. Migrations that are already
+	// running are allowed to finish. This is the default policy.
+	MigrationErrorPolicyFailFast MigrationErrorPolicy = "failFast"
+	// MigrationErrorPolicyContinue runs every migration, regardless of failures.
+	MigrationErrorPolicyContinue MigrationErrorPolicy = "continue"
+)
+
+// MigrationStrategy determines how the IndexManager keeps the alias usable while documents are copied to the new index.
+type MigrationStrategy string
+
+const (
+	// MigrationStrategyWriteBlock places a write block on the source index before reindexing, so writes fail until the
+	// migration completes. This is the default strategy.
+	MigrationStrategyWriteBlock MigrationStrategy = "writeBlock"
+	// MigrationStrategyDualWrite makes the target index the write index for the alias before reindexing, so writes are
+	// never blocked. Reads through the alias will see both indices until the migration completes.
+	// Partial updates through the alias fail for documents that haven't been copied to the target index yet, and deleted
+	// documents that haven't been copied yet are restored by the reindex, so document kinds that use the update or
+	// delete APIs should use MigrationStrategyWriteBlock instead.
+	MigrationStrategyDualWrite MigrationStrategy = "dualWrite"
+)
+
 type MigrationConfig struct {
 	// PollInterval is the time to wait between polls of the reindex task endpoint.
 	PollInterval time.Duration
 	// PollAttempts is the number of times that the IndexManager will fetch the task document
 	// to check if the reindex has finished.
 	PollAttempts int
+	// Strategy selects how writes are handled during a migration. Defaults to MigrationStrategyWriteBlock.
+	Strategy MigrationStrategy
+	// CatchUpField is a date field that holds the last time a document was written. It's only used by
+	// MigrationStrategyDualWrite: after the initial reindex, documents in the source index with a value greater than or
+	// equal to the time the migration started are copied if they aren't in the target index yet, to pick up writes that
+	// raced the alias change. Documents already in the target index aren't overwritten. If empty, the catch-up pass is
+	// skipped.
+	CatchUpField string
+	// Reindex controls how documents are copied from the source index to the target index.
+	Reindex *ReindexConfig
+	// DocumentKindReindex overrides fields in Reindex for specific document kinds. Only fields with a non-zero value
+	// take precedence over Reindex.
+	DocumentKindReindex map[string]*ReindexConfig
+	// AllowDowngrade permits migrating an index to an older version than the one it's already at. By default, an index
+	// that's newer than the version in the mappings is left alone, so an older build of the application keeps using it.
+	AllowDowngrade bool
+	// KeepPreviousAliases adds the alias for the previous document kind to the new index when a renamed document kind is
+	// migrated, so that clients using the old name keep working.
+	KeepPreviousAliases bool
+	// Concurrency is the maximum number of migrations that run at the same time. Defaults to 1.
+	Concurrency int
+	// ErrorPolicy controls whether migrations continue after one fails. Defaults to MigrationErrorPolicyFailFast.
+	ErrorPolicy MigrationErrorPolicy
+	// FailOnChecksumMismatch makes GetMigrations return an error when an index was created with the same version as the
+	// mapping in the registry, but different content, i.e. the mapping file was edited without bumping the version.
+	// By default, the mismatch is only logged.
+	FailOnChecksumMismatch bool
+	// Snapshot takes a snapshot of the source index before each migration, so that it can be restored with
+	// Migrator.RestoreSnapshot. By default, no snapshot is taken.
+	Snapshot *SnapshotConfig
+	// Throttle is called between polls of a running reindex task. When it returns true, the task is rethrottled to the
+	// returned number of requests per second (-1 to disable throttling).
+	Throttle ThrottleFunc
+}
+
+type SnapshotConfig struct {
+	// Repository is the name of a snapshot repository that's already registered with the cluster.
+	Repository string
 }
 
+type ReindexConfig struct {
+	// Slices is the number of slices used to parallelize the reindex, or "auto" to let Elasticsearch decide.
+	// Leave empty to use a single slice.
+	Slices string
+	// RequestsPerSecond limits the throughput of the reindex. Zero uses the Elasticsearch default (unlimited).
+	RequestsPerSecond int
+	// BatchSize is the number of documents read from the source index in each batch. Zero uses the Elasticsearch default.
+	BatchSize int
+	// MaxDocs is the maximum number of documents to reindex. Zero reindexes all documents. When it's set, the source
+	// index is kept after a migration instead of being deleted, since it may hold documents that weren't copied.
+	// Migrations of time series and data streams, and Adopt, return an error instead.
+	MaxDocs int
+}
+
+// ThrottleFunc decides whether a running reindex task should be rethrottled, e.g. when the cluster is under pressure.
+type ThrottleFunc func(ctx context.Context, migration *Migration, taskId string) (requestsPerSecond int, rethrottle bool)
+
 type Config struct {
 	// IndexPrefix is used when creating index and alias names, and to tell if a particular index is associated with the
 	// application. The IndexManager only operates on indices with this prefix; in addition, any indices must have the
@@ -55,16 +206,238 @@ type Config struct {
 	MappingsPath string
 	// Migration controls the amount of time the IndexManager will wait for a reindex to complete as part of a migration.
 	Migration *MigrationConfig
+	// NamingStrategy builds and parses index and alias names. Defaults to DefaultNamingStrategy, which creates names
+	// like prefix-version-inner-documentKind.
+	NamingStrategy NamingStrategy
+	// DriftCheck controls what Initialize does when an index's mappings or settings differ from the registry.
+	// Defaults to DriftCheckOff.
+	DriftCheck DriftCheck
+	// DocumentStructs defines document kinds with annotated Go structs, keyed by document kind, in addition to the
+	// mapping files in MappingsPath. See MappingFromStruct for how fields are mapped.
+	DocumentStructs map[string]*DocumentStruct
+	// MappingSources provide additional mappings, like ones that are embedded or registered from Go code. A document
+	// kind can only be defined by one source.
+	MappingSources []MappingSource
+	// Transfer controls how documents are exported and imported.
+	Transfer *TransferConfig
+	// Environment is the name of the environment the application is running in, like "local" or "test".
+	Environment string
+	// SeedEnvironments lists the environments where seed documents are loaded into indices created by the
+	// IndexManager. Seeding is off when Environment isn't in the list.
+	SeedEnvironments []string
+}
+
+type TransferConfig struct {
+	// BatchSize is the number of documents read or written in each request. Defaults to 1000.
+	BatchSize int
+	// Progress is called after each batch with the number of documents transferred so far.
+	Progress TransferProgressFunc
+}
+
+type TransferProgressFunc func(documentKind string, documents int)
+
+// TransferReport describes the outcome of an export or import.
+type TransferReport struct {
+	// Documents is the number of documents that were written.
+	Documents int
+	// Skipped is the number of documents that weren't created because a document with the same id already exists.
+	Skipped int
+	// Failed lists the documents that Elasticsearch rejected during an import.
+	Failed []*TransferError
+}
+
+type TransferError struct {
+	ID     string // empty if the document didn't have an id
+	Reason string
 }
 
+// DriftCheck determines how differences between the indices and the registry are handled on startup.
+type DriftCheck string
+
+const (
+	DriftCheckOff DriftCheck = ""
+	// DriftCheckWarn logs each difference.
+	DriftCheckWarn DriftCheck = "warn"
+	// DriftCheckFail logs each difference, and makes Initialize return an error if there are any.
+	DriftCheckFail DriftCheck = "fail"
+)
+
 type VersionedMapping struct {
 	Version  string                 `json:"version"`
 	Mappings map[string]interface{} `json:"mappings"`
 	Settings map[string]interface{} `json:"settings"`
+	// DependsOn lists other document kinds that must be migrated before this one, e.g. because an enrich policy reads
+	// from them.
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// PreviousKinds lists document kinds that have been renamed to, or merged into, this document kind.
+	// Indices for those kinds are reindexed into the index for this kind, keeping the same inner name.
+	PreviousKinds []string `json:"previousKinds,omitempty"`
+	// TimeSeries stores the document kind in a series of indices behind a write alias, rather than in a single index.
+	TimeSeries *TimeSeriesConfig `json:"timeSeries,omitempty"`
+	// DataStream stores the document kind in a data stream, rather than an aliased index. The mappings must include
+	// a @timestamp field.
+	DataStream *DataStreamConfig `json:"dataStream,omitempty"`
+	// ILMPolicy is the name of a policy in the ilm directory under Config.MappingsPath that's attached to indices
+	// created for the document kind.
+	ILMPolicy string `json:"ilmPolicy,omitempty"`
+	// DefaultPipeline is the name of an ingest pipeline in the pipelines directory under Config.MappingsPath that's set
+	// as the default pipeline for indices created for the document kind.
+	DefaultPipeline string `json:"defaultPipeline,omitempty"`
+}
+
+// DocumentStruct defines a document kind with a Go struct instead of a mapping file.
+type DocumentStruct struct {
+	// Version is the schema version, which should be changed along with the struct to trigger a migration.
+	Version string
+	// Document is a value of the struct, or a pointer to one, like &Build{}.
+	Document interface{}
+	// Settings are the index settings, as they would appear in a mapping file.
+	Settings map[string]interface{}
+}
+
+// MappingsReloadEvent is sent by MappingsRegistry.Watch after reloading the mappings. New mapping versions don't take
+// effect in the cluster until RunMigrations is called, which the application can do when Changed isn't empty.
+type MappingsReloadEvent struct {
+	// Changed lists the document kinds that were added, or whose mapping file, ILM policy, default pipeline, or seed
+	// documents changed, even if the version stayed the same.
+	Changed []string
+	// Removed lists the document kinds that are no longer in the registry.
+	Removed []string
+	// ResourcesChanged is set if any ILM policy, ingest pipeline, or stored script changed, in which case
+	// ResourceManager.InstallResources can be called to apply them.
+	ResourcesChanged bool
+	// Err is set if the mappings couldn't be reloaded, in which case the registry still has the previous mappings.
+	Err error
+}
+
+// VersionedPolicy is an index lifecycle management policy, read from a JSON file in the ilm directory under
+// Config.MappingsPath. The policy is only updated in the cluster when the version changes.
+type VersionedPolicy struct {
+	Version string                 `json:"version"`
+	Policy  map[string]interface{} `json:"policy"`
+}
+
+// VersionedPipeline is an ingest pipeline, read from a JSON file in the pipelines directory under Config.MappingsPath.
+// The pipeline is updated in the cluster when the version changes, or when it was modified in the cluster.
+type VersionedPipeline struct {
+	Version  string                 `json:"version"`
+	Pipeline map[string]interface{} `json:"pipeline"`
+}
+
+// StoredScript is read from the scripts directory under Config.MappingsPath, either from a .painless file containing
+// the source, or a JSON file with the lang and source. It's updated in the cluster whenever the source differs.
+type StoredScript struct {
+	Lang   string `json:"lang"`
+	Source string `json:"source"`
+}
+
+type ResourceType string
+
+const (
+	ResourceTypeILMPolicy    ResourceType = "ilmPolicy"
+	ResourceTypePipeline     ResourceType = "pipeline"
+	ResourceTypeStoredScript ResourceType = "storedScript"
+)
+
+type ResourceDriftStatus string
+
+const (
+	// ResourceDriftMissing means the resource doesn't exist in the cluster.
+	ResourceDriftMissing ResourceDriftStatus = "missing"
+	// ResourceDriftOutdated means the cluster has an older version of the resource.
+	ResourceDriftOutdated ResourceDriftStatus = "outdated"
+	// ResourceDriftNewer means the cluster has a newer version of the resource, e.g. installed by a newer build of the application.
+	ResourceDriftNewer ResourceDriftStatus = "newer"
+	// ResourceDriftModified means the resource in the cluster has the same version, but different contents.
+	ResourceDriftModified ResourceDriftStatus = "modified"
+)
+
+// ResourceDrift describes a resource in the registry that doesn't match the cluster.
+type ResourceDrift struct {
+	Type   ResourceType
+	Name   string // the name in the cluster
+	Status ResourceDriftStatus
+	// Version is the version in the registry, and InstalledVersion the one in the cluster. Stored scripts aren't versioned.
+	Version          string
+	InstalledVersion string
+}
+
+// SettingsChange is an index setting whose value in the cluster differs from the one in the registry.
+type SettingsChange struct {
+	Index   string
+	Setting string // the flattened name, e.g. index.number_of_replicas
+	// Current is nil if the setting isn't set on the index. Values are strings, or lists of strings, as Elasticsearch returns them.
+	Current interface{}
+	Desired interface{}
+	// Dynamic settings are updated in place. Static settings, like the number of shards or analyzers, need a migration.
+	Dynamic bool
+}
+
+// IndexDriftType categorizes a difference between an index and the registry.
+type IndexDriftType string
+
+const (
+	// IndexDriftExtraField is a field in the index that isn't in the registry, e.g. because it was added by dynamic mapping
+	// or a manual update.
+	IndexDriftExtraField IndexDriftType = "extraField"
+	// IndexDriftChangedType is a field that has a different type in the index than in the registry.
+	IndexDriftChangedType IndexDriftType = "changedType"
+	// IndexDriftMissingField is a field in the registry that isn't in the index.
+	IndexDriftMissingField IndexDriftType = "missingField"
+	// IndexDriftSetting is a setting whose value differs. See SettingsChange.
+	IndexDriftSetting IndexDriftType = "setting"
+)
+
+type IndexDrift struct {
+	Index string
+	Type  IndexDriftType
+	// Name is the full path of the field (e.g., user.name.keyword), or the flattened name of the setting.
+	Name string
+	// Expected is the field type or setting value in the registry, and Actual the one in the index. Either is nil if
+	// the field or setting is missing.
+	Expected interface{}
+	Actual   interface{}
+}
+
+// DataStreamConfig controls how data streams are updated when the mapping version changes. The data stream is named
+// like an alias (e.g., myapp-inner-documentKind) and its backing indices are managed by Elasticsearch.
+type DataStreamConfig struct {
+	// ReindexOnMigration copies the documents in backing indices with an older mapping back into the data stream, and
+	// then deletes those backing indices. By default, only backing indices created after the migration get the new mapping.
+	ReindexOnMigration bool `json:"reindexOnMigration,omitempty"`
+}
+
+// TimeSeriesConfig controls how indices for a time series document kind are named and rolled over.
+// Each index in the series is named after the usual index name, followed by a generation number (e.g., myapp-v1-audit-000001).
+type TimeSeriesConfig struct {
+	// DateFormat adds the date an index was created before the generation number, using a Go time layout like "2006.01.02".
+	// The layout must not contain the naming delimiter, which is "-" by default.
+	DateFormat string `json:"dateFormat,omitempty"`
+	// Rollover holds the conditions used by IndexRepository.Rollover. Without any conditions, every call rolls over.
+	Rollover *RolloverConditions `json:"rollover,omitempty"`
+	// ReindexOnMigration copies older indices in the series into indices with the current mapping when the version changes.
+	// By default, only new indices in the series get the new mapping.
+	ReindexOnMigration bool `json:"reindexOnMigration,omitempty"`
+}
+
+// RolloverConditions are passed to the Elasticsearch rollover API. Any condition being met triggers a rollover.
+type RolloverConditions struct {
+	MaxAge  string `json:"maxAge,omitempty"`
+	MaxSize string `json:"maxSize,omitempty"`
+	MaxDocs int    `json:"maxDocs,omitempty"`
+}
+
+type RolloverResult struct {
+	// RolledOver is false if none of the conditions were met.
+	RolledOver bool
+	OldIndex   string
+	NewIndex   string
 }
 
 type IndexName struct {
 	DocumentKind string
 	Version      string
 	Inner        string // the parts that aren't the prefix, document kind, or version
+	Generation   int    // the position of the index in a time series, or zero
+	Date         string // the formatted date of a time series index, if the series is dated
 }
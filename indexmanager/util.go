@@ -16,12 +16,16 @@ package indexmanager
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 
+	"github.com/elastic/go-elasticsearch/v7"
 	"github.com/elastic/go-elasticsearch/v7/esapi"
+	. "github.com/rode/es-index-manager/indexmanager/internal"
 )
 
 func decodeResponse(r io.ReadCloser, i interface{}) error {
@@ -53,3 +57,41 @@ func getErrorFromESResponse(res *esapi.Response, err error) error {
 	}
 	return nil
 }
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getApplicationIndices returns the indices that start with the prefix and have it as the _meta.type in their mappings.
+func getApplicationIndices(ctx context.Context, client *elasticsearch.Client, indexPrefix string) (map[string]EsIndex, error) {
+	res, err := client.Indices.Get([]string{ElasticsearchAllIndices}, client.Indices.Get.WithContext(ctx))
+	if err := getErrorFromESResponse(res, err); err != nil {
+		return nil, err
+	}
+
+	allIndices := map[string]EsIndex{}
+
+	if err := decodeResponse(res.Body, &allIndices); err != nil {
+		return nil, err
+	}
+
+	applicationIndices := map[string]EsIndex{}
+	for indexName, indexValue := range allIndices {
+		if indexValue.Mappings == nil {
+			continue
+		}
+
+		meta := indexValue.Mappings.Meta
+		if strings.HasPrefix(indexName, indexPrefix) && meta != nil && meta.Type == indexPrefix {
+			applicationIndices[indexName] = indexValue
+		}
+	}
+
+	return applicationIndices, nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// v1alpha1, v2beta3
+	kubernetesVersionPattern = regexp.MustCompile(`^v(\d+)(alpha|beta)(\d+)$`)
+	// v1, 1.2, v1.2.3-rc.1+build.5
+	semanticVersionPattern = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+)
+
+type version struct {
+	parts      [3]int
+	prerelease []string
+}
+
+// CompareVersions returns -1 if a is older than b, 1 if a is newer than b, and 0 if they're equivalent.
+// Versions may be Kubernetes-style (v1alpha1 < v1beta1 < v1 < v2) or semantic versions (1.0.0-rc.1 < 1.0.0 < v1.1.0).
+// An error is returned if either version can't be parsed.
+func CompareVersions(a, b string) (int, error) {
+	versionA, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+
+	versionB, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range versionA.parts {
+		if c := compareInts(versionA.parts[i], versionB.parts[i]); c != 0 {
+			return c, nil
+		}
+	}
+
+	return comparePrerelease(versionA.prerelease, versionB.prerelease), nil
+}
+
+func parseVersion(raw string) (*version, error) {
+	if match := kubernetesVersionPattern.FindStringSubmatch(raw); match != nil {
+		major, _ := strconv.Atoi(match[1])
+
+		return &version{
+			parts:      [3]int{major, 0, 0},
+			prerelease: []string{match[2], match[3]},
+		}, nil
+	}
+
+	match := semanticVersionPattern.FindStringSubmatch(raw)
+	if match == nil {
+		return nil, fmt.Errorf(`unable to parse version "%s"`, raw)
+	}
+
+	v := &version{}
+	for i := 0; i < 3; i++ {
+		if match[i+1] != "" {
+			v.parts[i], _ = strconv.Atoi(match[i+1])
+		}
+	}
+
+	if match[4] != "" {
+		v.prerelease = strings.Split(match[4], ".")
+	}
+
+	return v, nil
+}
+
+// comparePrerelease follows the semver precedence rules: a version without a prerelease is newer than one with,
+// numeric identifiers are compared numerically, and alphanumeric identifiers are compared lexically.
+func comparePrerelease(a, b []string) int {
+	if len(a) == 0 || len(b) == 0 {
+		return compareInts(len(b), len(a))
+	}
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		numberA, errA := strconv.Atoi(a[i])
+		numberB, errB := strconv.Atoi(b[i])
+
+		switch {
+		case errA == nil && errB == nil:
+			if c := compareInts(numberA, numberB); c != 0 {
+				return c
+			}
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		default:
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	return compareInts(len(a), len(b))
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
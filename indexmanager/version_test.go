@@ -0,0 +1,55 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	. "github.com/rode/es-index-manager/indexmanager"
+)
+
+var _ = Describe("CompareVersions", func() {
+	DescribeTable("comparing versions",
+		func(a, b string, expected int) {
+			actual, err := CompareVersions(a, b)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(actual).To(Equal(expected))
+		},
+		Entry("equal kubernetes versions", "v1alpha1", "v1alpha1", 0),
+		Entry("alpha before beta", "v1alpha1", "v1beta1", -1),
+		Entry("beta before GA", "v1beta2", "v1", -1),
+		Entry("GA before next major", "v1", "v2", -1),
+		Entry("next major alpha after GA", "v2alpha1", "v1", 1),
+		Entry("alpha numbers are compared numerically", "v1alpha10", "v1alpha2", 1),
+		Entry("equal semantic versions", "1.2.3", "v1.2.3", 0),
+		Entry("patch versions", "1.2.3", "1.2.4", -1),
+		Entry("minor versions", "1.10.0", "1.9.0", 1),
+		Entry("prerelease before release", "1.0.0-rc.1", "1.0.0", -1),
+		Entry("prerelease identifiers", "1.0.0-alpha.2", "1.0.0-alpha.10", -1),
+		Entry("build metadata is ignored", "1.0.0+abc", "1.0.0", 0),
+		Entry("kubernetes and semantic versions", "v1", "1.0.0", 0),
+	)
+
+	When("a version can't be parsed", func() {
+		It("should return an error", func() {
+			_, err := CompareVersions("v1", fake.Word())
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("unable to parse version"))
+		})
+	})
+})
@@ -0,0 +1,143 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// mappingsReloadDelay is how long the registry waits after a change before reloading, so that an editor saving
+// several files, or writing one in several steps, causes a single reload.
+const mappingsReloadDelay = 250 * time.Millisecond
+
+func (mr *mappingsRegistry) Watch(ctx context.Context) (<-chan *MappingsReloadEvent, error) {
+	if mr.directory == "" {
+		return nil, errors.New("only mappings read from a directory on disk, using NewDirectoryMappingsRegistry, can be watched")
+	}
+	mappingsPath := filepath.Join(mr.directory, mr.config.MappingsPath)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("error creating file watcher: %s", err)
+	}
+
+	if err := watcher.Add(mappingsPath); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("error watching mappings directory: %s", err)
+	}
+
+	// the watcher isn't recursive, so the resource directories are watched separately
+	for _, directory := range []string{ilmDirectory, pipelinesDirectory, scriptsDirectory, seedsDirectory} {
+		_ = watcher.Add(filepath.Join(mappingsPath, directory))
+	}
+
+	events := make(chan *MappingsReloadEvent)
+	go func() {
+		defer close(events)
+		defer watcher.Close()
+
+		var reload <-chan time.Time
+		for {
+			var event *MappingsReloadEvent
+			select {
+			case <-ctx.Done():
+				return
+			case change, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				if info, err := os.Stat(change.Name); change.Op&fsnotify.Create != 0 && err == nil && info.IsDir() {
+					_ = watcher.Add(change.Name)
+				}
+				reload = time.After(mappingsReloadDelay)
+				continue
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				event = &MappingsReloadEvent{Err: fmt.Errorf("error watching mappings: %s", err)}
+			case <-reload:
+				reload = nil
+				event = mr.reload()
+			}
+
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// reload loads the mappings like LoadMappings, and describes what changed. The diff is against the state that the new
+// one replaced, so a concurrent call to LoadMappings can't cause a change to be missed or reported twice.
+func (mr *mappingsRegistry) reload() *MappingsReloadEvent {
+	current, err := mr.loadState()
+	if err != nil {
+		return &MappingsReloadEvent{Err: fmt.Errorf("error reloading mappings: %s", err)}
+	}
+
+	previous := mr.swap(current)
+	event := &MappingsReloadEvent{
+		ResourcesChanged: !reflect.DeepEqual(previous.policies, current.policies) ||
+			!reflect.DeepEqual(previous.pipelines, current.pipelines) ||
+			!reflect.DeepEqual(previous.scripts, current.scripts),
+	}
+	for _, documentKind := range sortedKeys(current.mappings) {
+		if documentKindChanged(previous, current, documentKind) {
+			event.Changed = append(event.Changed, documentKind)
+		}
+	}
+
+	for _, documentKind := range sortedKeys(previous.mappings) {
+		if _, ok := current.mappings[documentKind]; !ok {
+			event.Removed = append(event.Removed, documentKind)
+		}
+	}
+
+	return event
+}
+
+// documentKindChanged checks if the document kind is new, or if anything used to create its indices changed: the version,
+// the mappings and static settings (by their checksum), the rest of the mapping file, its ILM policy and default
+// pipeline, or its seed documents.
+func documentKindChanged(previous, current *registryState, documentKind string) bool {
+	before, ok := previous.mappings[documentKind]
+	if !ok {
+		return true
+	}
+
+	after := current.mappings[documentKind]
+	if before.Version != after.Version || MappingChecksum(before) != MappingChecksum(after) || !reflect.DeepEqual(before, after) {
+		return true
+	}
+
+	return !reflect.DeepEqual(previous.policies[after.ILMPolicy], current.policies[after.ILMPolicy]) ||
+		!reflect.DeepEqual(previous.pipelines[after.DefaultPipeline], current.pipelines[after.DefaultPipeline]) ||
+		!bytes.Equal(previous.seeds[documentKind], current.seeds[documentKind])
+}
@@ -0,0 +1,218 @@
+// Copyright 2021 The Rode Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing/fstest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/rode/es-index-manager/indexmanager"
+)
+
+var _ = Describe("Watch", func() {
+	var (
+		ctx          context.Context
+		cancel       context.CancelFunc
+		workingDir   string
+		mappingsPath string
+		registry     MappingsRegistry
+
+		actualEvents <-chan *MappingsReloadEvent
+		actualError  error
+	)
+
+	writeMapping := func(documentKind string, mapping interface{}) {
+		data, err := json.Marshal(mapping)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(mappingsPath, documentKind+".json"), data, 0644)).To(Succeed())
+	}
+
+	nextEvent := func() *MappingsReloadEvent {
+		var event *MappingsReloadEvent
+		Eventually(actualEvents, 5*time.Second).Should(Receive(&event))
+
+		return event
+	}
+
+	BeforeEach(func() {
+		var err error
+		workingDir, err = os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+
+		tempDir, err := os.MkdirTemp("", "mappings")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir(tempDir)).To(Succeed())
+
+		mappingsPath = "mappings"
+		Expect(os.Mkdir(mappingsPath, 0755)).To(Succeed())
+		writeMapping("build", &VersionedMapping{Version: "v1"})
+		writeMapping("policy", &VersionedMapping{Version: "v1"})
+
+		ctx, cancel = context.WithCancel(context.Background())
+		registry = NewDirectoryMappingsRegistry(&Config{IndexPrefix: fake.Word(), MappingsPath: mappingsPath}, ".")
+		Expect(registry.LoadMappings()).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		actualEvents, actualError = registry.Watch(ctx)
+	})
+
+	AfterEach(func() {
+		cancel()
+		tempDir, _ := os.Getwd()
+		Expect(os.Chdir(workingDir)).To(Succeed())
+		Expect(os.RemoveAll(tempDir)).To(Succeed())
+	})
+
+	It("should reload the mappings when a file changes", func() {
+		Expect(actualError).NotTo(HaveOccurred())
+
+		writeMapping("build", &VersionedMapping{Version: "v2"})
+		writeMapping("event", &VersionedMapping{Version: "v1"})
+		Expect(os.Remove(filepath.Join(mappingsPath, "policy.json"))).To(Succeed())
+
+		event := nextEvent()
+		Expect(event).To(Equal(&MappingsReloadEvent{
+			Changed: []string{"build", "event"},
+			Removed: []string{"policy"},
+		}))
+		Expect(registry.Version("build")).To(Equal("v2"))
+		Expect(registry.DocumentKinds()).To(Equal([]string{"build", "event"}))
+	})
+
+	It("should report document kinds whose mappings changed without a new version", func() {
+		Expect(actualError).NotTo(HaveOccurred())
+
+		writeMapping("build", &VersionedMapping{
+			Version:  "v1",
+			Mappings: map[string]interface{}{"properties": map[string]interface{}{"name": map[string]interface{}{"type": "keyword"}}},
+		})
+
+		event := nextEvent()
+		Expect(event).To(Equal(&MappingsReloadEvent{
+			Changed: []string{"build"},
+		}))
+	})
+
+	It("should report resource changes", func() {
+		Expect(actualError).NotTo(HaveOccurred())
+
+		ilmPath := filepath.Join(mappingsPath, "ilm")
+		Expect(os.Mkdir(ilmPath, 0755)).To(Succeed())
+		Expect(nextEvent().ResourcesChanged).To(BeFalse())
+
+		policy := []byte(`{"version":"v1","policy":{"phases":{}}}`)
+		Expect(os.WriteFile(filepath.Join(ilmPath, "retention.json"), policy, 0644)).To(Succeed())
+
+		event := nextEvent()
+		Expect(event.ResourcesChanged).To(BeTrue())
+		Expect(event.Changed).To(BeEmpty())
+	})
+
+	It("should keep the previous mappings when a file is invalid", func() {
+		Expect(actualError).NotTo(HaveOccurred())
+
+		Expect(os.WriteFile(filepath.Join(mappingsPath, "build.json"), []byte("{"), 0644)).To(Succeed())
+
+		event := nextEvent()
+		Expect(event.Err).To(MatchError(ContainSubstring("error reloading mappings")))
+		Expect(registry.Version("build")).To(Equal("v1"))
+	})
+
+	It("should watch resource directories that are created later", func() {
+		Expect(actualError).NotTo(HaveOccurred())
+
+		seedsPath := filepath.Join(mappingsPath, "seeds")
+		Expect(os.Mkdir(seedsPath, 0755)).To(Succeed())
+		nextEvent()
+
+		seeds := []byte(`{"_id":"a","_source":{}}`)
+		Expect(os.WriteFile(filepath.Join(seedsPath, "build.ndjson"), seeds, 0644)).To(Succeed())
+
+		Eventually(func() []byte {
+			return registry.Seeds("build")
+		}, 5*time.Second).Should(Equal(seeds))
+	})
+
+	It("should stop watching when the context is done", func() {
+		Expect(actualError).NotTo(HaveOccurred())
+
+		cancel()
+
+		Eventually(actualEvents).Should(BeClosed())
+	})
+
+	When("the registry reads from another directory", func() {
+		BeforeEach(func() {
+			directory, err := os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+
+			registry = NewDirectoryMappingsRegistry(&Config{IndexPrefix: fake.Word(), MappingsPath: mappingsPath}, directory)
+			Expect(registry.LoadMappings()).To(Succeed())
+			// the registry shouldn't depend on the working directory
+			Expect(os.Chdir(os.TempDir())).To(Succeed())
+			mappingsPath = filepath.Join(directory, mappingsPath)
+		})
+
+		AfterEach(func() {
+			Expect(os.Chdir(filepath.Dir(mappingsPath))).To(Succeed())
+		})
+
+		It("should watch the mappings in that directory", func() {
+			Expect(actualError).NotTo(HaveOccurred())
+
+			writeMapping("build", &VersionedMapping{Version: "v2"})
+
+			Expect(nextEvent().Changed).To(Equal([]string{"build"}))
+			Expect(registry.Version("build")).To(Equal("v2"))
+		})
+	})
+
+	When("the filesystem is from os.DirFS but the directory wasn't given", func() {
+		BeforeEach(func() {
+			registry = NewMappingsRegistry(&Config{IndexPrefix: fake.Word(), MappingsPath: mappingsPath}, os.DirFS("."))
+		})
+
+		It("should return an error", func() {
+			Expect(actualError).To(MatchError(ContainSubstring("NewDirectoryMappingsRegistry")))
+		})
+	})
+
+	When("the mappings aren't read from disk", func() {
+		BeforeEach(func() {
+			registry = NewMappingsRegistry(&Config{IndexPrefix: fake.Word(), MappingsPath: mappingsPath}, fstest.MapFS{})
+		})
+
+		It("should return an error", func() {
+			Expect(actualError).To(MatchError(ContainSubstring("NewDirectoryMappingsRegistry")))
+		})
+	})
+
+	When("the mappings directory doesn't exist", func() {
+		BeforeEach(func() {
+			Expect(os.RemoveAll(mappingsPath)).To(Succeed())
+		})
+
+		It("should return an error", func() {
+			Expect(actualError).To(MatchError(ContainSubstring("error watching mappings directory")))
+		})
+	})
+})
@@ -0,0 +1,120 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rode/es-index-manager/indexmanager"
+)
+
+type FakeAdopter struct {
+	AdoptStub        func(context.Context, string, string, string, bool) error
+	adoptMutex       sync.RWMutex
+	adoptArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 bool
+	}
+	adoptReturns struct {
+		result1 error
+	}
+	adoptReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeAdopter) Adopt(arg1 context.Context, arg2 string, arg3 string, arg4 string, arg5 bool) error {
+	fake.adoptMutex.Lock()
+	ret, specificReturn := fake.adoptReturnsOnCall[len(fake.adoptArgsForCall)]
+	fake.adoptArgsForCall = append(fake.adoptArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 bool
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.AdoptStub
+	fakeReturns := fake.adoptReturns
+	fake.recordInvocation("Adopt", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.adoptMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeAdopter) AdoptCallCount() int {
+	fake.adoptMutex.RLock()
+	defer fake.adoptMutex.RUnlock()
+	return len(fake.adoptArgsForCall)
+}
+
+func (fake *FakeAdopter) AdoptCalls(stub func(context.Context, string, string, string, bool) error) {
+	fake.adoptMutex.Lock()
+	defer fake.adoptMutex.Unlock()
+	fake.AdoptStub = stub
+}
+
+func (fake *FakeAdopter) AdoptArgsForCall(i int) (context.Context, string, string, string, bool) {
+	fake.adoptMutex.RLock()
+	defer fake.adoptMutex.RUnlock()
+	argsForCall := fake.adoptArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeAdopter) AdoptReturns(result1 error) {
+	fake.adoptMutex.Lock()
+	defer fake.adoptMutex.Unlock()
+	fake.AdoptStub = nil
+	fake.adoptReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAdopter) AdoptReturnsOnCall(i int, result1 error) {
+	fake.adoptMutex.Lock()
+	defer fake.adoptMutex.Unlock()
+	fake.AdoptStub = nil
+	if fake.adoptReturnsOnCall == nil {
+		fake.adoptReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.adoptReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAdopter) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.adoptMutex.RLock()
+	defer fake.adoptMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeAdopter) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ indexmanager.Adopter = new(FakeAdopter)
@@ -0,0 +1,292 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package mocks
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/rode/es-index-manager/indexmanager"
+)
+
+type FakeDocumentTransfer struct {
+	ExportStub        func(context.Context, string, string, io.Writer) (*indexmanager.TransferReport, error)
+	exportMutex       sync.RWMutex
+	exportArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 io.Writer
+	}
+	exportReturns struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}
+	exportReturnsOnCall map[int]struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}
+	ImportStub        func(context.Context, string, string, io.Reader) (*indexmanager.TransferReport, error)
+	importMutex       sync.RWMutex
+	importArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 io.Reader
+	}
+	importReturns struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}
+	importReturnsOnCall map[int]struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}
+	SeedStub        func(context.Context, string, string) (*indexmanager.TransferReport, error)
+	seedMutex       sync.RWMutex
+	seedArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	seedReturns struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}
+	seedReturnsOnCall map[int]struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDocumentTransfer) Export(arg1 context.Context, arg2 string, arg3 string, arg4 io.Writer) (*indexmanager.TransferReport, error) {
+	fake.exportMutex.Lock()
+	ret, specificReturn := fake.exportReturnsOnCall[len(fake.exportArgsForCall)]
+	fake.exportArgsForCall = append(fake.exportArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 io.Writer
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.ExportStub
+	fakeReturns := fake.exportReturns
+	fake.recordInvocation("Export", []interface{}{arg1, arg2, arg3, arg4})
+	fake.exportMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDocumentTransfer) ExportCallCount() int {
+	fake.exportMutex.RLock()
+	defer fake.exportMutex.RUnlock()
+	return len(fake.exportArgsForCall)
+}
+
+func (fake *FakeDocumentTransfer) ExportCalls(stub func(context.Context, string, string, io.Writer) (*indexmanager.TransferReport, error)) {
+	fake.exportMutex.Lock()
+	defer fake.exportMutex.Unlock()
+	fake.ExportStub = stub
+}
+
+func (fake *FakeDocumentTransfer) ExportArgsForCall(i int) (context.Context, string, string, io.Writer) {
+	fake.exportMutex.RLock()
+	defer fake.exportMutex.RUnlock()
+	argsForCall := fake.exportArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeDocumentTransfer) ExportReturns(result1 *indexmanager.TransferReport, result2 error) {
+	fake.exportMutex.Lock()
+	defer fake.exportMutex.Unlock()
+	fake.ExportStub = nil
+	fake.exportReturns = struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDocumentTransfer) ExportReturnsOnCall(i int, result1 *indexmanager.TransferReport, result2 error) {
+	fake.exportMutex.Lock()
+	defer fake.exportMutex.Unlock()
+	fake.ExportStub = nil
+	if fake.exportReturnsOnCall == nil {
+		fake.exportReturnsOnCall = make(map[int]struct {
+			result1 *indexmanager.TransferReport
+			result2 error
+		})
+	}
+	fake.exportReturnsOnCall[i] = struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDocumentTransfer) Import(arg1 context.Context, arg2 string, arg3 string, arg4 io.Reader) (*indexmanager.TransferReport, error) {
+	fake.importMutex.Lock()
+	ret, specificReturn := fake.importReturnsOnCall[len(fake.importArgsForCall)]
+	fake.importArgsForCall = append(fake.importArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 io.Reader
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.ImportStub
+	fakeReturns := fake.importReturns
+	fake.recordInvocation("Import", []interface{}{arg1, arg2, arg3, arg4})
+	fake.importMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDocumentTransfer) ImportCallCount() int {
+	fake.importMutex.RLock()
+	defer fake.importMutex.RUnlock()
+	return len(fake.importArgsForCall)
+}
+
+func (fake *FakeDocumentTransfer) ImportCalls(stub func(context.Context, string, string, io.Reader) (*indexmanager.TransferReport, error)) {
+	fake.importMutex.Lock()
+	defer fake.importMutex.Unlock()
+	fake.ImportStub = stub
+}
+
+func (fake *FakeDocumentTransfer) ImportArgsForCall(i int) (context.Context, string, string, io.Reader) {
+	fake.importMutex.RLock()
+	defer fake.importMutex.RUnlock()
+	argsForCall := fake.importArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeDocumentTransfer) ImportReturns(result1 *indexmanager.TransferReport, result2 error) {
+	fake.importMutex.Lock()
+	defer fake.importMutex.Unlock()
+	fake.ImportStub = nil
+	fake.importReturns = struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDocumentTransfer) ImportReturnsOnCall(i int, result1 *indexmanager.TransferReport, result2 error) {
+	fake.importMutex.Lock()
+	defer fake.importMutex.Unlock()
+	fake.ImportStub = nil
+	if fake.importReturnsOnCall == nil {
+		fake.importReturnsOnCall = make(map[int]struct {
+			result1 *indexmanager.TransferReport
+			result2 error
+		})
+	}
+	fake.importReturnsOnCall[i] = struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDocumentTransfer) Seed(arg1 context.Context, arg2 string, arg3 string) (*indexmanager.TransferReport, error) {
+	fake.seedMutex.Lock()
+	ret, specificReturn := fake.seedReturnsOnCall[len(fake.seedArgsForCall)]
+	fake.seedArgsForCall = append(fake.seedArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.SeedStub
+	fakeReturns := fake.seedReturns
+	fake.recordInvocation("Seed", []interface{}{arg1, arg2, arg3})
+	fake.seedMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDocumentTransfer) SeedCallCount() int {
+	fake.seedMutex.RLock()
+	defer fake.seedMutex.RUnlock()
+	return len(fake.seedArgsForCall)
+}
+
+func (fake *FakeDocumentTransfer) SeedCalls(stub func(context.Context, string, string) (*indexmanager.TransferReport, error)) {
+	fake.seedMutex.Lock()
+	defer fake.seedMutex.Unlock()
+	fake.SeedStub = stub
+}
+
+func (fake *FakeDocumentTransfer) SeedArgsForCall(i int) (context.Context, string, string) {
+	fake.seedMutex.RLock()
+	defer fake.seedMutex.RUnlock()
+	argsForCall := fake.seedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeDocumentTransfer) SeedReturns(result1 *indexmanager.TransferReport, result2 error) {
+	fake.seedMutex.Lock()
+	defer fake.seedMutex.Unlock()
+	fake.SeedStub = nil
+	fake.seedReturns = struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDocumentTransfer) SeedReturnsOnCall(i int, result1 *indexmanager.TransferReport, result2 error) {
+	fake.seedMutex.Lock()
+	defer fake.seedMutex.Unlock()
+	fake.SeedStub = nil
+	if fake.seedReturnsOnCall == nil {
+		fake.seedReturnsOnCall = make(map[int]struct {
+			result1 *indexmanager.TransferReport
+			result2 error
+		})
+	}
+	fake.seedReturnsOnCall[i] = struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDocumentTransfer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.exportMutex.RLock()
+	defer fake.exportMutex.RUnlock()
+	fake.importMutex.RLock()
+	defer fake.importMutex.RUnlock()
+	fake.seedMutex.RLock()
+	defer fake.seedMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeDocumentTransfer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ indexmanager.DocumentTransfer = new(FakeDocumentTransfer)
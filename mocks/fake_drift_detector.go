@@ -0,0 +1,191 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rode/es-index-manager/indexmanager"
+)
+
+type FakeDriftDetector struct {
+	CheckDriftStub        func(context.Context) error
+	checkDriftMutex       sync.RWMutex
+	checkDriftArgsForCall []struct {
+		arg1 context.Context
+	}
+	checkDriftReturns struct {
+		result1 error
+	}
+	checkDriftReturnsOnCall map[int]struct {
+		result1 error
+	}
+	IndexDriftStub        func(context.Context) ([]*indexmanager.IndexDrift, error)
+	indexDriftMutex       sync.RWMutex
+	indexDriftArgsForCall []struct {
+		arg1 context.Context
+	}
+	indexDriftReturns struct {
+		result1 []*indexmanager.IndexDrift
+		result2 error
+	}
+	indexDriftReturnsOnCall map[int]struct {
+		result1 []*indexmanager.IndexDrift
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDriftDetector) CheckDrift(arg1 context.Context) error {
+	fake.checkDriftMutex.Lock()
+	ret, specificReturn := fake.checkDriftReturnsOnCall[len(fake.checkDriftArgsForCall)]
+	fake.checkDriftArgsForCall = append(fake.checkDriftArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.CheckDriftStub
+	fakeReturns := fake.checkDriftReturns
+	fake.recordInvocation("CheckDrift", []interface{}{arg1})
+	fake.checkDriftMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeDriftDetector) CheckDriftCallCount() int {
+	fake.checkDriftMutex.RLock()
+	defer fake.checkDriftMutex.RUnlock()
+	return len(fake.checkDriftArgsForCall)
+}
+
+func (fake *FakeDriftDetector) CheckDriftCalls(stub func(context.Context) error) {
+	fake.checkDriftMutex.Lock()
+	defer fake.checkDriftMutex.Unlock()
+	fake.CheckDriftStub = stub
+}
+
+func (fake *FakeDriftDetector) CheckDriftArgsForCall(i int) context.Context {
+	fake.checkDriftMutex.RLock()
+	defer fake.checkDriftMutex.RUnlock()
+	argsForCall := fake.checkDriftArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeDriftDetector) CheckDriftReturns(result1 error) {
+	fake.checkDriftMutex.Lock()
+	defer fake.checkDriftMutex.Unlock()
+	fake.CheckDriftStub = nil
+	fake.checkDriftReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDriftDetector) CheckDriftReturnsOnCall(i int, result1 error) {
+	fake.checkDriftMutex.Lock()
+	defer fake.checkDriftMutex.Unlock()
+	fake.CheckDriftStub = nil
+	if fake.checkDriftReturnsOnCall == nil {
+		fake.checkDriftReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.checkDriftReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDriftDetector) IndexDrift(arg1 context.Context) ([]*indexmanager.IndexDrift, error) {
+	fake.indexDriftMutex.Lock()
+	ret, specificReturn := fake.indexDriftReturnsOnCall[len(fake.indexDriftArgsForCall)]
+	fake.indexDriftArgsForCall = append(fake.indexDriftArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.IndexDriftStub
+	fakeReturns := fake.indexDriftReturns
+	fake.recordInvocation("IndexDrift", []interface{}{arg1})
+	fake.indexDriftMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDriftDetector) IndexDriftCallCount() int {
+	fake.indexDriftMutex.RLock()
+	defer fake.indexDriftMutex.RUnlock()
+	return len(fake.indexDriftArgsForCall)
+}
+
+func (fake *FakeDriftDetector) IndexDriftCalls(stub func(context.Context) ([]*indexmanager.IndexDrift, error)) {
+	fake.indexDriftMutex.Lock()
+	defer fake.indexDriftMutex.Unlock()
+	fake.IndexDriftStub = stub
+}
+
+func (fake *FakeDriftDetector) IndexDriftArgsForCall(i int) context.Context {
+	fake.indexDriftMutex.RLock()
+	defer fake.indexDriftMutex.RUnlock()
+	argsForCall := fake.indexDriftArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeDriftDetector) IndexDriftReturns(result1 []*indexmanager.IndexDrift, result2 error) {
+	fake.indexDriftMutex.Lock()
+	defer fake.indexDriftMutex.Unlock()
+	fake.IndexDriftStub = nil
+	fake.indexDriftReturns = struct {
+		result1 []*indexmanager.IndexDrift
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDriftDetector) IndexDriftReturnsOnCall(i int, result1 []*indexmanager.IndexDrift, result2 error) {
+	fake.indexDriftMutex.Lock()
+	defer fake.indexDriftMutex.Unlock()
+	fake.IndexDriftStub = nil
+	if fake.indexDriftReturnsOnCall == nil {
+		fake.indexDriftReturnsOnCall = make(map[int]struct {
+			result1 []*indexmanager.IndexDrift
+			result2 error
+		})
+	}
+	fake.indexDriftReturnsOnCall[i] = struct {
+		result1 []*indexmanager.IndexDrift
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDriftDetector) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.checkDriftMutex.RLock()
+	defer fake.checkDriftMutex.RUnlock()
+	fake.indexDriftMutex.RLock()
+	defer fake.indexDriftMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeDriftDetector) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ indexmanager.DriftDetector = new(FakeDriftDetector)
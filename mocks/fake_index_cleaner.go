@@ -0,0 +1,119 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rode/es-index-manager/indexmanager"
+)
+
+type FakeIndexCleaner struct {
+	CleanupStub        func(context.Context, *indexmanager.CleanupOptions) (*indexmanager.CleanupReport, error)
+	cleanupMutex       sync.RWMutex
+	cleanupArgsForCall []struct {
+		arg1 context.Context
+		arg2 *indexmanager.CleanupOptions
+	}
+	cleanupReturns struct {
+		result1 *indexmanager.CleanupReport
+		result2 error
+	}
+	cleanupReturnsOnCall map[int]struct {
+		result1 *indexmanager.CleanupReport
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeIndexCleaner) Cleanup(arg1 context.Context, arg2 *indexmanager.CleanupOptions) (*indexmanager.CleanupReport, error) {
+	fake.cleanupMutex.Lock()
+	ret, specificReturn := fake.cleanupReturnsOnCall[len(fake.cleanupArgsForCall)]
+	fake.cleanupArgsForCall = append(fake.cleanupArgsForCall, struct {
+		arg1 context.Context
+		arg2 *indexmanager.CleanupOptions
+	}{arg1, arg2})
+	stub := fake.CleanupStub
+	fakeReturns := fake.cleanupReturns
+	fake.recordInvocation("Cleanup", []interface{}{arg1, arg2})
+	fake.cleanupMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIndexCleaner) CleanupCallCount() int {
+	fake.cleanupMutex.RLock()
+	defer fake.cleanupMutex.RUnlock()
+	return len(fake.cleanupArgsForCall)
+}
+
+func (fake *FakeIndexCleaner) CleanupCalls(stub func(context.Context, *indexmanager.CleanupOptions) (*indexmanager.CleanupReport, error)) {
+	fake.cleanupMutex.Lock()
+	defer fake.cleanupMutex.Unlock()
+	fake.CleanupStub = stub
+}
+
+func (fake *FakeIndexCleaner) CleanupArgsForCall(i int) (context.Context, *indexmanager.CleanupOptions) {
+	fake.cleanupMutex.RLock()
+	defer fake.cleanupMutex.RUnlock()
+	argsForCall := fake.cleanupArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeIndexCleaner) CleanupReturns(result1 *indexmanager.CleanupReport, result2 error) {
+	fake.cleanupMutex.Lock()
+	defer fake.cleanupMutex.Unlock()
+	fake.CleanupStub = nil
+	fake.cleanupReturns = struct {
+		result1 *indexmanager.CleanupReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexCleaner) CleanupReturnsOnCall(i int, result1 *indexmanager.CleanupReport, result2 error) {
+	fake.cleanupMutex.Lock()
+	defer fake.cleanupMutex.Unlock()
+	fake.CleanupStub = nil
+	if fake.cleanupReturnsOnCall == nil {
+		fake.cleanupReturnsOnCall = make(map[int]struct {
+			result1 *indexmanager.CleanupReport
+			result2 error
+		})
+	}
+	fake.cleanupReturnsOnCall[i] = struct {
+		result1 *indexmanager.CleanupReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexCleaner) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.cleanupMutex.RLock()
+	defer fake.cleanupMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeIndexCleaner) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ indexmanager.IndexCleaner = new(FakeIndexCleaner)
@@ -3,12 +3,28 @@ package mocks
 
 import (
 	"context"
+	"io"
 	"sync"
 
 	"github.com/rode/es-index-manager/indexmanager"
 )
 
 type FakeIndexManager struct {
+	AdoptStub        func(context.Context, string, string, string, bool) error
+	adoptMutex       sync.RWMutex
+	adoptArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 bool
+	}
+	adoptReturns struct {
+		result1 error
+	}
+	adoptReturnsOnCall map[int]struct {
+		result1 error
+	}
 	AliasNameStub        func(string, string) string
 	aliasNameMutex       sync.RWMutex
 	aliasNameArgsForCall []struct {
@@ -21,6 +37,31 @@ type FakeIndexManager struct {
 	aliasNameReturnsOnCall map[int]struct {
 		result1 string
 	}
+	CheckDriftStub        func(context.Context) error
+	checkDriftMutex       sync.RWMutex
+	checkDriftArgsForCall []struct {
+		arg1 context.Context
+	}
+	checkDriftReturns struct {
+		result1 error
+	}
+	checkDriftReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CleanupStub        func(context.Context, *indexmanager.CleanupOptions) (*indexmanager.CleanupReport, error)
+	cleanupMutex       sync.RWMutex
+	cleanupArgsForCall []struct {
+		arg1 context.Context
+		arg2 *indexmanager.CleanupOptions
+	}
+	cleanupReturns struct {
+		result1 *indexmanager.CleanupReport
+		result2 error
+	}
+	cleanupReturnsOnCall map[int]struct {
+		result1 *indexmanager.CleanupReport
+		result2 error
+	}
 	CreateIndexStub        func(context.Context, string, string, string) error
 	createIndexMutex       sync.RWMutex
 	createIndexArgsForCall []struct {
@@ -35,6 +76,17 @@ type FakeIndexManager struct {
 	createIndexReturnsOnCall map[int]struct {
 		result1 error
 	}
+	CurrentDocumentKindStub        func(string) string
+	currentDocumentKindMutex       sync.RWMutex
+	currentDocumentKindArgsForCall []struct {
+		arg1 string
+	}
+	currentDocumentKindReturns struct {
+		result1 string
+	}
+	currentDocumentKindReturnsOnCall map[int]struct {
+		result1 string
+	}
 	DeleteIndexStub        func(context.Context, string) error
 	deleteIndexMutex       sync.RWMutex
 	deleteIndexArgsForCall []struct {
@@ -47,6 +99,84 @@ type FakeIndexManager struct {
 	deleteIndexReturnsOnCall map[int]struct {
 		result1 error
 	}
+	DocumentKindsStub        func() []string
+	documentKindsMutex       sync.RWMutex
+	documentKindsArgsForCall []struct {
+	}
+	documentKindsReturns struct {
+		result1 []string
+	}
+	documentKindsReturnsOnCall map[int]struct {
+		result1 []string
+	}
+	ExportStub        func(context.Context, string, string, io.Writer) (*indexmanager.TransferReport, error)
+	exportMutex       sync.RWMutex
+	exportArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 io.Writer
+	}
+	exportReturns struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}
+	exportReturnsOnCall map[int]struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}
+	GetSettingsChangesStub        func(context.Context) ([]*indexmanager.SettingsChange, error)
+	getSettingsChangesMutex       sync.RWMutex
+	getSettingsChangesArgsForCall []struct {
+		arg1 context.Context
+	}
+	getSettingsChangesReturns struct {
+		result1 []*indexmanager.SettingsChange
+		result2 error
+	}
+	getSettingsChangesReturnsOnCall map[int]struct {
+		result1 []*indexmanager.SettingsChange
+		result2 error
+	}
+	ILMPoliciesStub        func() map[string]*indexmanager.VersionedPolicy
+	iLMPoliciesMutex       sync.RWMutex
+	iLMPoliciesArgsForCall []struct {
+	}
+	iLMPoliciesReturns struct {
+		result1 map[string]*indexmanager.VersionedPolicy
+	}
+	iLMPoliciesReturnsOnCall map[int]struct {
+		result1 map[string]*indexmanager.VersionedPolicy
+	}
+	ImportStub        func(context.Context, string, string, io.Reader) (*indexmanager.TransferReport, error)
+	importMutex       sync.RWMutex
+	importArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 io.Reader
+	}
+	importReturns struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}
+	importReturnsOnCall map[int]struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}
+	IndexDriftStub        func(context.Context) ([]*indexmanager.IndexDrift, error)
+	indexDriftMutex       sync.RWMutex
+	indexDriftArgsForCall []struct {
+		arg1 context.Context
+	}
+	indexDriftReturns struct {
+		result1 []*indexmanager.IndexDrift
+		result2 error
+	}
+	indexDriftReturnsOnCall map[int]struct {
+		result1 []*indexmanager.IndexDrift
+		result2 error
+	}
 	IndexNameStub        func(string, string) string
 	indexNameMutex       sync.RWMutex
 	indexNameArgsForCall []struct {
@@ -59,6 +189,16 @@ type FakeIndexManager struct {
 	indexNameReturnsOnCall map[int]struct {
 		result1 string
 	}
+	IndexPrefixStub        func() string
+	indexPrefixMutex       sync.RWMutex
+	indexPrefixArgsForCall []struct {
+	}
+	indexPrefixReturns struct {
+		result1 string
+	}
+	indexPrefixReturnsOnCall map[int]struct {
+		result1 string
+	}
 	InitializeStub        func(context.Context) error
 	initializeMutex       sync.RWMutex
 	initializeArgsForCall []struct {
@@ -70,6 +210,17 @@ type FakeIndexManager struct {
 	initializeReturnsOnCall map[int]struct {
 		result1 error
 	}
+	InstallResourcesStub        func(context.Context) error
+	installResourcesMutex       sync.RWMutex
+	installResourcesArgsForCall []struct {
+		arg1 context.Context
+	}
+	installResourcesReturns struct {
+		result1 error
+	}
+	installResourcesReturnsOnCall map[int]struct {
+		result1 error
+	}
 	LoadMappingsStub        func() error
 	loadMappingsMutex       sync.RWMutex
 	loadMappingsArgsForCall []struct {
@@ -102,16 +253,129 @@ type FakeIndexManager struct {
 	parseIndexNameReturnsOnCall map[int]struct {
 		result1 *indexmanager.IndexName
 	}
-	RunMigrationsStub        func(context.Context) error
+	PipelinesStub        func() map[string]*indexmanager.VersionedPipeline
+	pipelinesMutex       sync.RWMutex
+	pipelinesArgsForCall []struct {
+	}
+	pipelinesReturns struct {
+		result1 map[string]*indexmanager.VersionedPipeline
+	}
+	pipelinesReturnsOnCall map[int]struct {
+		result1 map[string]*indexmanager.VersionedPipeline
+	}
+	ResourceDriftStub        func(context.Context) ([]*indexmanager.ResourceDrift, error)
+	resourceDriftMutex       sync.RWMutex
+	resourceDriftArgsForCall []struct {
+		arg1 context.Context
+	}
+	resourceDriftReturns struct {
+		result1 []*indexmanager.ResourceDrift
+		result2 error
+	}
+	resourceDriftReturnsOnCall map[int]struct {
+		result1 []*indexmanager.ResourceDrift
+		result2 error
+	}
+	ResourceNameStub        func(string) string
+	resourceNameMutex       sync.RWMutex
+	resourceNameArgsForCall []struct {
+		arg1 string
+	}
+	resourceNameReturns struct {
+		result1 string
+	}
+	resourceNameReturnsOnCall map[int]struct {
+		result1 string
+	}
+	RolloverStub        func(context.Context, string, string) (*indexmanager.RolloverResult, error)
+	rolloverMutex       sync.RWMutex
+	rolloverArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	rolloverReturns struct {
+		result1 *indexmanager.RolloverResult
+		result2 error
+	}
+	rolloverReturnsOnCall map[int]struct {
+		result1 *indexmanager.RolloverResult
+		result2 error
+	}
+	RunMigrationsStub        func(context.Context) (*indexmanager.MigrationReport, error)
 	runMigrationsMutex       sync.RWMutex
 	runMigrationsArgsForCall []struct {
 		arg1 context.Context
 	}
 	runMigrationsReturns struct {
-		result1 error
+		result1 *indexmanager.MigrationReport
+		result2 error
 	}
 	runMigrationsReturnsOnCall map[int]struct {
-		result1 error
+		result1 *indexmanager.MigrationReport
+		result2 error
+	}
+	SeedStub        func(context.Context, string, string) (*indexmanager.TransferReport, error)
+	seedMutex       sync.RWMutex
+	seedArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	seedReturns struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}
+	seedReturnsOnCall map[int]struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}
+	SeedsStub        func(string) []byte
+	seedsMutex       sync.RWMutex
+	seedsArgsForCall []struct {
+		arg1 string
+	}
+	seedsReturns struct {
+		result1 []byte
+	}
+	seedsReturnsOnCall map[int]struct {
+		result1 []byte
+	}
+	StoredScriptsStub        func() map[string]*indexmanager.StoredScript
+	storedScriptsMutex       sync.RWMutex
+	storedScriptsArgsForCall []struct {
+	}
+	storedScriptsReturns struct {
+		result1 map[string]*indexmanager.StoredScript
+	}
+	storedScriptsReturnsOnCall map[int]struct {
+		result1 map[string]*indexmanager.StoredScript
+	}
+	TimeSeriesIndexNameStub        func(string, int, string) string
+	timeSeriesIndexNameMutex       sync.RWMutex
+	timeSeriesIndexNameArgsForCall []struct {
+		arg1 string
+		arg2 int
+		arg3 string
+	}
+	timeSeriesIndexNameReturns struct {
+		result1 string
+	}
+	timeSeriesIndexNameReturnsOnCall map[int]struct {
+		result1 string
+	}
+	UpdateSettingsStub        func(context.Context) ([]*indexmanager.SettingsChange, error)
+	updateSettingsMutex       sync.RWMutex
+	updateSettingsArgsForCall []struct {
+		arg1 context.Context
+	}
+	updateSettingsReturns struct {
+		result1 []*indexmanager.SettingsChange
+		result2 error
+	}
+	updateSettingsReturnsOnCall map[int]struct {
+		result1 []*indexmanager.SettingsChange
+		result2 error
 	}
 	VersionStub        func(string) string
 	versionMutex       sync.RWMutex
@@ -124,10 +388,88 @@ type FakeIndexManager struct {
 	versionReturnsOnCall map[int]struct {
 		result1 string
 	}
+	WatchStub        func(context.Context) (<-chan *indexmanager.MappingsReloadEvent, error)
+	watchMutex       sync.RWMutex
+	watchArgsForCall []struct {
+		arg1 context.Context
+	}
+	watchReturns struct {
+		result1 <-chan *indexmanager.MappingsReloadEvent
+		result2 error
+	}
+	watchReturnsOnCall map[int]struct {
+		result1 <-chan *indexmanager.MappingsReloadEvent
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeIndexManager) Adopt(arg1 context.Context, arg2 string, arg3 string, arg4 string, arg5 bool) error {
+	fake.adoptMutex.Lock()
+	ret, specificReturn := fake.adoptReturnsOnCall[len(fake.adoptArgsForCall)]
+	fake.adoptArgsForCall = append(fake.adoptArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 bool
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.AdoptStub
+	fakeReturns := fake.adoptReturns
+	fake.recordInvocation("Adopt", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.adoptMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIndexManager) AdoptCallCount() int {
+	fake.adoptMutex.RLock()
+	defer fake.adoptMutex.RUnlock()
+	return len(fake.adoptArgsForCall)
+}
+
+func (fake *FakeIndexManager) AdoptCalls(stub func(context.Context, string, string, string, bool) error) {
+	fake.adoptMutex.Lock()
+	defer fake.adoptMutex.Unlock()
+	fake.AdoptStub = stub
+}
+
+func (fake *FakeIndexManager) AdoptArgsForCall(i int) (context.Context, string, string, string, bool) {
+	fake.adoptMutex.RLock()
+	defer fake.adoptMutex.RUnlock()
+	argsForCall := fake.adoptArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeIndexManager) AdoptReturns(result1 error) {
+	fake.adoptMutex.Lock()
+	defer fake.adoptMutex.Unlock()
+	fake.AdoptStub = nil
+	fake.adoptReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIndexManager) AdoptReturnsOnCall(i int, result1 error) {
+	fake.adoptMutex.Lock()
+	defer fake.adoptMutex.Unlock()
+	fake.AdoptStub = nil
+	if fake.adoptReturnsOnCall == nil {
+		fake.adoptReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.adoptReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeIndexManager) AliasName(arg1 string, arg2 string) string {
 	fake.aliasNameMutex.Lock()
 	ret, specificReturn := fake.aliasNameReturnsOnCall[len(fake.aliasNameArgsForCall)]
@@ -190,6 +532,132 @@ func (fake *FakeIndexManager) AliasNameReturnsOnCall(i int, result1 string) {
 	}{result1}
 }
 
+func (fake *FakeIndexManager) CheckDrift(arg1 context.Context) error {
+	fake.checkDriftMutex.Lock()
+	ret, specificReturn := fake.checkDriftReturnsOnCall[len(fake.checkDriftArgsForCall)]
+	fake.checkDriftArgsForCall = append(fake.checkDriftArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.CheckDriftStub
+	fakeReturns := fake.checkDriftReturns
+	fake.recordInvocation("CheckDrift", []interface{}{arg1})
+	fake.checkDriftMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIndexManager) CheckDriftCallCount() int {
+	fake.checkDriftMutex.RLock()
+	defer fake.checkDriftMutex.RUnlock()
+	return len(fake.checkDriftArgsForCall)
+}
+
+func (fake *FakeIndexManager) CheckDriftCalls(stub func(context.Context) error) {
+	fake.checkDriftMutex.Lock()
+	defer fake.checkDriftMutex.Unlock()
+	fake.CheckDriftStub = stub
+}
+
+func (fake *FakeIndexManager) CheckDriftArgsForCall(i int) context.Context {
+	fake.checkDriftMutex.RLock()
+	defer fake.checkDriftMutex.RUnlock()
+	argsForCall := fake.checkDriftArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIndexManager) CheckDriftReturns(result1 error) {
+	fake.checkDriftMutex.Lock()
+	defer fake.checkDriftMutex.Unlock()
+	fake.CheckDriftStub = nil
+	fake.checkDriftReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIndexManager) CheckDriftReturnsOnCall(i int, result1 error) {
+	fake.checkDriftMutex.Lock()
+	defer fake.checkDriftMutex.Unlock()
+	fake.CheckDriftStub = nil
+	if fake.checkDriftReturnsOnCall == nil {
+		fake.checkDriftReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.checkDriftReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIndexManager) Cleanup(arg1 context.Context, arg2 *indexmanager.CleanupOptions) (*indexmanager.CleanupReport, error) {
+	fake.cleanupMutex.Lock()
+	ret, specificReturn := fake.cleanupReturnsOnCall[len(fake.cleanupArgsForCall)]
+	fake.cleanupArgsForCall = append(fake.cleanupArgsForCall, struct {
+		arg1 context.Context
+		arg2 *indexmanager.CleanupOptions
+	}{arg1, arg2})
+	stub := fake.CleanupStub
+	fakeReturns := fake.cleanupReturns
+	fake.recordInvocation("Cleanup", []interface{}{arg1, arg2})
+	fake.cleanupMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIndexManager) CleanupCallCount() int {
+	fake.cleanupMutex.RLock()
+	defer fake.cleanupMutex.RUnlock()
+	return len(fake.cleanupArgsForCall)
+}
+
+func (fake *FakeIndexManager) CleanupCalls(stub func(context.Context, *indexmanager.CleanupOptions) (*indexmanager.CleanupReport, error)) {
+	fake.cleanupMutex.Lock()
+	defer fake.cleanupMutex.Unlock()
+	fake.CleanupStub = stub
+}
+
+func (fake *FakeIndexManager) CleanupArgsForCall(i int) (context.Context, *indexmanager.CleanupOptions) {
+	fake.cleanupMutex.RLock()
+	defer fake.cleanupMutex.RUnlock()
+	argsForCall := fake.cleanupArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeIndexManager) CleanupReturns(result1 *indexmanager.CleanupReport, result2 error) {
+	fake.cleanupMutex.Lock()
+	defer fake.cleanupMutex.Unlock()
+	fake.CleanupStub = nil
+	fake.cleanupReturns = struct {
+		result1 *indexmanager.CleanupReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexManager) CleanupReturnsOnCall(i int, result1 *indexmanager.CleanupReport, result2 error) {
+	fake.cleanupMutex.Lock()
+	defer fake.cleanupMutex.Unlock()
+	fake.CleanupStub = nil
+	if fake.cleanupReturnsOnCall == nil {
+		fake.cleanupReturnsOnCall = make(map[int]struct {
+			result1 *indexmanager.CleanupReport
+			result2 error
+		})
+	}
+	fake.cleanupReturnsOnCall[i] = struct {
+		result1 *indexmanager.CleanupReport
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeIndexManager) CreateIndex(arg1 context.Context, arg2 string, arg3 string, arg4 string) error {
 	fake.createIndexMutex.Lock()
 	ret, specificReturn := fake.createIndexReturnsOnCall[len(fake.createIndexArgsForCall)]
@@ -254,6 +722,67 @@ func (fake *FakeIndexManager) CreateIndexReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeIndexManager) CurrentDocumentKind(arg1 string) string {
+	fake.currentDocumentKindMutex.Lock()
+	ret, specificReturn := fake.currentDocumentKindReturnsOnCall[len(fake.currentDocumentKindArgsForCall)]
+	fake.currentDocumentKindArgsForCall = append(fake.currentDocumentKindArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.CurrentDocumentKindStub
+	fakeReturns := fake.currentDocumentKindReturns
+	fake.recordInvocation("CurrentDocumentKind", []interface{}{arg1})
+	fake.currentDocumentKindMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIndexManager) CurrentDocumentKindCallCount() int {
+	fake.currentDocumentKindMutex.RLock()
+	defer fake.currentDocumentKindMutex.RUnlock()
+	return len(fake.currentDocumentKindArgsForCall)
+}
+
+func (fake *FakeIndexManager) CurrentDocumentKindCalls(stub func(string) string) {
+	fake.currentDocumentKindMutex.Lock()
+	defer fake.currentDocumentKindMutex.Unlock()
+	fake.CurrentDocumentKindStub = stub
+}
+
+func (fake *FakeIndexManager) CurrentDocumentKindArgsForCall(i int) string {
+	fake.currentDocumentKindMutex.RLock()
+	defer fake.currentDocumentKindMutex.RUnlock()
+	argsForCall := fake.currentDocumentKindArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIndexManager) CurrentDocumentKindReturns(result1 string) {
+	fake.currentDocumentKindMutex.Lock()
+	defer fake.currentDocumentKindMutex.Unlock()
+	fake.CurrentDocumentKindStub = nil
+	fake.currentDocumentKindReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeIndexManager) CurrentDocumentKindReturnsOnCall(i int, result1 string) {
+	fake.currentDocumentKindMutex.Lock()
+	defer fake.currentDocumentKindMutex.Unlock()
+	fake.CurrentDocumentKindStub = nil
+	if fake.currentDocumentKindReturnsOnCall == nil {
+		fake.currentDocumentKindReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.currentDocumentKindReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
 func (fake *FakeIndexManager) DeleteIndex(arg1 context.Context, arg2 string) error {
 	fake.deleteIndexMutex.Lock()
 	ret, specificReturn := fake.deleteIndexReturnsOnCall[len(fake.deleteIndexArgsForCall)]
@@ -316,19 +845,17 @@ func (fake *FakeIndexManager) DeleteIndexReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeIndexManager) IndexName(arg1 string, arg2 string) string {
-	fake.indexNameMutex.Lock()
-	ret, specificReturn := fake.indexNameReturnsOnCall[len(fake.indexNameArgsForCall)]
-	fake.indexNameArgsForCall = append(fake.indexNameArgsForCall, struct {
-		arg1 string
-		arg2 string
-	}{arg1, arg2})
-	stub := fake.IndexNameStub
-	fakeReturns := fake.indexNameReturns
-	fake.recordInvocation("IndexName", []interface{}{arg1, arg2})
-	fake.indexNameMutex.Unlock()
+func (fake *FakeIndexManager) DocumentKinds() []string {
+	fake.documentKindsMutex.Lock()
+	ret, specificReturn := fake.documentKindsReturnsOnCall[len(fake.documentKindsArgsForCall)]
+	fake.documentKindsArgsForCall = append(fake.documentKindsArgsForCall, struct {
+	}{})
+	stub := fake.DocumentKindsStub
+	fakeReturns := fake.documentKindsReturns
+	fake.recordInvocation("DocumentKinds", []interface{}{})
+	fake.documentKindsMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1
@@ -336,31 +863,401 @@ func (fake *FakeIndexManager) IndexName(arg1 string, arg2 string) string {
 	return fakeReturns.result1
 }
 
-func (fake *FakeIndexManager) IndexNameCallCount() int {
-	fake.indexNameMutex.RLock()
-	defer fake.indexNameMutex.RUnlock()
-	return len(fake.indexNameArgsForCall)
+func (fake *FakeIndexManager) DocumentKindsCallCount() int {
+	fake.documentKindsMutex.RLock()
+	defer fake.documentKindsMutex.RUnlock()
+	return len(fake.documentKindsArgsForCall)
 }
 
-func (fake *FakeIndexManager) IndexNameCalls(stub func(string, string) string) {
-	fake.indexNameMutex.Lock()
-	defer fake.indexNameMutex.Unlock()
-	fake.IndexNameStub = stub
+func (fake *FakeIndexManager) DocumentKindsCalls(stub func() []string) {
+	fake.documentKindsMutex.Lock()
+	defer fake.documentKindsMutex.Unlock()
+	fake.DocumentKindsStub = stub
 }
 
-func (fake *FakeIndexManager) IndexNameArgsForCall(i int) (string, string) {
-	fake.indexNameMutex.RLock()
-	defer fake.indexNameMutex.RUnlock()
-	argsForCall := fake.indexNameArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+func (fake *FakeIndexManager) DocumentKindsReturns(result1 []string) {
+	fake.documentKindsMutex.Lock()
+	defer fake.documentKindsMutex.Unlock()
+	fake.DocumentKindsStub = nil
+	fake.documentKindsReturns = struct {
+		result1 []string
+	}{result1}
 }
 
-func (fake *FakeIndexManager) IndexNameReturns(result1 string) {
-	fake.indexNameMutex.Lock()
-	defer fake.indexNameMutex.Unlock()
-	fake.IndexNameStub = nil
-	fake.indexNameReturns = struct {
-		result1 string
+func (fake *FakeIndexManager) DocumentKindsReturnsOnCall(i int, result1 []string) {
+	fake.documentKindsMutex.Lock()
+	defer fake.documentKindsMutex.Unlock()
+	fake.DocumentKindsStub = nil
+	if fake.documentKindsReturnsOnCall == nil {
+		fake.documentKindsReturnsOnCall = make(map[int]struct {
+			result1 []string
+		})
+	}
+	fake.documentKindsReturnsOnCall[i] = struct {
+		result1 []string
+	}{result1}
+}
+
+func (fake *FakeIndexManager) Export(arg1 context.Context, arg2 string, arg3 string, arg4 io.Writer) (*indexmanager.TransferReport, error) {
+	fake.exportMutex.Lock()
+	ret, specificReturn := fake.exportReturnsOnCall[len(fake.exportArgsForCall)]
+	fake.exportArgsForCall = append(fake.exportArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 io.Writer
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.ExportStub
+	fakeReturns := fake.exportReturns
+	fake.recordInvocation("Export", []interface{}{arg1, arg2, arg3, arg4})
+	fake.exportMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIndexManager) ExportCallCount() int {
+	fake.exportMutex.RLock()
+	defer fake.exportMutex.RUnlock()
+	return len(fake.exportArgsForCall)
+}
+
+func (fake *FakeIndexManager) ExportCalls(stub func(context.Context, string, string, io.Writer) (*indexmanager.TransferReport, error)) {
+	fake.exportMutex.Lock()
+	defer fake.exportMutex.Unlock()
+	fake.ExportStub = stub
+}
+
+func (fake *FakeIndexManager) ExportArgsForCall(i int) (context.Context, string, string, io.Writer) {
+	fake.exportMutex.RLock()
+	defer fake.exportMutex.RUnlock()
+	argsForCall := fake.exportArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeIndexManager) ExportReturns(result1 *indexmanager.TransferReport, result2 error) {
+	fake.exportMutex.Lock()
+	defer fake.exportMutex.Unlock()
+	fake.ExportStub = nil
+	fake.exportReturns = struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexManager) ExportReturnsOnCall(i int, result1 *indexmanager.TransferReport, result2 error) {
+	fake.exportMutex.Lock()
+	defer fake.exportMutex.Unlock()
+	fake.ExportStub = nil
+	if fake.exportReturnsOnCall == nil {
+		fake.exportReturnsOnCall = make(map[int]struct {
+			result1 *indexmanager.TransferReport
+			result2 error
+		})
+	}
+	fake.exportReturnsOnCall[i] = struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexManager) GetSettingsChanges(arg1 context.Context) ([]*indexmanager.SettingsChange, error) {
+	fake.getSettingsChangesMutex.Lock()
+	ret, specificReturn := fake.getSettingsChangesReturnsOnCall[len(fake.getSettingsChangesArgsForCall)]
+	fake.getSettingsChangesArgsForCall = append(fake.getSettingsChangesArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.GetSettingsChangesStub
+	fakeReturns := fake.getSettingsChangesReturns
+	fake.recordInvocation("GetSettingsChanges", []interface{}{arg1})
+	fake.getSettingsChangesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIndexManager) GetSettingsChangesCallCount() int {
+	fake.getSettingsChangesMutex.RLock()
+	defer fake.getSettingsChangesMutex.RUnlock()
+	return len(fake.getSettingsChangesArgsForCall)
+}
+
+func (fake *FakeIndexManager) GetSettingsChangesCalls(stub func(context.Context) ([]*indexmanager.SettingsChange, error)) {
+	fake.getSettingsChangesMutex.Lock()
+	defer fake.getSettingsChangesMutex.Unlock()
+	fake.GetSettingsChangesStub = stub
+}
+
+func (fake *FakeIndexManager) GetSettingsChangesArgsForCall(i int) context.Context {
+	fake.getSettingsChangesMutex.RLock()
+	defer fake.getSettingsChangesMutex.RUnlock()
+	argsForCall := fake.getSettingsChangesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIndexManager) GetSettingsChangesReturns(result1 []*indexmanager.SettingsChange, result2 error) {
+	fake.getSettingsChangesMutex.Lock()
+	defer fake.getSettingsChangesMutex.Unlock()
+	fake.GetSettingsChangesStub = nil
+	fake.getSettingsChangesReturns = struct {
+		result1 []*indexmanager.SettingsChange
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexManager) GetSettingsChangesReturnsOnCall(i int, result1 []*indexmanager.SettingsChange, result2 error) {
+	fake.getSettingsChangesMutex.Lock()
+	defer fake.getSettingsChangesMutex.Unlock()
+	fake.GetSettingsChangesStub = nil
+	if fake.getSettingsChangesReturnsOnCall == nil {
+		fake.getSettingsChangesReturnsOnCall = make(map[int]struct {
+			result1 []*indexmanager.SettingsChange
+			result2 error
+		})
+	}
+	fake.getSettingsChangesReturnsOnCall[i] = struct {
+		result1 []*indexmanager.SettingsChange
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexManager) ILMPolicies() map[string]*indexmanager.VersionedPolicy {
+	fake.iLMPoliciesMutex.Lock()
+	ret, specificReturn := fake.iLMPoliciesReturnsOnCall[len(fake.iLMPoliciesArgsForCall)]
+	fake.iLMPoliciesArgsForCall = append(fake.iLMPoliciesArgsForCall, struct {
+	}{})
+	stub := fake.ILMPoliciesStub
+	fakeReturns := fake.iLMPoliciesReturns
+	fake.recordInvocation("ILMPolicies", []interface{}{})
+	fake.iLMPoliciesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIndexManager) ILMPoliciesCallCount() int {
+	fake.iLMPoliciesMutex.RLock()
+	defer fake.iLMPoliciesMutex.RUnlock()
+	return len(fake.iLMPoliciesArgsForCall)
+}
+
+func (fake *FakeIndexManager) ILMPoliciesCalls(stub func() map[string]*indexmanager.VersionedPolicy) {
+	fake.iLMPoliciesMutex.Lock()
+	defer fake.iLMPoliciesMutex.Unlock()
+	fake.ILMPoliciesStub = stub
+}
+
+func (fake *FakeIndexManager) ILMPoliciesReturns(result1 map[string]*indexmanager.VersionedPolicy) {
+	fake.iLMPoliciesMutex.Lock()
+	defer fake.iLMPoliciesMutex.Unlock()
+	fake.ILMPoliciesStub = nil
+	fake.iLMPoliciesReturns = struct {
+		result1 map[string]*indexmanager.VersionedPolicy
+	}{result1}
+}
+
+func (fake *FakeIndexManager) ILMPoliciesReturnsOnCall(i int, result1 map[string]*indexmanager.VersionedPolicy) {
+	fake.iLMPoliciesMutex.Lock()
+	defer fake.iLMPoliciesMutex.Unlock()
+	fake.ILMPoliciesStub = nil
+	if fake.iLMPoliciesReturnsOnCall == nil {
+		fake.iLMPoliciesReturnsOnCall = make(map[int]struct {
+			result1 map[string]*indexmanager.VersionedPolicy
+		})
+	}
+	fake.iLMPoliciesReturnsOnCall[i] = struct {
+		result1 map[string]*indexmanager.VersionedPolicy
+	}{result1}
+}
+
+func (fake *FakeIndexManager) Import(arg1 context.Context, arg2 string, arg3 string, arg4 io.Reader) (*indexmanager.TransferReport, error) {
+	fake.importMutex.Lock()
+	ret, specificReturn := fake.importReturnsOnCall[len(fake.importArgsForCall)]
+	fake.importArgsForCall = append(fake.importArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 io.Reader
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.ImportStub
+	fakeReturns := fake.importReturns
+	fake.recordInvocation("Import", []interface{}{arg1, arg2, arg3, arg4})
+	fake.importMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIndexManager) ImportCallCount() int {
+	fake.importMutex.RLock()
+	defer fake.importMutex.RUnlock()
+	return len(fake.importArgsForCall)
+}
+
+func (fake *FakeIndexManager) ImportCalls(stub func(context.Context, string, string, io.Reader) (*indexmanager.TransferReport, error)) {
+	fake.importMutex.Lock()
+	defer fake.importMutex.Unlock()
+	fake.ImportStub = stub
+}
+
+func (fake *FakeIndexManager) ImportArgsForCall(i int) (context.Context, string, string, io.Reader) {
+	fake.importMutex.RLock()
+	defer fake.importMutex.RUnlock()
+	argsForCall := fake.importArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeIndexManager) ImportReturns(result1 *indexmanager.TransferReport, result2 error) {
+	fake.importMutex.Lock()
+	defer fake.importMutex.Unlock()
+	fake.ImportStub = nil
+	fake.importReturns = struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexManager) ImportReturnsOnCall(i int, result1 *indexmanager.TransferReport, result2 error) {
+	fake.importMutex.Lock()
+	defer fake.importMutex.Unlock()
+	fake.ImportStub = nil
+	if fake.importReturnsOnCall == nil {
+		fake.importReturnsOnCall = make(map[int]struct {
+			result1 *indexmanager.TransferReport
+			result2 error
+		})
+	}
+	fake.importReturnsOnCall[i] = struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexManager) IndexDrift(arg1 context.Context) ([]*indexmanager.IndexDrift, error) {
+	fake.indexDriftMutex.Lock()
+	ret, specificReturn := fake.indexDriftReturnsOnCall[len(fake.indexDriftArgsForCall)]
+	fake.indexDriftArgsForCall = append(fake.indexDriftArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.IndexDriftStub
+	fakeReturns := fake.indexDriftReturns
+	fake.recordInvocation("IndexDrift", []interface{}{arg1})
+	fake.indexDriftMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIndexManager) IndexDriftCallCount() int {
+	fake.indexDriftMutex.RLock()
+	defer fake.indexDriftMutex.RUnlock()
+	return len(fake.indexDriftArgsForCall)
+}
+
+func (fake *FakeIndexManager) IndexDriftCalls(stub func(context.Context) ([]*indexmanager.IndexDrift, error)) {
+	fake.indexDriftMutex.Lock()
+	defer fake.indexDriftMutex.Unlock()
+	fake.IndexDriftStub = stub
+}
+
+func (fake *FakeIndexManager) IndexDriftArgsForCall(i int) context.Context {
+	fake.indexDriftMutex.RLock()
+	defer fake.indexDriftMutex.RUnlock()
+	argsForCall := fake.indexDriftArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIndexManager) IndexDriftReturns(result1 []*indexmanager.IndexDrift, result2 error) {
+	fake.indexDriftMutex.Lock()
+	defer fake.indexDriftMutex.Unlock()
+	fake.IndexDriftStub = nil
+	fake.indexDriftReturns = struct {
+		result1 []*indexmanager.IndexDrift
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexManager) IndexDriftReturnsOnCall(i int, result1 []*indexmanager.IndexDrift, result2 error) {
+	fake.indexDriftMutex.Lock()
+	defer fake.indexDriftMutex.Unlock()
+	fake.IndexDriftStub = nil
+	if fake.indexDriftReturnsOnCall == nil {
+		fake.indexDriftReturnsOnCall = make(map[int]struct {
+			result1 []*indexmanager.IndexDrift
+			result2 error
+		})
+	}
+	fake.indexDriftReturnsOnCall[i] = struct {
+		result1 []*indexmanager.IndexDrift
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexManager) IndexName(arg1 string, arg2 string) string {
+	fake.indexNameMutex.Lock()
+	ret, specificReturn := fake.indexNameReturnsOnCall[len(fake.indexNameArgsForCall)]
+	fake.indexNameArgsForCall = append(fake.indexNameArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.IndexNameStub
+	fakeReturns := fake.indexNameReturns
+	fake.recordInvocation("IndexName", []interface{}{arg1, arg2})
+	fake.indexNameMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIndexManager) IndexNameCallCount() int {
+	fake.indexNameMutex.RLock()
+	defer fake.indexNameMutex.RUnlock()
+	return len(fake.indexNameArgsForCall)
+}
+
+func (fake *FakeIndexManager) IndexNameCalls(stub func(string, string) string) {
+	fake.indexNameMutex.Lock()
+	defer fake.indexNameMutex.Unlock()
+	fake.IndexNameStub = stub
+}
+
+func (fake *FakeIndexManager) IndexNameArgsForCall(i int) (string, string) {
+	fake.indexNameMutex.RLock()
+	defer fake.indexNameMutex.RUnlock()
+	argsForCall := fake.indexNameArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeIndexManager) IndexNameReturns(result1 string) {
+	fake.indexNameMutex.Lock()
+	defer fake.indexNameMutex.Unlock()
+	fake.IndexNameStub = nil
+	fake.indexNameReturns = struct {
+		result1 string
 	}{result1}
 }
 
@@ -378,6 +1275,59 @@ func (fake *FakeIndexManager) IndexNameReturnsOnCall(i int, result1 string) {
 	}{result1}
 }
 
+func (fake *FakeIndexManager) IndexPrefix() string {
+	fake.indexPrefixMutex.Lock()
+	ret, specificReturn := fake.indexPrefixReturnsOnCall[len(fake.indexPrefixArgsForCall)]
+	fake.indexPrefixArgsForCall = append(fake.indexPrefixArgsForCall, struct {
+	}{})
+	stub := fake.IndexPrefixStub
+	fakeReturns := fake.indexPrefixReturns
+	fake.recordInvocation("IndexPrefix", []interface{}{})
+	fake.indexPrefixMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIndexManager) IndexPrefixCallCount() int {
+	fake.indexPrefixMutex.RLock()
+	defer fake.indexPrefixMutex.RUnlock()
+	return len(fake.indexPrefixArgsForCall)
+}
+
+func (fake *FakeIndexManager) IndexPrefixCalls(stub func() string) {
+	fake.indexPrefixMutex.Lock()
+	defer fake.indexPrefixMutex.Unlock()
+	fake.IndexPrefixStub = stub
+}
+
+func (fake *FakeIndexManager) IndexPrefixReturns(result1 string) {
+	fake.indexPrefixMutex.Lock()
+	defer fake.indexPrefixMutex.Unlock()
+	fake.IndexPrefixStub = nil
+	fake.indexPrefixReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeIndexManager) IndexPrefixReturnsOnCall(i int, result1 string) {
+	fake.indexPrefixMutex.Lock()
+	defer fake.indexPrefixMutex.Unlock()
+	fake.IndexPrefixStub = nil
+	if fake.indexPrefixReturnsOnCall == nil {
+		fake.indexPrefixReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.indexPrefixReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
 func (fake *FakeIndexManager) Initialize(arg1 context.Context) error {
 	fake.initializeMutex.Lock()
 	ret, specificReturn := fake.initializeReturnsOnCall[len(fake.initializeArgsForCall)]
@@ -439,6 +1389,67 @@ func (fake *FakeIndexManager) InitializeReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeIndexManager) InstallResources(arg1 context.Context) error {
+	fake.installResourcesMutex.Lock()
+	ret, specificReturn := fake.installResourcesReturnsOnCall[len(fake.installResourcesArgsForCall)]
+	fake.installResourcesArgsForCall = append(fake.installResourcesArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.InstallResourcesStub
+	fakeReturns := fake.installResourcesReturns
+	fake.recordInvocation("InstallResources", []interface{}{arg1})
+	fake.installResourcesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIndexManager) InstallResourcesCallCount() int {
+	fake.installResourcesMutex.RLock()
+	defer fake.installResourcesMutex.RUnlock()
+	return len(fake.installResourcesArgsForCall)
+}
+
+func (fake *FakeIndexManager) InstallResourcesCalls(stub func(context.Context) error) {
+	fake.installResourcesMutex.Lock()
+	defer fake.installResourcesMutex.Unlock()
+	fake.InstallResourcesStub = stub
+}
+
+func (fake *FakeIndexManager) InstallResourcesArgsForCall(i int) context.Context {
+	fake.installResourcesMutex.RLock()
+	defer fake.installResourcesMutex.RUnlock()
+	argsForCall := fake.installResourcesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIndexManager) InstallResourcesReturns(result1 error) {
+	fake.installResourcesMutex.Lock()
+	defer fake.installResourcesMutex.Unlock()
+	fake.InstallResourcesStub = nil
+	fake.installResourcesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIndexManager) InstallResourcesReturnsOnCall(i int, result1 error) {
+	fake.installResourcesMutex.Lock()
+	defer fake.installResourcesMutex.Unlock()
+	fake.InstallResourcesStub = nil
+	if fake.installResourcesReturnsOnCall == nil {
+		fake.installResourcesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.installResourcesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeIndexManager) LoadMappings() error {
 	fake.loadMappingsMutex.Lock()
 	ret, specificReturn := fake.loadMappingsReturnsOnCall[len(fake.loadMappingsArgsForCall)]
@@ -614,7 +1625,251 @@ func (fake *FakeIndexManager) ParseIndexNameReturnsOnCall(i int, result1 *indexm
 	}{result1}
 }
 
-func (fake *FakeIndexManager) RunMigrations(arg1 context.Context) error {
+func (fake *FakeIndexManager) Pipelines() map[string]*indexmanager.VersionedPipeline {
+	fake.pipelinesMutex.Lock()
+	ret, specificReturn := fake.pipelinesReturnsOnCall[len(fake.pipelinesArgsForCall)]
+	fake.pipelinesArgsForCall = append(fake.pipelinesArgsForCall, struct {
+	}{})
+	stub := fake.PipelinesStub
+	fakeReturns := fake.pipelinesReturns
+	fake.recordInvocation("Pipelines", []interface{}{})
+	fake.pipelinesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIndexManager) PipelinesCallCount() int {
+	fake.pipelinesMutex.RLock()
+	defer fake.pipelinesMutex.RUnlock()
+	return len(fake.pipelinesArgsForCall)
+}
+
+func (fake *FakeIndexManager) PipelinesCalls(stub func() map[string]*indexmanager.VersionedPipeline) {
+	fake.pipelinesMutex.Lock()
+	defer fake.pipelinesMutex.Unlock()
+	fake.PipelinesStub = stub
+}
+
+func (fake *FakeIndexManager) PipelinesReturns(result1 map[string]*indexmanager.VersionedPipeline) {
+	fake.pipelinesMutex.Lock()
+	defer fake.pipelinesMutex.Unlock()
+	fake.PipelinesStub = nil
+	fake.pipelinesReturns = struct {
+		result1 map[string]*indexmanager.VersionedPipeline
+	}{result1}
+}
+
+func (fake *FakeIndexManager) PipelinesReturnsOnCall(i int, result1 map[string]*indexmanager.VersionedPipeline) {
+	fake.pipelinesMutex.Lock()
+	defer fake.pipelinesMutex.Unlock()
+	fake.PipelinesStub = nil
+	if fake.pipelinesReturnsOnCall == nil {
+		fake.pipelinesReturnsOnCall = make(map[int]struct {
+			result1 map[string]*indexmanager.VersionedPipeline
+		})
+	}
+	fake.pipelinesReturnsOnCall[i] = struct {
+		result1 map[string]*indexmanager.VersionedPipeline
+	}{result1}
+}
+
+func (fake *FakeIndexManager) ResourceDrift(arg1 context.Context) ([]*indexmanager.ResourceDrift, error) {
+	fake.resourceDriftMutex.Lock()
+	ret, specificReturn := fake.resourceDriftReturnsOnCall[len(fake.resourceDriftArgsForCall)]
+	fake.resourceDriftArgsForCall = append(fake.resourceDriftArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.ResourceDriftStub
+	fakeReturns := fake.resourceDriftReturns
+	fake.recordInvocation("ResourceDrift", []interface{}{arg1})
+	fake.resourceDriftMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIndexManager) ResourceDriftCallCount() int {
+	fake.resourceDriftMutex.RLock()
+	defer fake.resourceDriftMutex.RUnlock()
+	return len(fake.resourceDriftArgsForCall)
+}
+
+func (fake *FakeIndexManager) ResourceDriftCalls(stub func(context.Context) ([]*indexmanager.ResourceDrift, error)) {
+	fake.resourceDriftMutex.Lock()
+	defer fake.resourceDriftMutex.Unlock()
+	fake.ResourceDriftStub = stub
+}
+
+func (fake *FakeIndexManager) ResourceDriftArgsForCall(i int) context.Context {
+	fake.resourceDriftMutex.RLock()
+	defer fake.resourceDriftMutex.RUnlock()
+	argsForCall := fake.resourceDriftArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIndexManager) ResourceDriftReturns(result1 []*indexmanager.ResourceDrift, result2 error) {
+	fake.resourceDriftMutex.Lock()
+	defer fake.resourceDriftMutex.Unlock()
+	fake.ResourceDriftStub = nil
+	fake.resourceDriftReturns = struct {
+		result1 []*indexmanager.ResourceDrift
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexManager) ResourceDriftReturnsOnCall(i int, result1 []*indexmanager.ResourceDrift, result2 error) {
+	fake.resourceDriftMutex.Lock()
+	defer fake.resourceDriftMutex.Unlock()
+	fake.ResourceDriftStub = nil
+	if fake.resourceDriftReturnsOnCall == nil {
+		fake.resourceDriftReturnsOnCall = make(map[int]struct {
+			result1 []*indexmanager.ResourceDrift
+			result2 error
+		})
+	}
+	fake.resourceDriftReturnsOnCall[i] = struct {
+		result1 []*indexmanager.ResourceDrift
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexManager) ResourceName(arg1 string) string {
+	fake.resourceNameMutex.Lock()
+	ret, specificReturn := fake.resourceNameReturnsOnCall[len(fake.resourceNameArgsForCall)]
+	fake.resourceNameArgsForCall = append(fake.resourceNameArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ResourceNameStub
+	fakeReturns := fake.resourceNameReturns
+	fake.recordInvocation("ResourceName", []interface{}{arg1})
+	fake.resourceNameMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIndexManager) ResourceNameCallCount() int {
+	fake.resourceNameMutex.RLock()
+	defer fake.resourceNameMutex.RUnlock()
+	return len(fake.resourceNameArgsForCall)
+}
+
+func (fake *FakeIndexManager) ResourceNameCalls(stub func(string) string) {
+	fake.resourceNameMutex.Lock()
+	defer fake.resourceNameMutex.Unlock()
+	fake.ResourceNameStub = stub
+}
+
+func (fake *FakeIndexManager) ResourceNameArgsForCall(i int) string {
+	fake.resourceNameMutex.RLock()
+	defer fake.resourceNameMutex.RUnlock()
+	argsForCall := fake.resourceNameArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIndexManager) ResourceNameReturns(result1 string) {
+	fake.resourceNameMutex.Lock()
+	defer fake.resourceNameMutex.Unlock()
+	fake.ResourceNameStub = nil
+	fake.resourceNameReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeIndexManager) ResourceNameReturnsOnCall(i int, result1 string) {
+	fake.resourceNameMutex.Lock()
+	defer fake.resourceNameMutex.Unlock()
+	fake.ResourceNameStub = nil
+	if fake.resourceNameReturnsOnCall == nil {
+		fake.resourceNameReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.resourceNameReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeIndexManager) Rollover(arg1 context.Context, arg2 string, arg3 string) (*indexmanager.RolloverResult, error) {
+	fake.rolloverMutex.Lock()
+	ret, specificReturn := fake.rolloverReturnsOnCall[len(fake.rolloverArgsForCall)]
+	fake.rolloverArgsForCall = append(fake.rolloverArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.RolloverStub
+	fakeReturns := fake.rolloverReturns
+	fake.recordInvocation("Rollover", []interface{}{arg1, arg2, arg3})
+	fake.rolloverMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIndexManager) RolloverCallCount() int {
+	fake.rolloverMutex.RLock()
+	defer fake.rolloverMutex.RUnlock()
+	return len(fake.rolloverArgsForCall)
+}
+
+func (fake *FakeIndexManager) RolloverCalls(stub func(context.Context, string, string) (*indexmanager.RolloverResult, error)) {
+	fake.rolloverMutex.Lock()
+	defer fake.rolloverMutex.Unlock()
+	fake.RolloverStub = stub
+}
+
+func (fake *FakeIndexManager) RolloverArgsForCall(i int) (context.Context, string, string) {
+	fake.rolloverMutex.RLock()
+	defer fake.rolloverMutex.RUnlock()
+	argsForCall := fake.rolloverArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeIndexManager) RolloverReturns(result1 *indexmanager.RolloverResult, result2 error) {
+	fake.rolloverMutex.Lock()
+	defer fake.rolloverMutex.Unlock()
+	fake.RolloverStub = nil
+	fake.rolloverReturns = struct {
+		result1 *indexmanager.RolloverResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexManager) RolloverReturnsOnCall(i int, result1 *indexmanager.RolloverResult, result2 error) {
+	fake.rolloverMutex.Lock()
+	defer fake.rolloverMutex.Unlock()
+	fake.RolloverStub = nil
+	if fake.rolloverReturnsOnCall == nil {
+		fake.rolloverReturnsOnCall = make(map[int]struct {
+			result1 *indexmanager.RolloverResult
+			result2 error
+		})
+	}
+	fake.rolloverReturnsOnCall[i] = struct {
+		result1 *indexmanager.RolloverResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexManager) RunMigrations(arg1 context.Context) (*indexmanager.MigrationReport, error) {
 	fake.runMigrationsMutex.Lock()
 	ret, specificReturn := fake.runMigrationsReturnsOnCall[len(fake.runMigrationsArgsForCall)]
 	fake.runMigrationsArgsForCall = append(fake.runMigrationsArgsForCall, struct {
@@ -628,9 +1883,9 @@ func (fake *FakeIndexManager) RunMigrations(arg1 context.Context) error {
 		return stub(arg1)
 	}
 	if specificReturn {
-		return ret.result1
+		return ret.result1, ret.result2
 	}
-	return fakeReturns.result1
+	return fakeReturns.result1, fakeReturns.result2
 }
 
 func (fake *FakeIndexManager) RunMigrationsCallCount() int {
@@ -639,7 +1894,7 @@ func (fake *FakeIndexManager) RunMigrationsCallCount() int {
 	return len(fake.runMigrationsArgsForCall)
 }
 
-func (fake *FakeIndexManager) RunMigrationsCalls(stub func(context.Context) error) {
+func (fake *FakeIndexManager) RunMigrationsCalls(stub func(context.Context) (*indexmanager.MigrationReport, error)) {
 	fake.runMigrationsMutex.Lock()
 	defer fake.runMigrationsMutex.Unlock()
 	fake.RunMigrationsStub = stub
@@ -652,29 +1907,339 @@ func (fake *FakeIndexManager) RunMigrationsArgsForCall(i int) context.Context {
 	return argsForCall.arg1
 }
 
-func (fake *FakeIndexManager) RunMigrationsReturns(result1 error) {
+func (fake *FakeIndexManager) RunMigrationsReturns(result1 *indexmanager.MigrationReport, result2 error) {
 	fake.runMigrationsMutex.Lock()
 	defer fake.runMigrationsMutex.Unlock()
 	fake.RunMigrationsStub = nil
 	fake.runMigrationsReturns = struct {
-		result1 error
-	}{result1}
+		result1 *indexmanager.MigrationReport
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeIndexManager) RunMigrationsReturnsOnCall(i int, result1 error) {
+func (fake *FakeIndexManager) RunMigrationsReturnsOnCall(i int, result1 *indexmanager.MigrationReport, result2 error) {
 	fake.runMigrationsMutex.Lock()
 	defer fake.runMigrationsMutex.Unlock()
 	fake.RunMigrationsStub = nil
 	if fake.runMigrationsReturnsOnCall == nil {
 		fake.runMigrationsReturnsOnCall = make(map[int]struct {
-			result1 error
+			result1 *indexmanager.MigrationReport
+			result2 error
 		})
 	}
 	fake.runMigrationsReturnsOnCall[i] = struct {
-		result1 error
+		result1 *indexmanager.MigrationReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexManager) Seed(arg1 context.Context, arg2 string, arg3 string) (*indexmanager.TransferReport, error) {
+	fake.seedMutex.Lock()
+	ret, specificReturn := fake.seedReturnsOnCall[len(fake.seedArgsForCall)]
+	fake.seedArgsForCall = append(fake.seedArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.SeedStub
+	fakeReturns := fake.seedReturns
+	fake.recordInvocation("Seed", []interface{}{arg1, arg2, arg3})
+	fake.seedMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIndexManager) SeedCallCount() int {
+	fake.seedMutex.RLock()
+	defer fake.seedMutex.RUnlock()
+	return len(fake.seedArgsForCall)
+}
+
+func (fake *FakeIndexManager) SeedCalls(stub func(context.Context, string, string) (*indexmanager.TransferReport, error)) {
+	fake.seedMutex.Lock()
+	defer fake.seedMutex.Unlock()
+	fake.SeedStub = stub
+}
+
+func (fake *FakeIndexManager) SeedArgsForCall(i int) (context.Context, string, string) {
+	fake.seedMutex.RLock()
+	defer fake.seedMutex.RUnlock()
+	argsForCall := fake.seedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeIndexManager) SeedReturns(result1 *indexmanager.TransferReport, result2 error) {
+	fake.seedMutex.Lock()
+	defer fake.seedMutex.Unlock()
+	fake.SeedStub = nil
+	fake.seedReturns = struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexManager) SeedReturnsOnCall(i int, result1 *indexmanager.TransferReport, result2 error) {
+	fake.seedMutex.Lock()
+	defer fake.seedMutex.Unlock()
+	fake.SeedStub = nil
+	if fake.seedReturnsOnCall == nil {
+		fake.seedReturnsOnCall = make(map[int]struct {
+			result1 *indexmanager.TransferReport
+			result2 error
+		})
+	}
+	fake.seedReturnsOnCall[i] = struct {
+		result1 *indexmanager.TransferReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexManager) Seeds(arg1 string) []byte {
+	fake.seedsMutex.Lock()
+	ret, specificReturn := fake.seedsReturnsOnCall[len(fake.seedsArgsForCall)]
+	fake.seedsArgsForCall = append(fake.seedsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.SeedsStub
+	fakeReturns := fake.seedsReturns
+	fake.recordInvocation("Seeds", []interface{}{arg1})
+	fake.seedsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIndexManager) SeedsCallCount() int {
+	fake.seedsMutex.RLock()
+	defer fake.seedsMutex.RUnlock()
+	return len(fake.seedsArgsForCall)
+}
+
+func (fake *FakeIndexManager) SeedsCalls(stub func(string) []byte) {
+	fake.seedsMutex.Lock()
+	defer fake.seedsMutex.Unlock()
+	fake.SeedsStub = stub
+}
+
+func (fake *FakeIndexManager) SeedsArgsForCall(i int) string {
+	fake.seedsMutex.RLock()
+	defer fake.seedsMutex.RUnlock()
+	argsForCall := fake.seedsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIndexManager) SeedsReturns(result1 []byte) {
+	fake.seedsMutex.Lock()
+	defer fake.seedsMutex.Unlock()
+	fake.SeedsStub = nil
+	fake.seedsReturns = struct {
+		result1 []byte
 	}{result1}
 }
 
+func (fake *FakeIndexManager) SeedsReturnsOnCall(i int, result1 []byte) {
+	fake.seedsMutex.Lock()
+	defer fake.seedsMutex.Unlock()
+	fake.SeedsStub = nil
+	if fake.seedsReturnsOnCall == nil {
+		fake.seedsReturnsOnCall = make(map[int]struct {
+			result1 []byte
+		})
+	}
+	fake.seedsReturnsOnCall[i] = struct {
+		result1 []byte
+	}{result1}
+}
+
+func (fake *FakeIndexManager) StoredScripts() map[string]*indexmanager.StoredScript {
+	fake.storedScriptsMutex.Lock()
+	ret, specificReturn := fake.storedScriptsReturnsOnCall[len(fake.storedScriptsArgsForCall)]
+	fake.storedScriptsArgsForCall = append(fake.storedScriptsArgsForCall, struct {
+	}{})
+	stub := fake.StoredScriptsStub
+	fakeReturns := fake.storedScriptsReturns
+	fake.recordInvocation("StoredScripts", []interface{}{})
+	fake.storedScriptsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIndexManager) StoredScriptsCallCount() int {
+	fake.storedScriptsMutex.RLock()
+	defer fake.storedScriptsMutex.RUnlock()
+	return len(fake.storedScriptsArgsForCall)
+}
+
+func (fake *FakeIndexManager) StoredScriptsCalls(stub func() map[string]*indexmanager.StoredScript) {
+	fake.storedScriptsMutex.Lock()
+	defer fake.storedScriptsMutex.Unlock()
+	fake.StoredScriptsStub = stub
+}
+
+func (fake *FakeIndexManager) StoredScriptsReturns(result1 map[string]*indexmanager.StoredScript) {
+	fake.storedScriptsMutex.Lock()
+	defer fake.storedScriptsMutex.Unlock()
+	fake.StoredScriptsStub = nil
+	fake.storedScriptsReturns = struct {
+		result1 map[string]*indexmanager.StoredScript
+	}{result1}
+}
+
+func (fake *FakeIndexManager) StoredScriptsReturnsOnCall(i int, result1 map[string]*indexmanager.StoredScript) {
+	fake.storedScriptsMutex.Lock()
+	defer fake.storedScriptsMutex.Unlock()
+	fake.StoredScriptsStub = nil
+	if fake.storedScriptsReturnsOnCall == nil {
+		fake.storedScriptsReturnsOnCall = make(map[int]struct {
+			result1 map[string]*indexmanager.StoredScript
+		})
+	}
+	fake.storedScriptsReturnsOnCall[i] = struct {
+		result1 map[string]*indexmanager.StoredScript
+	}{result1}
+}
+
+func (fake *FakeIndexManager) TimeSeriesIndexName(arg1 string, arg2 int, arg3 string) string {
+	fake.timeSeriesIndexNameMutex.Lock()
+	ret, specificReturn := fake.timeSeriesIndexNameReturnsOnCall[len(fake.timeSeriesIndexNameArgsForCall)]
+	fake.timeSeriesIndexNameArgsForCall = append(fake.timeSeriesIndexNameArgsForCall, struct {
+		arg1 string
+		arg2 int
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.TimeSeriesIndexNameStub
+	fakeReturns := fake.timeSeriesIndexNameReturns
+	fake.recordInvocation("TimeSeriesIndexName", []interface{}{arg1, arg2, arg3})
+	fake.timeSeriesIndexNameMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIndexManager) TimeSeriesIndexNameCallCount() int {
+	fake.timeSeriesIndexNameMutex.RLock()
+	defer fake.timeSeriesIndexNameMutex.RUnlock()
+	return len(fake.timeSeriesIndexNameArgsForCall)
+}
+
+func (fake *FakeIndexManager) TimeSeriesIndexNameCalls(stub func(string, int, string) string) {
+	fake.timeSeriesIndexNameMutex.Lock()
+	defer fake.timeSeriesIndexNameMutex.Unlock()
+	fake.TimeSeriesIndexNameStub = stub
+}
+
+func (fake *FakeIndexManager) TimeSeriesIndexNameArgsForCall(i int) (string, int, string) {
+	fake.timeSeriesIndexNameMutex.RLock()
+	defer fake.timeSeriesIndexNameMutex.RUnlock()
+	argsForCall := fake.timeSeriesIndexNameArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeIndexManager) TimeSeriesIndexNameReturns(result1 string) {
+	fake.timeSeriesIndexNameMutex.Lock()
+	defer fake.timeSeriesIndexNameMutex.Unlock()
+	fake.TimeSeriesIndexNameStub = nil
+	fake.timeSeriesIndexNameReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeIndexManager) TimeSeriesIndexNameReturnsOnCall(i int, result1 string) {
+	fake.timeSeriesIndexNameMutex.Lock()
+	defer fake.timeSeriesIndexNameMutex.Unlock()
+	fake.TimeSeriesIndexNameStub = nil
+	if fake.timeSeriesIndexNameReturnsOnCall == nil {
+		fake.timeSeriesIndexNameReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.timeSeriesIndexNameReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeIndexManager) UpdateSettings(arg1 context.Context) ([]*indexmanager.SettingsChange, error) {
+	fake.updateSettingsMutex.Lock()
+	ret, specificReturn := fake.updateSettingsReturnsOnCall[len(fake.updateSettingsArgsForCall)]
+	fake.updateSettingsArgsForCall = append(fake.updateSettingsArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.UpdateSettingsStub
+	fakeReturns := fake.updateSettingsReturns
+	fake.recordInvocation("UpdateSettings", []interface{}{arg1})
+	fake.updateSettingsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIndexManager) UpdateSettingsCallCount() int {
+	fake.updateSettingsMutex.RLock()
+	defer fake.updateSettingsMutex.RUnlock()
+	return len(fake.updateSettingsArgsForCall)
+}
+
+func (fake *FakeIndexManager) UpdateSettingsCalls(stub func(context.Context) ([]*indexmanager.SettingsChange, error)) {
+	fake.updateSettingsMutex.Lock()
+	defer fake.updateSettingsMutex.Unlock()
+	fake.UpdateSettingsStub = stub
+}
+
+func (fake *FakeIndexManager) UpdateSettingsArgsForCall(i int) context.Context {
+	fake.updateSettingsMutex.RLock()
+	defer fake.updateSettingsMutex.RUnlock()
+	argsForCall := fake.updateSettingsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIndexManager) UpdateSettingsReturns(result1 []*indexmanager.SettingsChange, result2 error) {
+	fake.updateSettingsMutex.Lock()
+	defer fake.updateSettingsMutex.Unlock()
+	fake.UpdateSettingsStub = nil
+	fake.updateSettingsReturns = struct {
+		result1 []*indexmanager.SettingsChange
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexManager) UpdateSettingsReturnsOnCall(i int, result1 []*indexmanager.SettingsChange, result2 error) {
+	fake.updateSettingsMutex.Lock()
+	defer fake.updateSettingsMutex.Unlock()
+	fake.UpdateSettingsStub = nil
+	if fake.updateSettingsReturnsOnCall == nil {
+		fake.updateSettingsReturnsOnCall = make(map[int]struct {
+			result1 []*indexmanager.SettingsChange
+			result2 error
+		})
+	}
+	fake.updateSettingsReturnsOnCall[i] = struct {
+		result1 []*indexmanager.SettingsChange
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeIndexManager) Version(arg1 string) string {
 	fake.versionMutex.Lock()
 	ret, specificReturn := fake.versionReturnsOnCall[len(fake.versionArgsForCall)]
@@ -736,29 +2301,137 @@ func (fake *FakeIndexManager) VersionReturnsOnCall(i int, result1 string) {
 	}{result1}
 }
 
+func (fake *FakeIndexManager) Watch(arg1 context.Context) (<-chan *indexmanager.MappingsReloadEvent, error) {
+	fake.watchMutex.Lock()
+	ret, specificReturn := fake.watchReturnsOnCall[len(fake.watchArgsForCall)]
+	fake.watchArgsForCall = append(fake.watchArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.WatchStub
+	fakeReturns := fake.watchReturns
+	fake.recordInvocation("Watch", []interface{}{arg1})
+	fake.watchMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIndexManager) WatchCallCount() int {
+	fake.watchMutex.RLock()
+	defer fake.watchMutex.RUnlock()
+	return len(fake.watchArgsForCall)
+}
+
+func (fake *FakeIndexManager) WatchCalls(stub func(context.Context) (<-chan *indexmanager.MappingsReloadEvent, error)) {
+	fake.watchMutex.Lock()
+	defer fake.watchMutex.Unlock()
+	fake.WatchStub = stub
+}
+
+func (fake *FakeIndexManager) WatchArgsForCall(i int) context.Context {
+	fake.watchMutex.RLock()
+	defer fake.watchMutex.RUnlock()
+	argsForCall := fake.watchArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIndexManager) WatchReturns(result1 <-chan *indexmanager.MappingsReloadEvent, result2 error) {
+	fake.watchMutex.Lock()
+	defer fake.watchMutex.Unlock()
+	fake.WatchStub = nil
+	fake.watchReturns = struct {
+		result1 <-chan *indexmanager.MappingsReloadEvent
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexManager) WatchReturnsOnCall(i int, result1 <-chan *indexmanager.MappingsReloadEvent, result2 error) {
+	fake.watchMutex.Lock()
+	defer fake.watchMutex.Unlock()
+	fake.WatchStub = nil
+	if fake.watchReturnsOnCall == nil {
+		fake.watchReturnsOnCall = make(map[int]struct {
+			result1 <-chan *indexmanager.MappingsReloadEvent
+			result2 error
+		})
+	}
+	fake.watchReturnsOnCall[i] = struct {
+		result1 <-chan *indexmanager.MappingsReloadEvent
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeIndexManager) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.adoptMutex.RLock()
+	defer fake.adoptMutex.RUnlock()
 	fake.aliasNameMutex.RLock()
 	defer fake.aliasNameMutex.RUnlock()
+	fake.checkDriftMutex.RLock()
+	defer fake.checkDriftMutex.RUnlock()
+	fake.cleanupMutex.RLock()
+	defer fake.cleanupMutex.RUnlock()
 	fake.createIndexMutex.RLock()
 	defer fake.createIndexMutex.RUnlock()
+	fake.currentDocumentKindMutex.RLock()
+	defer fake.currentDocumentKindMutex.RUnlock()
 	fake.deleteIndexMutex.RLock()
 	defer fake.deleteIndexMutex.RUnlock()
+	fake.documentKindsMutex.RLock()
+	defer fake.documentKindsMutex.RUnlock()
+	fake.exportMutex.RLock()
+	defer fake.exportMutex.RUnlock()
+	fake.getSettingsChangesMutex.RLock()
+	defer fake.getSettingsChangesMutex.RUnlock()
+	fake.iLMPoliciesMutex.RLock()
+	defer fake.iLMPoliciesMutex.RUnlock()
+	fake.importMutex.RLock()
+	defer fake.importMutex.RUnlock()
+	fake.indexDriftMutex.RLock()
+	defer fake.indexDriftMutex.RUnlock()
 	fake.indexNameMutex.RLock()
 	defer fake.indexNameMutex.RUnlock()
+	fake.indexPrefixMutex.RLock()
+	defer fake.indexPrefixMutex.RUnlock()
 	fake.initializeMutex.RLock()
 	defer fake.initializeMutex.RUnlock()
+	fake.installResourcesMutex.RLock()
+	defer fake.installResourcesMutex.RUnlock()
 	fake.loadMappingsMutex.RLock()
 	defer fake.loadMappingsMutex.RUnlock()
 	fake.mappingMutex.RLock()
 	defer fake.mappingMutex.RUnlock()
 	fake.parseIndexNameMutex.RLock()
 	defer fake.parseIndexNameMutex.RUnlock()
+	fake.pipelinesMutex.RLock()
+	defer fake.pipelinesMutex.RUnlock()
+	fake.resourceDriftMutex.RLock()
+	defer fake.resourceDriftMutex.RUnlock()
+	fake.resourceNameMutex.RLock()
+	defer fake.resourceNameMutex.RUnlock()
+	fake.rolloverMutex.RLock()
+	defer fake.rolloverMutex.RUnlock()
 	fake.runMigrationsMutex.RLock()
 	defer fake.runMigrationsMutex.RUnlock()
+	fake.seedMutex.RLock()
+	defer fake.seedMutex.RUnlock()
+	fake.seedsMutex.RLock()
+	defer fake.seedsMutex.RUnlock()
+	fake.storedScriptsMutex.RLock()
+	defer fake.storedScriptsMutex.RUnlock()
+	fake.timeSeriesIndexNameMutex.RLock()
+	defer fake.timeSeriesIndexNameMutex.RUnlock()
+	fake.updateSettingsMutex.RLock()
+	defer fake.updateSettingsMutex.RUnlock()
 	fake.versionMutex.RLock()
 	defer fake.versionMutex.RUnlock()
+	fake.watchMutex.RLock()
+	defer fake.watchMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -35,6 +35,21 @@ type FakeIndexRepository struct {
 	deleteIndexReturnsOnCall map[int]struct {
 		result1 error
 	}
+	RolloverStub        func(context.Context, string, string) (*indexmanager.RolloverResult, error)
+	rolloverMutex       sync.RWMutex
+	rolloverArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	rolloverReturns struct {
+		result1 *indexmanager.RolloverResult
+		result2 error
+	}
+	rolloverReturnsOnCall map[int]struct {
+		result1 *indexmanager.RolloverResult
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -165,6 +180,72 @@ func (fake *FakeIndexRepository) DeleteIndexReturnsOnCall(i int, result1 error)
 	}{result1}
 }
 
+func (fake *FakeIndexRepository) Rollover(arg1 context.Context, arg2 string, arg3 string) (*indexmanager.RolloverResult, error) {
+	fake.rolloverMutex.Lock()
+	ret, specificReturn := fake.rolloverReturnsOnCall[len(fake.rolloverArgsForCall)]
+	fake.rolloverArgsForCall = append(fake.rolloverArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.RolloverStub
+	fakeReturns := fake.rolloverReturns
+	fake.recordInvocation("Rollover", []interface{}{arg1, arg2, arg3})
+	fake.rolloverMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIndexRepository) RolloverCallCount() int {
+	fake.rolloverMutex.RLock()
+	defer fake.rolloverMutex.RUnlock()
+	return len(fake.rolloverArgsForCall)
+}
+
+func (fake *FakeIndexRepository) RolloverCalls(stub func(context.Context, string, string) (*indexmanager.RolloverResult, error)) {
+	fake.rolloverMutex.Lock()
+	defer fake.rolloverMutex.Unlock()
+	fake.RolloverStub = stub
+}
+
+func (fake *FakeIndexRepository) RolloverArgsForCall(i int) (context.Context, string, string) {
+	fake.rolloverMutex.RLock()
+	defer fake.rolloverMutex.RUnlock()
+	argsForCall := fake.rolloverArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeIndexRepository) RolloverReturns(result1 *indexmanager.RolloverResult, result2 error) {
+	fake.rolloverMutex.Lock()
+	defer fake.rolloverMutex.Unlock()
+	fake.RolloverStub = nil
+	fake.rolloverReturns = struct {
+		result1 *indexmanager.RolloverResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIndexRepository) RolloverReturnsOnCall(i int, result1 *indexmanager.RolloverResult, result2 error) {
+	fake.rolloverMutex.Lock()
+	defer fake.rolloverMutex.Unlock()
+	fake.RolloverStub = nil
+	if fake.rolloverReturnsOnCall == nil {
+		fake.rolloverReturnsOnCall = make(map[int]struct {
+			result1 *indexmanager.RolloverResult
+			result2 error
+		})
+	}
+	fake.rolloverReturnsOnCall[i] = struct {
+		result1 *indexmanager.RolloverResult
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeIndexRepository) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -172,6 +253,8 @@ func (fake *FakeIndexRepository) Invocations() map[string][][]interface{} {
 	defer fake.createIndexMutex.RUnlock()
 	fake.deleteIndexMutex.RLock()
 	defer fake.deleteIndexMutex.RUnlock()
+	fake.rolloverMutex.RLock()
+	defer fake.rolloverMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -2,6 +2,7 @@
 package mocks
 
 import (
+	"context"
 	"sync"
 
 	"github.com/rode/es-index-manager/indexmanager"
@@ -20,6 +21,37 @@ type FakeMappingsRegistry struct {
 	aliasNameReturnsOnCall map[int]struct {
 		result1 string
 	}
+	CurrentDocumentKindStub        func(string) string
+	currentDocumentKindMutex       sync.RWMutex
+	currentDocumentKindArgsForCall []struct {
+		arg1 string
+	}
+	currentDocumentKindReturns struct {
+		result1 string
+	}
+	currentDocumentKindReturnsOnCall map[int]struct {
+		result1 string
+	}
+	DocumentKindsStub        func() []string
+	documentKindsMutex       sync.RWMutex
+	documentKindsArgsForCall []struct {
+	}
+	documentKindsReturns struct {
+		result1 []string
+	}
+	documentKindsReturnsOnCall map[int]struct {
+		result1 []string
+	}
+	ILMPoliciesStub        func() map[string]*indexmanager.VersionedPolicy
+	iLMPoliciesMutex       sync.RWMutex
+	iLMPoliciesArgsForCall []struct {
+	}
+	iLMPoliciesReturns struct {
+		result1 map[string]*indexmanager.VersionedPolicy
+	}
+	iLMPoliciesReturnsOnCall map[int]struct {
+		result1 map[string]*indexmanager.VersionedPolicy
+	}
 	IndexNameStub        func(string, string) string
 	indexNameMutex       sync.RWMutex
 	indexNameArgsForCall []struct {
@@ -32,6 +64,16 @@ type FakeMappingsRegistry struct {
 	indexNameReturnsOnCall map[int]struct {
 		result1 string
 	}
+	IndexPrefixStub        func() string
+	indexPrefixMutex       sync.RWMutex
+	indexPrefixArgsForCall []struct {
+	}
+	indexPrefixReturns struct {
+		result1 string
+	}
+	indexPrefixReturnsOnCall map[int]struct {
+		result1 string
+	}
 	LoadMappingsStub        func() error
 	loadMappingsMutex       sync.RWMutex
 	loadMappingsArgsForCall []struct {
@@ -64,6 +106,61 @@ type FakeMappingsRegistry struct {
 	parseIndexNameReturnsOnCall map[int]struct {
 		result1 *indexmanager.IndexName
 	}
+	PipelinesStub        func() map[string]*indexmanager.VersionedPipeline
+	pipelinesMutex       sync.RWMutex
+	pipelinesArgsForCall []struct {
+	}
+	pipelinesReturns struct {
+		result1 map[string]*indexmanager.VersionedPipeline
+	}
+	pipelinesReturnsOnCall map[int]struct {
+		result1 map[string]*indexmanager.VersionedPipeline
+	}
+	ResourceNameStub        func(string) string
+	resourceNameMutex       sync.RWMutex
+	resourceNameArgsForCall []struct {
+		arg1 string
+	}
+	resourceNameReturns struct {
+		result1 string
+	}
+	resourceNameReturnsOnCall map[int]struct {
+		result1 string
+	}
+	SeedsStub        func(string) []byte
+	seedsMutex       sync.RWMutex
+	seedsArgsForCall []struct {
+		arg1 string
+	}
+	seedsReturns struct {
+		result1 []byte
+	}
+	seedsReturnsOnCall map[int]struct {
+		result1 []byte
+	}
+	StoredScriptsStub        func() map[string]*indexmanager.StoredScript
+	storedScriptsMutex       sync.RWMutex
+	storedScriptsArgsForCall []struct {
+	}
+	storedScriptsReturns struct {
+		result1 map[string]*indexmanager.StoredScript
+	}
+	storedScriptsReturnsOnCall map[int]struct {
+		result1 map[string]*indexmanager.StoredScript
+	}
+	TimeSeriesIndexNameStub        func(string, int, string) string
+	timeSeriesIndexNameMutex       sync.RWMutex
+	timeSeriesIndexNameArgsForCall []struct {
+		arg1 string
+		arg2 int
+		arg3 string
+	}
+	timeSeriesIndexNameReturns struct {
+		result1 string
+	}
+	timeSeriesIndexNameReturnsOnCall map[int]struct {
+		result1 string
+	}
 	VersionStub        func(string) string
 	versionMutex       sync.RWMutex
 	versionArgsForCall []struct {
@@ -75,6 +172,19 @@ type FakeMappingsRegistry struct {
 	versionReturnsOnCall map[int]struct {
 		result1 string
 	}
+	WatchStub        func(context.Context) (<-chan *indexmanager.MappingsReloadEvent, error)
+	watchMutex       sync.RWMutex
+	watchArgsForCall []struct {
+		arg1 context.Context
+	}
+	watchReturns struct {
+		result1 <-chan *indexmanager.MappingsReloadEvent
+		result2 error
+	}
+	watchReturnsOnCall map[int]struct {
+		result1 <-chan *indexmanager.MappingsReloadEvent
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -141,6 +251,173 @@ func (fake *FakeMappingsRegistry) AliasNameReturnsOnCall(i int, result1 string)
 	}{result1}
 }
 
+func (fake *FakeMappingsRegistry) CurrentDocumentKind(arg1 string) string {
+	fake.currentDocumentKindMutex.Lock()
+	ret, specificReturn := fake.currentDocumentKindReturnsOnCall[len(fake.currentDocumentKindArgsForCall)]
+	fake.currentDocumentKindArgsForCall = append(fake.currentDocumentKindArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.CurrentDocumentKindStub
+	fakeReturns := fake.currentDocumentKindReturns
+	fake.recordInvocation("CurrentDocumentKind", []interface{}{arg1})
+	fake.currentDocumentKindMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMappingsRegistry) CurrentDocumentKindCallCount() int {
+	fake.currentDocumentKindMutex.RLock()
+	defer fake.currentDocumentKindMutex.RUnlock()
+	return len(fake.currentDocumentKindArgsForCall)
+}
+
+func (fake *FakeMappingsRegistry) CurrentDocumentKindCalls(stub func(string) string) {
+	fake.currentDocumentKindMutex.Lock()
+	defer fake.currentDocumentKindMutex.Unlock()
+	fake.CurrentDocumentKindStub = stub
+}
+
+func (fake *FakeMappingsRegistry) CurrentDocumentKindArgsForCall(i int) string {
+	fake.currentDocumentKindMutex.RLock()
+	defer fake.currentDocumentKindMutex.RUnlock()
+	argsForCall := fake.currentDocumentKindArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeMappingsRegistry) CurrentDocumentKindReturns(result1 string) {
+	fake.currentDocumentKindMutex.Lock()
+	defer fake.currentDocumentKindMutex.Unlock()
+	fake.CurrentDocumentKindStub = nil
+	fake.currentDocumentKindReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeMappingsRegistry) CurrentDocumentKindReturnsOnCall(i int, result1 string) {
+	fake.currentDocumentKindMutex.Lock()
+	defer fake.currentDocumentKindMutex.Unlock()
+	fake.CurrentDocumentKindStub = nil
+	if fake.currentDocumentKindReturnsOnCall == nil {
+		fake.currentDocumentKindReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.currentDocumentKindReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeMappingsRegistry) DocumentKinds() []string {
+	fake.documentKindsMutex.Lock()
+	ret, specificReturn := fake.documentKindsReturnsOnCall[len(fake.documentKindsArgsForCall)]
+	fake.documentKindsArgsForCall = append(fake.documentKindsArgsForCall, struct {
+	}{})
+	stub := fake.DocumentKindsStub
+	fakeReturns := fake.documentKindsReturns
+	fake.recordInvocation("DocumentKinds", []interface{}{})
+	fake.documentKindsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMappingsRegistry) DocumentKindsCallCount() int {
+	fake.documentKindsMutex.RLock()
+	defer fake.documentKindsMutex.RUnlock()
+	return len(fake.documentKindsArgsForCall)
+}
+
+func (fake *FakeMappingsRegistry) DocumentKindsCalls(stub func() []string) {
+	fake.documentKindsMutex.Lock()
+	defer fake.documentKindsMutex.Unlock()
+	fake.DocumentKindsStub = stub
+}
+
+func (fake *FakeMappingsRegistry) DocumentKindsReturns(result1 []string) {
+	fake.documentKindsMutex.Lock()
+	defer fake.documentKindsMutex.Unlock()
+	fake.DocumentKindsStub = nil
+	fake.documentKindsReturns = struct {
+		result1 []string
+	}{result1}
+}
+
+func (fake *FakeMappingsRegistry) DocumentKindsReturnsOnCall(i int, result1 []string) {
+	fake.documentKindsMutex.Lock()
+	defer fake.documentKindsMutex.Unlock()
+	fake.DocumentKindsStub = nil
+	if fake.documentKindsReturnsOnCall == nil {
+		fake.documentKindsReturnsOnCall = make(map[int]struct {
+			result1 []string
+		})
+	}
+	fake.documentKindsReturnsOnCall[i] = struct {
+		result1 []string
+	}{result1}
+}
+
+func (fake *FakeMappingsRegistry) ILMPolicies() map[string]*indexmanager.VersionedPolicy {
+	fake.iLMPoliciesMutex.Lock()
+	ret, specificReturn := fake.iLMPoliciesReturnsOnCall[len(fake.iLMPoliciesArgsForCall)]
+	fake.iLMPoliciesArgsForCall = append(fake.iLMPoliciesArgsForCall, struct {
+	}{})
+	stub := fake.ILMPoliciesStub
+	fakeReturns := fake.iLMPoliciesReturns
+	fake.recordInvocation("ILMPolicies", []interface{}{})
+	fake.iLMPoliciesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMappingsRegistry) ILMPoliciesCallCount() int {
+	fake.iLMPoliciesMutex.RLock()
+	defer fake.iLMPoliciesMutex.RUnlock()
+	return len(fake.iLMPoliciesArgsForCall)
+}
+
+func (fake *FakeMappingsRegistry) ILMPoliciesCalls(stub func() map[string]*indexmanager.VersionedPolicy) {
+	fake.iLMPoliciesMutex.Lock()
+	defer fake.iLMPoliciesMutex.Unlock()
+	fake.ILMPoliciesStub = stub
+}
+
+func (fake *FakeMappingsRegistry) ILMPoliciesReturns(result1 map[string]*indexmanager.VersionedPolicy) {
+	fake.iLMPoliciesMutex.Lock()
+	defer fake.iLMPoliciesMutex.Unlock()
+	fake.ILMPoliciesStub = nil
+	fake.iLMPoliciesReturns = struct {
+		result1 map[string]*indexmanager.VersionedPolicy
+	}{result1}
+}
+
+func (fake *FakeMappingsRegistry) ILMPoliciesReturnsOnCall(i int, result1 map[string]*indexmanager.VersionedPolicy) {
+	fake.iLMPoliciesMutex.Lock()
+	defer fake.iLMPoliciesMutex.Unlock()
+	fake.ILMPoliciesStub = nil
+	if fake.iLMPoliciesReturnsOnCall == nil {
+		fake.iLMPoliciesReturnsOnCall = make(map[int]struct {
+			result1 map[string]*indexmanager.VersionedPolicy
+		})
+	}
+	fake.iLMPoliciesReturnsOnCall[i] = struct {
+		result1 map[string]*indexmanager.VersionedPolicy
+	}{result1}
+}
+
 func (fake *FakeMappingsRegistry) IndexName(arg1 string, arg2 string) string {
 	fake.indexNameMutex.Lock()
 	ret, specificReturn := fake.indexNameReturnsOnCall[len(fake.indexNameArgsForCall)]
@@ -203,6 +480,59 @@ func (fake *FakeMappingsRegistry) IndexNameReturnsOnCall(i int, result1 string)
 	}{result1}
 }
 
+func (fake *FakeMappingsRegistry) IndexPrefix() string {
+	fake.indexPrefixMutex.Lock()
+	ret, specificReturn := fake.indexPrefixReturnsOnCall[len(fake.indexPrefixArgsForCall)]
+	fake.indexPrefixArgsForCall = append(fake.indexPrefixArgsForCall, struct {
+	}{})
+	stub := fake.IndexPrefixStub
+	fakeReturns := fake.indexPrefixReturns
+	fake.recordInvocation("IndexPrefix", []interface{}{})
+	fake.indexPrefixMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMappingsRegistry) IndexPrefixCallCount() int {
+	fake.indexPrefixMutex.RLock()
+	defer fake.indexPrefixMutex.RUnlock()
+	return len(fake.indexPrefixArgsForCall)
+}
+
+func (fake *FakeMappingsRegistry) IndexPrefixCalls(stub func() string) {
+	fake.indexPrefixMutex.Lock()
+	defer fake.indexPrefixMutex.Unlock()
+	fake.IndexPrefixStub = stub
+}
+
+func (fake *FakeMappingsRegistry) IndexPrefixReturns(result1 string) {
+	fake.indexPrefixMutex.Lock()
+	defer fake.indexPrefixMutex.Unlock()
+	fake.IndexPrefixStub = nil
+	fake.indexPrefixReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeMappingsRegistry) IndexPrefixReturnsOnCall(i int, result1 string) {
+	fake.indexPrefixMutex.Lock()
+	defer fake.indexPrefixMutex.Unlock()
+	fake.IndexPrefixStub = nil
+	if fake.indexPrefixReturnsOnCall == nil {
+		fake.indexPrefixReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.indexPrefixReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
 func (fake *FakeMappingsRegistry) LoadMappings() error {
 	fake.loadMappingsMutex.Lock()
 	ret, specificReturn := fake.loadMappingsReturnsOnCall[len(fake.loadMappingsArgsForCall)]
@@ -378,6 +708,297 @@ func (fake *FakeMappingsRegistry) ParseIndexNameReturnsOnCall(i int, result1 *in
 	}{result1}
 }
 
+func (fake *FakeMappingsRegistry) Pipelines() map[string]*indexmanager.VersionedPipeline {
+	fake.pipelinesMutex.Lock()
+	ret, specificReturn := fake.pipelinesReturnsOnCall[len(fake.pipelinesArgsForCall)]
+	fake.pipelinesArgsForCall = append(fake.pipelinesArgsForCall, struct {
+	}{})
+	stub := fake.PipelinesStub
+	fakeReturns := fake.pipelinesReturns
+	fake.recordInvocation("Pipelines", []interface{}{})
+	fake.pipelinesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMappingsRegistry) PipelinesCallCount() int {
+	fake.pipelinesMutex.RLock()
+	defer fake.pipelinesMutex.RUnlock()
+	return len(fake.pipelinesArgsForCall)
+}
+
+func (fake *FakeMappingsRegistry) PipelinesCalls(stub func() map[string]*indexmanager.VersionedPipeline) {
+	fake.pipelinesMutex.Lock()
+	defer fake.pipelinesMutex.Unlock()
+	fake.PipelinesStub = stub
+}
+
+func (fake *FakeMappingsRegistry) PipelinesReturns(result1 map[string]*indexmanager.VersionedPipeline) {
+	fake.pipelinesMutex.Lock()
+	defer fake.pipelinesMutex.Unlock()
+	fake.PipelinesStub = nil
+	fake.pipelinesReturns = struct {
+		result1 map[string]*indexmanager.VersionedPipeline
+	}{result1}
+}
+
+func (fake *FakeMappingsRegistry) PipelinesReturnsOnCall(i int, result1 map[string]*indexmanager.VersionedPipeline) {
+	fake.pipelinesMutex.Lock()
+	defer fake.pipelinesMutex.Unlock()
+	fake.PipelinesStub = nil
+	if fake.pipelinesReturnsOnCall == nil {
+		fake.pipelinesReturnsOnCall = make(map[int]struct {
+			result1 map[string]*indexmanager.VersionedPipeline
+		})
+	}
+	fake.pipelinesReturnsOnCall[i] = struct {
+		result1 map[string]*indexmanager.VersionedPipeline
+	}{result1}
+}
+
+func (fake *FakeMappingsRegistry) ResourceName(arg1 string) string {
+	fake.resourceNameMutex.Lock()
+	ret, specificReturn := fake.resourceNameReturnsOnCall[len(fake.resourceNameArgsForCall)]
+	fake.resourceNameArgsForCall = append(fake.resourceNameArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ResourceNameStub
+	fakeReturns := fake.resourceNameReturns
+	fake.recordInvocation("ResourceName", []interface{}{arg1})
+	fake.resourceNameMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMappingsRegistry) ResourceNameCallCount() int {
+	fake.resourceNameMutex.RLock()
+	defer fake.resourceNameMutex.RUnlock()
+	return len(fake.resourceNameArgsForCall)
+}
+
+func (fake *FakeMappingsRegistry) ResourceNameCalls(stub func(string) string) {
+	fake.resourceNameMutex.Lock()
+	defer fake.resourceNameMutex.Unlock()
+	fake.ResourceNameStub = stub
+}
+
+func (fake *FakeMappingsRegistry) ResourceNameArgsForCall(i int) string {
+	fake.resourceNameMutex.RLock()
+	defer fake.resourceNameMutex.RUnlock()
+	argsForCall := fake.resourceNameArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeMappingsRegistry) ResourceNameReturns(result1 string) {
+	fake.resourceNameMutex.Lock()
+	defer fake.resourceNameMutex.Unlock()
+	fake.ResourceNameStub = nil
+	fake.resourceNameReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeMappingsRegistry) ResourceNameReturnsOnCall(i int, result1 string) {
+	fake.resourceNameMutex.Lock()
+	defer fake.resourceNameMutex.Unlock()
+	fake.ResourceNameStub = nil
+	if fake.resourceNameReturnsOnCall == nil {
+		fake.resourceNameReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.resourceNameReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeMappingsRegistry) Seeds(arg1 string) []byte {
+	fake.seedsMutex.Lock()
+	ret, specificReturn := fake.seedsReturnsOnCall[len(fake.seedsArgsForCall)]
+	fake.seedsArgsForCall = append(fake.seedsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.SeedsStub
+	fakeReturns := fake.seedsReturns
+	fake.recordInvocation("Seeds", []interface{}{arg1})
+	fake.seedsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMappingsRegistry) SeedsCallCount() int {
+	fake.seedsMutex.RLock()
+	defer fake.seedsMutex.RUnlock()
+	return len(fake.seedsArgsForCall)
+}
+
+func (fake *FakeMappingsRegistry) SeedsCalls(stub func(string) []byte) {
+	fake.seedsMutex.Lock()
+	defer fake.seedsMutex.Unlock()
+	fake.SeedsStub = stub
+}
+
+func (fake *FakeMappingsRegistry) SeedsArgsForCall(i int) string {
+	fake.seedsMutex.RLock()
+	defer fake.seedsMutex.RUnlock()
+	argsForCall := fake.seedsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeMappingsRegistry) SeedsReturns(result1 []byte) {
+	fake.seedsMutex.Lock()
+	defer fake.seedsMutex.Unlock()
+	fake.SeedsStub = nil
+	fake.seedsReturns = struct {
+		result1 []byte
+	}{result1}
+}
+
+func (fake *FakeMappingsRegistry) SeedsReturnsOnCall(i int, result1 []byte) {
+	fake.seedsMutex.Lock()
+	defer fake.seedsMutex.Unlock()
+	fake.SeedsStub = nil
+	if fake.seedsReturnsOnCall == nil {
+		fake.seedsReturnsOnCall = make(map[int]struct {
+			result1 []byte
+		})
+	}
+	fake.seedsReturnsOnCall[i] = struct {
+		result1 []byte
+	}{result1}
+}
+
+func (fake *FakeMappingsRegistry) StoredScripts() map[string]*indexmanager.StoredScript {
+	fake.storedScriptsMutex.Lock()
+	ret, specificReturn := fake.storedScriptsReturnsOnCall[len(fake.storedScriptsArgsForCall)]
+	fake.storedScriptsArgsForCall = append(fake.storedScriptsArgsForCall, struct {
+	}{})
+	stub := fake.StoredScriptsStub
+	fakeReturns := fake.storedScriptsReturns
+	fake.recordInvocation("StoredScripts", []interface{}{})
+	fake.storedScriptsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMappingsRegistry) StoredScriptsCallCount() int {
+	fake.storedScriptsMutex.RLock()
+	defer fake.storedScriptsMutex.RUnlock()
+	return len(fake.storedScriptsArgsForCall)
+}
+
+func (fake *FakeMappingsRegistry) StoredScriptsCalls(stub func() map[string]*indexmanager.StoredScript) {
+	fake.storedScriptsMutex.Lock()
+	defer fake.storedScriptsMutex.Unlock()
+	fake.StoredScriptsStub = stub
+}
+
+func (fake *FakeMappingsRegistry) StoredScriptsReturns(result1 map[string]*indexmanager.StoredScript) {
+	fake.storedScriptsMutex.Lock()
+	defer fake.storedScriptsMutex.Unlock()
+	fake.StoredScriptsStub = nil
+	fake.storedScriptsReturns = struct {
+		result1 map[string]*indexmanager.StoredScript
+	}{result1}
+}
+
+func (fake *FakeMappingsRegistry) StoredScriptsReturnsOnCall(i int, result1 map[string]*indexmanager.StoredScript) {
+	fake.storedScriptsMutex.Lock()
+	defer fake.storedScriptsMutex.Unlock()
+	fake.StoredScriptsStub = nil
+	if fake.storedScriptsReturnsOnCall == nil {
+		fake.storedScriptsReturnsOnCall = make(map[int]struct {
+			result1 map[string]*indexmanager.StoredScript
+		})
+	}
+	fake.storedScriptsReturnsOnCall[i] = struct {
+		result1 map[string]*indexmanager.StoredScript
+	}{result1}
+}
+
+func (fake *FakeMappingsRegistry) TimeSeriesIndexName(arg1 string, arg2 int, arg3 string) string {
+	fake.timeSeriesIndexNameMutex.Lock()
+	ret, specificReturn := fake.timeSeriesIndexNameReturnsOnCall[len(fake.timeSeriesIndexNameArgsForCall)]
+	fake.timeSeriesIndexNameArgsForCall = append(fake.timeSeriesIndexNameArgsForCall, struct {
+		arg1 string
+		arg2 int
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.TimeSeriesIndexNameStub
+	fakeReturns := fake.timeSeriesIndexNameReturns
+	fake.recordInvocation("TimeSeriesIndexName", []interface{}{arg1, arg2, arg3})
+	fake.timeSeriesIndexNameMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMappingsRegistry) TimeSeriesIndexNameCallCount() int {
+	fake.timeSeriesIndexNameMutex.RLock()
+	defer fake.timeSeriesIndexNameMutex.RUnlock()
+	return len(fake.timeSeriesIndexNameArgsForCall)
+}
+
+func (fake *FakeMappingsRegistry) TimeSeriesIndexNameCalls(stub func(string, int, string) string) {
+	fake.timeSeriesIndexNameMutex.Lock()
+	defer fake.timeSeriesIndexNameMutex.Unlock()
+	fake.TimeSeriesIndexNameStub = stub
+}
+
+func (fake *FakeMappingsRegistry) TimeSeriesIndexNameArgsForCall(i int) (string, int, string) {
+	fake.timeSeriesIndexNameMutex.RLock()
+	defer fake.timeSeriesIndexNameMutex.RUnlock()
+	argsForCall := fake.timeSeriesIndexNameArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeMappingsRegistry) TimeSeriesIndexNameReturns(result1 string) {
+	fake.timeSeriesIndexNameMutex.Lock()
+	defer fake.timeSeriesIndexNameMutex.Unlock()
+	fake.TimeSeriesIndexNameStub = nil
+	fake.timeSeriesIndexNameReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeMappingsRegistry) TimeSeriesIndexNameReturnsOnCall(i int, result1 string) {
+	fake.timeSeriesIndexNameMutex.Lock()
+	defer fake.timeSeriesIndexNameMutex.Unlock()
+	fake.TimeSeriesIndexNameStub = nil
+	if fake.timeSeriesIndexNameReturnsOnCall == nil {
+		fake.timeSeriesIndexNameReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.timeSeriesIndexNameReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
 func (fake *FakeMappingsRegistry) Version(arg1 string) string {
 	fake.versionMutex.Lock()
 	ret, specificReturn := fake.versionReturnsOnCall[len(fake.versionArgsForCall)]
@@ -439,21 +1060,105 @@ func (fake *FakeMappingsRegistry) VersionReturnsOnCall(i int, result1 string) {
 	}{result1}
 }
 
+func (fake *FakeMappingsRegistry) Watch(arg1 context.Context) (<-chan *indexmanager.MappingsReloadEvent, error) {
+	fake.watchMutex.Lock()
+	ret, specificReturn := fake.watchReturnsOnCall[len(fake.watchArgsForCall)]
+	fake.watchArgsForCall = append(fake.watchArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.WatchStub
+	fakeReturns := fake.watchReturns
+	fake.recordInvocation("Watch", []interface{}{arg1})
+	fake.watchMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeMappingsRegistry) WatchCallCount() int {
+	fake.watchMutex.RLock()
+	defer fake.watchMutex.RUnlock()
+	return len(fake.watchArgsForCall)
+}
+
+func (fake *FakeMappingsRegistry) WatchCalls(stub func(context.Context) (<-chan *indexmanager.MappingsReloadEvent, error)) {
+	fake.watchMutex.Lock()
+	defer fake.watchMutex.Unlock()
+	fake.WatchStub = stub
+}
+
+func (fake *FakeMappingsRegistry) WatchArgsForCall(i int) context.Context {
+	fake.watchMutex.RLock()
+	defer fake.watchMutex.RUnlock()
+	argsForCall := fake.watchArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeMappingsRegistry) WatchReturns(result1 <-chan *indexmanager.MappingsReloadEvent, result2 error) {
+	fake.watchMutex.Lock()
+	defer fake.watchMutex.Unlock()
+	fake.WatchStub = nil
+	fake.watchReturns = struct {
+		result1 <-chan *indexmanager.MappingsReloadEvent
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMappingsRegistry) WatchReturnsOnCall(i int, result1 <-chan *indexmanager.MappingsReloadEvent, result2 error) {
+	fake.watchMutex.Lock()
+	defer fake.watchMutex.Unlock()
+	fake.WatchStub = nil
+	if fake.watchReturnsOnCall == nil {
+		fake.watchReturnsOnCall = make(map[int]struct {
+			result1 <-chan *indexmanager.MappingsReloadEvent
+			result2 error
+		})
+	}
+	fake.watchReturnsOnCall[i] = struct {
+		result1 <-chan *indexmanager.MappingsReloadEvent
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeMappingsRegistry) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.aliasNameMutex.RLock()
 	defer fake.aliasNameMutex.RUnlock()
+	fake.currentDocumentKindMutex.RLock()
+	defer fake.currentDocumentKindMutex.RUnlock()
+	fake.documentKindsMutex.RLock()
+	defer fake.documentKindsMutex.RUnlock()
+	fake.iLMPoliciesMutex.RLock()
+	defer fake.iLMPoliciesMutex.RUnlock()
 	fake.indexNameMutex.RLock()
 	defer fake.indexNameMutex.RUnlock()
+	fake.indexPrefixMutex.RLock()
+	defer fake.indexPrefixMutex.RUnlock()
 	fake.loadMappingsMutex.RLock()
 	defer fake.loadMappingsMutex.RUnlock()
 	fake.mappingMutex.RLock()
 	defer fake.mappingMutex.RUnlock()
 	fake.parseIndexNameMutex.RLock()
 	defer fake.parseIndexNameMutex.RUnlock()
+	fake.pipelinesMutex.RLock()
+	defer fake.pipelinesMutex.RUnlock()
+	fake.resourceNameMutex.RLock()
+	defer fake.resourceNameMutex.RUnlock()
+	fake.seedsMutex.RLock()
+	defer fake.seedsMutex.RUnlock()
+	fake.storedScriptsMutex.RLock()
+	defer fake.storedScriptsMutex.RUnlock()
+	fake.timeSeriesIndexNameMutex.RLock()
+	defer fake.timeSeriesIndexNameMutex.RUnlock()
 	fake.versionMutex.RLock()
 	defer fake.versionMutex.RUnlock()
+	fake.watchMutex.RLock()
+	defer fake.watchMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
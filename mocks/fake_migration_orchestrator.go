@@ -9,22 +9,24 @@ import (
 )
 
 type FakeMigrationOrchestrator struct {
-	RunMigrationsStub        func(context.Context) error
+	RunMigrationsStub        func(context.Context) (*indexmanager.MigrationReport, error)
 	runMigrationsMutex       sync.RWMutex
 	runMigrationsArgsForCall []struct {
 		arg1 context.Context
 	}
 	runMigrationsReturns struct {
-		result1 error
+		result1 *indexmanager.MigrationReport
+		result2 error
 	}
 	runMigrationsReturnsOnCall map[int]struct {
-		result1 error
+		result1 *indexmanager.MigrationReport
+		result2 error
 	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeMigrationOrchestrator) RunMigrations(arg1 context.Context) error {
+func (fake *FakeMigrationOrchestrator) RunMigrations(arg1 context.Context) (*indexmanager.MigrationReport, error) {
 	fake.runMigrationsMutex.Lock()
 	ret, specificReturn := fake.runMigrationsReturnsOnCall[len(fake.runMigrationsArgsForCall)]
 	fake.runMigrationsArgsForCall = append(fake.runMigrationsArgsForCall, struct {
@@ -38,9 +40,9 @@ func (fake *FakeMigrationOrchestrator) RunMigrations(arg1 context.Context) error
 		return stub(arg1)
 	}
 	if specificReturn {
-		return ret.result1
+		return ret.result1, ret.result2
 	}
-	return fakeReturns.result1
+	return fakeReturns.result1, fakeReturns.result2
 }
 
 func (fake *FakeMigrationOrchestrator) RunMigrationsCallCount() int {
@@ -49,7 +51,7 @@ func (fake *FakeMigrationOrchestrator) RunMigrationsCallCount() int {
 	return len(fake.runMigrationsArgsForCall)
 }
 
-func (fake *FakeMigrationOrchestrator) RunMigrationsCalls(stub func(context.Context) error) {
+func (fake *FakeMigrationOrchestrator) RunMigrationsCalls(stub func(context.Context) (*indexmanager.MigrationReport, error)) {
 	fake.runMigrationsMutex.Lock()
 	defer fake.runMigrationsMutex.Unlock()
 	fake.RunMigrationsStub = stub
@@ -62,27 +64,30 @@ func (fake *FakeMigrationOrchestrator) RunMigrationsArgsForCall(i int) context.C
 	return argsForCall.arg1
 }
 
-func (fake *FakeMigrationOrchestrator) RunMigrationsReturns(result1 error) {
+func (fake *FakeMigrationOrchestrator) RunMigrationsReturns(result1 *indexmanager.MigrationReport, result2 error) {
 	fake.runMigrationsMutex.Lock()
 	defer fake.runMigrationsMutex.Unlock()
 	fake.RunMigrationsStub = nil
 	fake.runMigrationsReturns = struct {
-		result1 error
-	}{result1}
+		result1 *indexmanager.MigrationReport
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeMigrationOrchestrator) RunMigrationsReturnsOnCall(i int, result1 error) {
+func (fake *FakeMigrationOrchestrator) RunMigrationsReturnsOnCall(i int, result1 *indexmanager.MigrationReport, result2 error) {
 	fake.runMigrationsMutex.Lock()
 	defer fake.runMigrationsMutex.Unlock()
 	fake.RunMigrationsStub = nil
 	if fake.runMigrationsReturnsOnCall == nil {
 		fake.runMigrationsReturnsOnCall = make(map[int]struct {
-			result1 error
+			result1 *indexmanager.MigrationReport
+			result2 error
 		})
 	}
 	fake.runMigrationsReturnsOnCall[i] = struct {
-		result1 error
-	}{result1}
+		result1 *indexmanager.MigrationReport
+		result2 error
+	}{result1, result2}
 }
 
 func (fake *FakeMigrationOrchestrator) Invocations() map[string][][]interface{} {
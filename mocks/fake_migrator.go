@@ -9,6 +9,21 @@ import (
 )
 
 type FakeMigrator struct {
+	AdoptStub        func(context.Context, string, string, string, bool) error
+	adoptMutex       sync.RWMutex
+	adoptArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 bool
+	}
+	adoptReturns struct {
+		result1 error
+	}
+	adoptReturnsOnCall map[int]struct {
+		result1 error
+	}
 	GetMigrationsStub        func(context.Context) ([]*indexmanager.Migration, error)
 	getMigrationsMutex       sync.RWMutex
 	getMigrationsArgsForCall []struct {
@@ -34,10 +49,101 @@ type FakeMigrator struct {
 	migrateReturnsOnCall map[int]struct {
 		result1 error
 	}
+	RestoreSnapshotStub        func(context.Context, *indexmanager.Migration, *indexmanager.RestoreOptions) error
+	restoreSnapshotMutex       sync.RWMutex
+	restoreSnapshotArgsForCall []struct {
+		arg1 context.Context
+		arg2 *indexmanager.Migration
+		arg3 *indexmanager.RestoreOptions
+	}
+	restoreSnapshotReturns struct {
+		result1 error
+	}
+	restoreSnapshotReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RethrottleStub        func(context.Context, string, int) error
+	rethrottleMutex       sync.RWMutex
+	rethrottleArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 int
+	}
+	rethrottleReturns struct {
+		result1 error
+	}
+	rethrottleReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeMigrator) Adopt(arg1 context.Context, arg2 string, arg3 string, arg4 string, arg5 bool) error {
+	fake.adoptMutex.Lock()
+	ret, specificReturn := fake.adoptReturnsOnCall[len(fake.adoptArgsForCall)]
+	fake.adoptArgsForCall = append(fake.adoptArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 bool
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.AdoptStub
+	fakeReturns := fake.adoptReturns
+	fake.recordInvocation("Adopt", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.adoptMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMigrator) AdoptCallCount() int {
+	fake.adoptMutex.RLock()
+	defer fake.adoptMutex.RUnlock()
+	return len(fake.adoptArgsForCall)
+}
+
+func (fake *FakeMigrator) AdoptCalls(stub func(context.Context, string, string, string, bool) error) {
+	fake.adoptMutex.Lock()
+	defer fake.adoptMutex.Unlock()
+	fake.AdoptStub = stub
+}
+
+func (fake *FakeMigrator) AdoptArgsForCall(i int) (context.Context, string, string, string, bool) {
+	fake.adoptMutex.RLock()
+	defer fake.adoptMutex.RUnlock()
+	argsForCall := fake.adoptArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeMigrator) AdoptReturns(result1 error) {
+	fake.adoptMutex.Lock()
+	defer fake.adoptMutex.Unlock()
+	fake.AdoptStub = nil
+	fake.adoptReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMigrator) AdoptReturnsOnCall(i int, result1 error) {
+	fake.adoptMutex.Lock()
+	defer fake.adoptMutex.Unlock()
+	fake.AdoptStub = nil
+	if fake.adoptReturnsOnCall == nil {
+		fake.adoptReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.adoptReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeMigrator) GetMigrations(arg1 context.Context) ([]*indexmanager.Migration, error) {
 	fake.getMigrationsMutex.Lock()
 	ret, specificReturn := fake.getMigrationsReturnsOnCall[len(fake.getMigrationsArgsForCall)]
@@ -164,13 +270,145 @@ func (fake *FakeMigrator) MigrateReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeMigrator) RestoreSnapshot(arg1 context.Context, arg2 *indexmanager.Migration, arg3 *indexmanager.RestoreOptions) error {
+	fake.restoreSnapshotMutex.Lock()
+	ret, specificReturn := fake.restoreSnapshotReturnsOnCall[len(fake.restoreSnapshotArgsForCall)]
+	fake.restoreSnapshotArgsForCall = append(fake.restoreSnapshotArgsForCall, struct {
+		arg1 context.Context
+		arg2 *indexmanager.Migration
+		arg3 *indexmanager.RestoreOptions
+	}{arg1, arg2, arg3})
+	stub := fake.RestoreSnapshotStub
+	fakeReturns := fake.restoreSnapshotReturns
+	fake.recordInvocation("RestoreSnapshot", []interface{}{arg1, arg2, arg3})
+	fake.restoreSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMigrator) RestoreSnapshotCallCount() int {
+	fake.restoreSnapshotMutex.RLock()
+	defer fake.restoreSnapshotMutex.RUnlock()
+	return len(fake.restoreSnapshotArgsForCall)
+}
+
+func (fake *FakeMigrator) RestoreSnapshotCalls(stub func(context.Context, *indexmanager.Migration, *indexmanager.RestoreOptions) error) {
+	fake.restoreSnapshotMutex.Lock()
+	defer fake.restoreSnapshotMutex.Unlock()
+	fake.RestoreSnapshotStub = stub
+}
+
+func (fake *FakeMigrator) RestoreSnapshotArgsForCall(i int) (context.Context, *indexmanager.Migration, *indexmanager.RestoreOptions) {
+	fake.restoreSnapshotMutex.RLock()
+	defer fake.restoreSnapshotMutex.RUnlock()
+	argsForCall := fake.restoreSnapshotArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeMigrator) RestoreSnapshotReturns(result1 error) {
+	fake.restoreSnapshotMutex.Lock()
+	defer fake.restoreSnapshotMutex.Unlock()
+	fake.RestoreSnapshotStub = nil
+	fake.restoreSnapshotReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMigrator) RestoreSnapshotReturnsOnCall(i int, result1 error) {
+	fake.restoreSnapshotMutex.Lock()
+	defer fake.restoreSnapshotMutex.Unlock()
+	fake.RestoreSnapshotStub = nil
+	if fake.restoreSnapshotReturnsOnCall == nil {
+		fake.restoreSnapshotReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.restoreSnapshotReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMigrator) Rethrottle(arg1 context.Context, arg2 string, arg3 int) error {
+	fake.rethrottleMutex.Lock()
+	ret, specificReturn := fake.rethrottleReturnsOnCall[len(fake.rethrottleArgsForCall)]
+	fake.rethrottleArgsForCall = append(fake.rethrottleArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 int
+	}{arg1, arg2, arg3})
+	stub := fake.RethrottleStub
+	fakeReturns := fake.rethrottleReturns
+	fake.recordInvocation("Rethrottle", []interface{}{arg1, arg2, arg3})
+	fake.rethrottleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMigrator) RethrottleCallCount() int {
+	fake.rethrottleMutex.RLock()
+	defer fake.rethrottleMutex.RUnlock()
+	return len(fake.rethrottleArgsForCall)
+}
+
+func (fake *FakeMigrator) RethrottleCalls(stub func(context.Context, string, int) error) {
+	fake.rethrottleMutex.Lock()
+	defer fake.rethrottleMutex.Unlock()
+	fake.RethrottleStub = stub
+}
+
+func (fake *FakeMigrator) RethrottleArgsForCall(i int) (context.Context, string, int) {
+	fake.rethrottleMutex.RLock()
+	defer fake.rethrottleMutex.RUnlock()
+	argsForCall := fake.rethrottleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeMigrator) RethrottleReturns(result1 error) {
+	fake.rethrottleMutex.Lock()
+	defer fake.rethrottleMutex.Unlock()
+	fake.RethrottleStub = nil
+	fake.rethrottleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMigrator) RethrottleReturnsOnCall(i int, result1 error) {
+	fake.rethrottleMutex.Lock()
+	defer fake.rethrottleMutex.Unlock()
+	fake.RethrottleStub = nil
+	if fake.rethrottleReturnsOnCall == nil {
+		fake.rethrottleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.rethrottleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeMigrator) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.adoptMutex.RLock()
+	defer fake.adoptMutex.RUnlock()
 	fake.getMigrationsMutex.RLock()
 	defer fake.getMigrationsMutex.RUnlock()
 	fake.migrateMutex.RLock()
 	defer fake.migrateMutex.RUnlock()
+	fake.restoreSnapshotMutex.RLock()
+	defer fake.restoreSnapshotMutex.RUnlock()
+	fake.rethrottleMutex.RLock()
+	defer fake.rethrottleMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,117 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rode/es-index-manager/indexmanager"
+)
+
+type FakeRemoteMappingsClient struct {
+	FetchMappingsStub        func(context.Context) (map[string]*indexmanager.VersionedMapping, error)
+	fetchMappingsMutex       sync.RWMutex
+	fetchMappingsArgsForCall []struct {
+		arg1 context.Context
+	}
+	fetchMappingsReturns struct {
+		result1 map[string]*indexmanager.VersionedMapping
+		result2 error
+	}
+	fetchMappingsReturnsOnCall map[int]struct {
+		result1 map[string]*indexmanager.VersionedMapping
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeRemoteMappingsClient) FetchMappings(arg1 context.Context) (map[string]*indexmanager.VersionedMapping, error) {
+	fake.fetchMappingsMutex.Lock()
+	ret, specificReturn := fake.fetchMappingsReturnsOnCall[len(fake.fetchMappingsArgsForCall)]
+	fake.fetchMappingsArgsForCall = append(fake.fetchMappingsArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.FetchMappingsStub
+	fakeReturns := fake.fetchMappingsReturns
+	fake.recordInvocation("FetchMappings", []interface{}{arg1})
+	fake.fetchMappingsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRemoteMappingsClient) FetchMappingsCallCount() int {
+	fake.fetchMappingsMutex.RLock()
+	defer fake.fetchMappingsMutex.RUnlock()
+	return len(fake.fetchMappingsArgsForCall)
+}
+
+func (fake *FakeRemoteMappingsClient) FetchMappingsCalls(stub func(context.Context) (map[string]*indexmanager.VersionedMapping, error)) {
+	fake.fetchMappingsMutex.Lock()
+	defer fake.fetchMappingsMutex.Unlock()
+	fake.FetchMappingsStub = stub
+}
+
+func (fake *FakeRemoteMappingsClient) FetchMappingsArgsForCall(i int) context.Context {
+	fake.fetchMappingsMutex.RLock()
+	defer fake.fetchMappingsMutex.RUnlock()
+	argsForCall := fake.fetchMappingsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeRemoteMappingsClient) FetchMappingsReturns(result1 map[string]*indexmanager.VersionedMapping, result2 error) {
+	fake.fetchMappingsMutex.Lock()
+	defer fake.fetchMappingsMutex.Unlock()
+	fake.FetchMappingsStub = nil
+	fake.fetchMappingsReturns = struct {
+		result1 map[string]*indexmanager.VersionedMapping
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRemoteMappingsClient) FetchMappingsReturnsOnCall(i int, result1 map[string]*indexmanager.VersionedMapping, result2 error) {
+	fake.fetchMappingsMutex.Lock()
+	defer fake.fetchMappingsMutex.Unlock()
+	fake.FetchMappingsStub = nil
+	if fake.fetchMappingsReturnsOnCall == nil {
+		fake.fetchMappingsReturnsOnCall = make(map[int]struct {
+			result1 map[string]*indexmanager.VersionedMapping
+			result2 error
+		})
+	}
+	fake.fetchMappingsReturnsOnCall[i] = struct {
+		result1 map[string]*indexmanager.VersionedMapping
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRemoteMappingsClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.fetchMappingsMutex.RLock()
+	defer fake.fetchMappingsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeRemoteMappingsClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ indexmanager.RemoteMappingsClient = new(FakeRemoteMappingsClient)
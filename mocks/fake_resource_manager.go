@@ -0,0 +1,191 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rode/es-index-manager/indexmanager"
+)
+
+type FakeResourceManager struct {
+	InstallResourcesStub        func(context.Context) error
+	installResourcesMutex       sync.RWMutex
+	installResourcesArgsForCall []struct {
+		arg1 context.Context
+	}
+	installResourcesReturns struct {
+		result1 error
+	}
+	installResourcesReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ResourceDriftStub        func(context.Context) ([]*indexmanager.ResourceDrift, error)
+	resourceDriftMutex       sync.RWMutex
+	resourceDriftArgsForCall []struct {
+		arg1 context.Context
+	}
+	resourceDriftReturns struct {
+		result1 []*indexmanager.ResourceDrift
+		result2 error
+	}
+	resourceDriftReturnsOnCall map[int]struct {
+		result1 []*indexmanager.ResourceDrift
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeResourceManager) InstallResources(arg1 context.Context) error {
+	fake.installResourcesMutex.Lock()
+	ret, specificReturn := fake.installResourcesReturnsOnCall[len(fake.installResourcesArgsForCall)]
+	fake.installResourcesArgsForCall = append(fake.installResourcesArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.InstallResourcesStub
+	fakeReturns := fake.installResourcesReturns
+	fake.recordInvocation("InstallResources", []interface{}{arg1})
+	fake.installResourcesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeResourceManager) InstallResourcesCallCount() int {
+	fake.installResourcesMutex.RLock()
+	defer fake.installResourcesMutex.RUnlock()
+	return len(fake.installResourcesArgsForCall)
+}
+
+func (fake *FakeResourceManager) InstallResourcesCalls(stub func(context.Context) error) {
+	fake.installResourcesMutex.Lock()
+	defer fake.installResourcesMutex.Unlock()
+	fake.InstallResourcesStub = stub
+}
+
+func (fake *FakeResourceManager) InstallResourcesArgsForCall(i int) context.Context {
+	fake.installResourcesMutex.RLock()
+	defer fake.installResourcesMutex.RUnlock()
+	argsForCall := fake.installResourcesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeResourceManager) InstallResourcesReturns(result1 error) {
+	fake.installResourcesMutex.Lock()
+	defer fake.installResourcesMutex.Unlock()
+	fake.InstallResourcesStub = nil
+	fake.installResourcesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeResourceManager) InstallResourcesReturnsOnCall(i int, result1 error) {
+	fake.installResourcesMutex.Lock()
+	defer fake.installResourcesMutex.Unlock()
+	fake.InstallResourcesStub = nil
+	if fake.installResourcesReturnsOnCall == nil {
+		fake.installResourcesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.installResourcesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeResourceManager) ResourceDrift(arg1 context.Context) ([]*indexmanager.ResourceDrift, error) {
+	fake.resourceDriftMutex.Lock()
+	ret, specificReturn := fake.resourceDriftReturnsOnCall[len(fake.resourceDriftArgsForCall)]
+	fake.resourceDriftArgsForCall = append(fake.resourceDriftArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.ResourceDriftStub
+	fakeReturns := fake.resourceDriftReturns
+	fake.recordInvocation("ResourceDrift", []interface{}{arg1})
+	fake.resourceDriftMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeResourceManager) ResourceDriftCallCount() int {
+	fake.resourceDriftMutex.RLock()
+	defer fake.resourceDriftMutex.RUnlock()
+	return len(fake.resourceDriftArgsForCall)
+}
+
+func (fake *FakeResourceManager) ResourceDriftCalls(stub func(context.Context) ([]*indexmanager.ResourceDrift, error)) {
+	fake.resourceDriftMutex.Lock()
+	defer fake.resourceDriftMutex.Unlock()
+	fake.ResourceDriftStub = stub
+}
+
+func (fake *FakeResourceManager) ResourceDriftArgsForCall(i int) context.Context {
+	fake.resourceDriftMutex.RLock()
+	defer fake.resourceDriftMutex.RUnlock()
+	argsForCall := fake.resourceDriftArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeResourceManager) ResourceDriftReturns(result1 []*indexmanager.ResourceDrift, result2 error) {
+	fake.resourceDriftMutex.Lock()
+	defer fake.resourceDriftMutex.Unlock()
+	fake.ResourceDriftStub = nil
+	fake.resourceDriftReturns = struct {
+		result1 []*indexmanager.ResourceDrift
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeResourceManager) ResourceDriftReturnsOnCall(i int, result1 []*indexmanager.ResourceDrift, result2 error) {
+	fake.resourceDriftMutex.Lock()
+	defer fake.resourceDriftMutex.Unlock()
+	fake.ResourceDriftStub = nil
+	if fake.resourceDriftReturnsOnCall == nil {
+		fake.resourceDriftReturnsOnCall = make(map[int]struct {
+			result1 []*indexmanager.ResourceDrift
+			result2 error
+		})
+	}
+	fake.resourceDriftReturnsOnCall[i] = struct {
+		result1 []*indexmanager.ResourceDrift
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeResourceManager) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.installResourcesMutex.RLock()
+	defer fake.installResourcesMutex.RUnlock()
+	fake.resourceDriftMutex.RLock()
+	defer fake.resourceDriftMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeResourceManager) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ indexmanager.ResourceManager = new(FakeResourceManager)
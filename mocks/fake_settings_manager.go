@@ -0,0 +1,196 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rode/es-index-manager/indexmanager"
+)
+
+type FakeSettingsManager struct {
+	GetSettingsChangesStub        func(context.Context) ([]*indexmanager.SettingsChange, error)
+	getSettingsChangesMutex       sync.RWMutex
+	getSettingsChangesArgsForCall []struct {
+		arg1 context.Context
+	}
+	getSettingsChangesReturns struct {
+		result1 []*indexmanager.SettingsChange
+		result2 error
+	}
+	getSettingsChangesReturnsOnCall map[int]struct {
+		result1 []*indexmanager.SettingsChange
+		result2 error
+	}
+	UpdateSettingsStub        func(context.Context) ([]*indexmanager.SettingsChange, error)
+	updateSettingsMutex       sync.RWMutex
+	updateSettingsArgsForCall []struct {
+		arg1 context.Context
+	}
+	updateSettingsReturns struct {
+		result1 []*indexmanager.SettingsChange
+		result2 error
+	}
+	updateSettingsReturnsOnCall map[int]struct {
+		result1 []*indexmanager.SettingsChange
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeSettingsManager) GetSettingsChanges(arg1 context.Context) ([]*indexmanager.SettingsChange, error) {
+	fake.getSettingsChangesMutex.Lock()
+	ret, specificReturn := fake.getSettingsChangesReturnsOnCall[len(fake.getSettingsChangesArgsForCall)]
+	fake.getSettingsChangesArgsForCall = append(fake.getSettingsChangesArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.GetSettingsChangesStub
+	fakeReturns := fake.getSettingsChangesReturns
+	fake.recordInvocation("GetSettingsChanges", []interface{}{arg1})
+	fake.getSettingsChangesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSettingsManager) GetSettingsChangesCallCount() int {
+	fake.getSettingsChangesMutex.RLock()
+	defer fake.getSettingsChangesMutex.RUnlock()
+	return len(fake.getSettingsChangesArgsForCall)
+}
+
+func (fake *FakeSettingsManager) GetSettingsChangesCalls(stub func(context.Context) ([]*indexmanager.SettingsChange, error)) {
+	fake.getSettingsChangesMutex.Lock()
+	defer fake.getSettingsChangesMutex.Unlock()
+	fake.GetSettingsChangesStub = stub
+}
+
+func (fake *FakeSettingsManager) GetSettingsChangesArgsForCall(i int) context.Context {
+	fake.getSettingsChangesMutex.RLock()
+	defer fake.getSettingsChangesMutex.RUnlock()
+	argsForCall := fake.getSettingsChangesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSettingsManager) GetSettingsChangesReturns(result1 []*indexmanager.SettingsChange, result2 error) {
+	fake.getSettingsChangesMutex.Lock()
+	defer fake.getSettingsChangesMutex.Unlock()
+	fake.GetSettingsChangesStub = nil
+	fake.getSettingsChangesReturns = struct {
+		result1 []*indexmanager.SettingsChange
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSettingsManager) GetSettingsChangesReturnsOnCall(i int, result1 []*indexmanager.SettingsChange, result2 error) {
+	fake.getSettingsChangesMutex.Lock()
+	defer fake.getSettingsChangesMutex.Unlock()
+	fake.GetSettingsChangesStub = nil
+	if fake.getSettingsChangesReturnsOnCall == nil {
+		fake.getSettingsChangesReturnsOnCall = make(map[int]struct {
+			result1 []*indexmanager.SettingsChange
+			result2 error
+		})
+	}
+	fake.getSettingsChangesReturnsOnCall[i] = struct {
+		result1 []*indexmanager.SettingsChange
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSettingsManager) UpdateSettings(arg1 context.Context) ([]*indexmanager.SettingsChange, error) {
+	fake.updateSettingsMutex.Lock()
+	ret, specificReturn := fake.updateSettingsReturnsOnCall[len(fake.updateSettingsArgsForCall)]
+	fake.updateSettingsArgsForCall = append(fake.updateSettingsArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.UpdateSettingsStub
+	fakeReturns := fake.updateSettingsReturns
+	fake.recordInvocation("UpdateSettings", []interface{}{arg1})
+	fake.updateSettingsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSettingsManager) UpdateSettingsCallCount() int {
+	fake.updateSettingsMutex.RLock()
+	defer fake.updateSettingsMutex.RUnlock()
+	return len(fake.updateSettingsArgsForCall)
+}
+
+func (fake *FakeSettingsManager) UpdateSettingsCalls(stub func(context.Context) ([]*indexmanager.SettingsChange, error)) {
+	fake.updateSettingsMutex.Lock()
+	defer fake.updateSettingsMutex.Unlock()
+	fake.UpdateSettingsStub = stub
+}
+
+func (fake *FakeSettingsManager) UpdateSettingsArgsForCall(i int) context.Context {
+	fake.updateSettingsMutex.RLock()
+	defer fake.updateSettingsMutex.RUnlock()
+	argsForCall := fake.updateSettingsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSettingsManager) UpdateSettingsReturns(result1 []*indexmanager.SettingsChange, result2 error) {
+	fake.updateSettingsMutex.Lock()
+	defer fake.updateSettingsMutex.Unlock()
+	fake.UpdateSettingsStub = nil
+	fake.updateSettingsReturns = struct {
+		result1 []*indexmanager.SettingsChange
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSettingsManager) UpdateSettingsReturnsOnCall(i int, result1 []*indexmanager.SettingsChange, result2 error) {
+	fake.updateSettingsMutex.Lock()
+	defer fake.updateSettingsMutex.Unlock()
+	fake.UpdateSettingsStub = nil
+	if fake.updateSettingsReturnsOnCall == nil {
+		fake.updateSettingsReturnsOnCall = make(map[int]struct {
+			result1 []*indexmanager.SettingsChange
+			result2 error
+		})
+	}
+	fake.updateSettingsReturnsOnCall[i] = struct {
+		result1 []*indexmanager.SettingsChange
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSettingsManager) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getSettingsChangesMutex.RLock()
+	defer fake.getSettingsChangesMutex.RUnlock()
+	fake.updateSettingsMutex.RLock()
+	defer fake.updateSettingsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeSettingsManager) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ indexmanager.SettingsManager = new(FakeSettingsManager)